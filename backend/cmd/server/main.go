@@ -3,6 +3,8 @@ package main
 
 import (
 	"log"
+	"time"
+	_ "time/tzdata" // station time zones on hosts without a zoneinfo database
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -44,21 +46,44 @@ func main() {
 
 	// Initialize services
 	userService := services.NewUserService(db)
-	scheduleService := services.NewScheduleService(db)
+	scheduleService := services.NewScheduleService(db, time.Now)
 	commandService := services.NewCommandService(db)
-	stationService := services.NewStationService(db, scheduleService)
+	notifier := services.NewNotifier()
+	healthService := services.NewHealthService(db, scheduleService, notifier)
+	stationService := services.NewStationService(db, scheduleService, healthService)
 	documentService := services.NewDocumentService(db)
 	vesselService := services.NewVesselService(db)
+	positionService := services.NewPositionService(db, vesselService)
+	riskService := services.NewRiskService(positionService, stationService)
+	watchlistService := services.NewWatchlistService(db, vesselService, positionService, notifier)
+	equipmentService := services.NewEquipmentService(db)
+	groupService := services.NewGroupService(db)
+	personnelService := services.NewPersonnelService(db, scheduleService)
+	handoverService := services.NewHandoverService(db, scheduleService)
+	scheduleChangeService := services.NewScheduleChangeService(db, scheduleService, notifier)
+	exportService := services.NewExportService(stationService, scheduleService, vesselService, positionService)
 	fileUploadService := services.NewFileUploadService("./uploads", "http://localhost:8998")
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(userService)
 	userHandler := handlers.NewUserHandler(userService)
-	stationHandler := handlers.NewStationHandler(stationService)
-	scheduleHandler := handlers.NewScheduleHandler(scheduleService, stationService)
-	commandHandler := handlers.NewCommandHandler(commandService, stationService)
+	stationHandler := handlers.NewStationHandler(stationService, groupService)
+	scheduleHandler := handlers.NewScheduleHandler(scheduleService, scheduleChangeService, stationService, groupService)
+	commandHandler := handlers.NewCommandHandler(commandService, stationService, groupService)
 	documentHandler := handlers.NewDocumentHandler(documentService, fileUploadService)
-	vesselHandler := handlers.NewVesselHandler(vesselService)
+	vesselHandler := handlers.NewVesselHandler(vesselService, positionService)
+	riskHandler := handlers.NewRiskHandler(riskService, groupService)
+	watchlistHandler := handlers.NewWatchlistHandler(watchlistService)
+	eventHandler := handlers.NewEventHandler(notifier)
+	exportHandler := handlers.NewExportHandler(exportService, groupService)
+	healthHandler := handlers.NewHealthHandler(healthService, groupService)
+	equipmentHandler := handlers.NewEquipmentHandler(equipmentService, groupService)
+	groupHandler := handlers.NewGroupHandler(groupService, stationService)
+	personnelHandler := handlers.NewPersonnelHandler(personnelService, groupService)
+	handoverHandler := handlers.NewHandoverHandler(handoverService, stationService, groupService)
+
+	// Start station health monitor
+	go healthService.Run(services.HealthCheckInterval, nil)
 
 	// Initialize Gin router
 	r := gin.Default()
@@ -110,6 +135,8 @@ func main() {
 
 			// Protected routes (require JWT token)
 			auth.GET("/me", middleware.JWTMiddleware(userService), authHandler.GetUserInfo)
+			auth.GET("/calendar-token", middleware.JWTMiddleware(userService), authHandler.GetCalendarToken)     // GET /auth/calendar-token
+			auth.POST("/calendar-token", middleware.JWTMiddleware(userService), authHandler.RotateCalendarToken) // POST /auth/calendar-token
 		}
 
 		// User management routes (Admin only)
@@ -136,9 +163,46 @@ func main() {
 		stations := api.Group("/stations")
 		stations.Use(middleware.JWTMiddleware(userService), middleware.StationAccessMiddleware())
 		{
-			stations.GET("", stationHandler.ListStations)      // GET /stations
-			stations.GET("/:id", stationHandler.GetStation)    // GET /stations/:id
-			stations.PUT("/:id", stationHandler.UpdateStation) // PUT /stations/:id
+			stations.GET("", stationHandler.ListStations)                         // GET /stations
+			stations.GET("/nearest", stationHandler.NearestStations)              // GET /stations/nearest?lat&lon&k
+			stations.GET("/covering", stationHandler.CoveringStations)            // GET /stations/covering?lat&lon
+			stations.GET("/coverage", stationHandler.GetCoverageMap)              // GET /stations/coverage
+			stations.GET("/availability", healthHandler.GetFleetAvailability)     // GET /stations/availability?from&to
+			stations.GET("/:id", stationHandler.GetStation)                       // GET /stations/:id
+			stations.GET("/:id/coverage", stationHandler.GetStationCoverage)      // GET /stations/:id/coverage
+			stations.GET("/:id/health", healthHandler.GetHealth)                  // GET /stations/:id/health
+			stations.GET("/:id/status-history", healthHandler.GetStatusHistory)   // GET /stations/:id/status-history
+			stations.GET("/:id/timeline", healthHandler.GetTimeline)              // GET /stations/:id/timeline?from&to
+			stations.GET("/:id/availability", healthHandler.GetAvailability)      // GET /stations/:id/availability?from&to
+			stations.POST("/:id/heartbeat", healthHandler.PostHeartbeat)          // POST /stations/:id/heartbeat
+			stations.GET("/:id/override", healthHandler.GetOverride)              // GET /stations/:id/override
+			stations.PUT("/:id/override", healthHandler.SetOverride)              // PUT /stations/:id/override
+			stations.DELETE("/:id/override", healthHandler.ClearOverride)         // DELETE /stations/:id/override
+			stations.GET("/:id/equipment", equipmentHandler.ListStationEquipment) // GET /stations/:id/equipment
+			stations.POST("/:id/equipment", equipmentHandler.CreateEquipment)     // POST /stations/:id/equipment
+			stations.GET("/:id/notes", stationHandler.ListNotes)                  // GET /stations/:id/notes?page&page_size
+			stations.POST("/:id/notes", stationHandler.AddNote)                   // POST /stations/:id/notes
+			stations.PUT("/:id/notes/:note_id", stationHandler.PinNote)           // PUT /stations/:id/notes/:note_id
+			stations.PUT("/:id", stationHandler.UpdateStation)                    // PUT /stations/:id
+		}
+
+		// Station group routes
+		// Admin-only operations (Create, Update, Delete)
+		groupsAdmin := api.Group("/groups")
+		groupsAdmin.Use(middleware.JWTMiddleware(userService), middleware.AdminMiddleware())
+		{
+			groupsAdmin.POST("", groupHandler.CreateGroup)       // POST /groups (Admin only)
+			groupsAdmin.PUT("/:id", groupHandler.UpdateGroup)    // PUT /groups/:id (Admin only)
+			groupsAdmin.DELETE("/:id", groupHandler.DeleteGroup) // DELETE /groups/:id (Admin only)
+		}
+
+		// Group reads for ADMIN, OPERATOR, and HQ
+		groups := api.Group("/groups")
+		groups.Use(middleware.JWTMiddleware(userService), middleware.StationAccessMiddleware())
+		{
+			groups.GET("", groupHandler.ListGroups)                     // GET /groups
+			groups.GET("/:id", groupHandler.GetGroup)                   // GET /groups/:id
+			groups.GET("/:id/stations", groupHandler.ListGroupStations) // GET /groups/:id/stations
 		}
 
 		// Schedule routes - using different URL pattern to avoid conflicts
@@ -146,17 +210,92 @@ func main() {
 		schedules.Use(middleware.JWTMiddleware(userService), middleware.StationAccessMiddleware())
 		{
 			// Read operations available to all with station access
-			schedules.GET("/station/:station_id", scheduleHandler.ListSchedules)            // GET /station-schedules/station/:station_id
-			schedules.GET("/station/:station_id/:schedule_id", scheduleHandler.GetSchedule) // GET /station-schedules/station/:station_id/:schedule_id
+			schedules.GET("", scheduleHandler.ListAllSchedules)                                       // GET /station-schedules?group_id
+			schedules.GET("/coverage", scheduleHandler.GetScheduleCoverage)                           // GET /station-schedules/coverage?from&to&group_id&tz&format
+			schedules.GET("/station/:station_id", scheduleHandler.ListSchedules)                      // GET /station-schedules/station/:station_id
+			schedules.GET("/station/:station_id/:schedule_id", scheduleHandler.GetSchedule)           // GET /station-schedules/station/:station_id/:schedule_id
+			schedules.GET("/station/:station_id/overrides", scheduleHandler.ListScheduleOverrides)    // GET /station-schedules/station/:station_id/overrides
+			schedules.GET("/station/:station_id/active", scheduleHandler.GetScheduleActive)           // GET /station-schedules/station/:station_id/active?at
+			schedules.GET("/station/:station_id/handovers", handoverHandler.ListHandovers)            // GET /station-schedules/station/:station_id/handovers
+			schedules.GET("/station/:station_id/handovers/:handover_id", handoverHandler.GetHandover) // GET /station-schedules/station/:station_id/handovers/:handover_id
+			schedules.GET("/handovers/unsigned", handoverHandler.ListUnsignedHandovers)               // GET /station-schedules/handovers/unsigned?group_id
+		}
+
+		// Personnel roster routes
+		personnel := api.Group("/personnel")
+		personnel.Use(middleware.JWTMiddleware(userService), middleware.StationAccessMiddleware())
+		{
+			personnel.GET("", personnelHandler.ListPersonnel)               // GET /personnel?station_id
+			personnel.GET("/conflicts", personnelHandler.ListDutyConflicts) // GET /personnel/conflicts?from&to
+			personnel.GET("/me/duties", personnelHandler.ListMyDuties)      // GET /personnel/me/duties?from&to
+			personnel.GET("/:id", personnelHandler.GetPersonnel)            // GET /personnel/:id
+			personnel.GET("/:id/duties", personnelHandler.ListDuties)       // GET /personnel/:id/duties?from&to
+		}
+		personnelAdmin := api.Group("/personnel")
+		personnelAdmin.Use(middleware.JWTMiddleware(userService), middleware.AdminMiddleware())
+		{
+			personnelAdmin.POST("", personnelHandler.CreatePersonnel)       // POST /personnel
+			personnelAdmin.PUT("/:id", personnelHandler.UpdatePersonnel)    // PUT /personnel/:id
+			personnelAdmin.DELETE("/:id", personnelHandler.DeletePersonnel) // DELETE /personnel/:id
+		}
+
+		// Calendar feeds accept a calendar token (?token=) instead of a JWT header
+		calendars := api.Group("/station-schedules")
+		calendars.Use(middleware.CalendarTokenMiddleware(userService), middleware.StationAccessMiddleware())
+		{
+			calendars.GET("/calendar.ics", scheduleHandler.FleetCalendar)                       // GET /station-schedules/calendar.ics?token&group_id&days
+			calendars.GET("/station/:station_id/calendar.ics", scheduleHandler.StationCalendar) // GET /station-schedules/station/:station_id/calendar.ics?token&days
 		}
 
 		// Schedule management routes (Operator only for CUD operations)
 		scheduleOperator := api.Group("/station-schedules")
 		scheduleOperator.Use(middleware.JWTMiddleware(userService), middleware.OperatorMiddleware())
 		{
-			scheduleOperator.POST("/station/:station_id", scheduleHandler.CreateSchedule)                // POST /station-schedules/station/:station_id
-			scheduleOperator.PUT("/station/:station_id/:schedule_id", scheduleHandler.UpdateSchedule)    // PUT /station-schedules/station/:station_id/:schedule_id
-			scheduleOperator.DELETE("/station/:station_id/:schedule_id", scheduleHandler.DeleteSchedule) // DELETE /station-schedules/station/:station_id/:schedule_id
+			scheduleOperator.POST("/station/:station_id", scheduleHandler.CreateSchedule)                                  // POST /station-schedules/station/:station_id
+			scheduleOperator.PUT("/station/:station_id/:schedule_id", scheduleHandler.UpdateSchedule)                      // PUT /station-schedules/station/:station_id/:schedule_id
+			scheduleOperator.DELETE("/station/:station_id/:schedule_id", scheduleHandler.DeleteSchedule)                   // DELETE /station-schedules/station/:station_id/:schedule_id
+			scheduleOperator.POST("/station/:station_id/overrides", scheduleHandler.CreateScheduleOverride)                // POST /station-schedules/station/:station_id/overrides
+			scheduleOperator.DELETE("/station/:station_id/overrides/:override_id", scheduleHandler.DeleteScheduleOverride) // DELETE /station-schedules/station/:station_id/overrides/:override_id
+			scheduleOperator.POST("/station/:station_id/handovers", handoverHandler.CreateHandover)                        // POST /station-schedules/station/:station_id/handovers
+			scheduleOperator.POST("/station/:station_id/handovers/:handover_id/sign", handoverHandler.SignHandover)        // POST /station-schedules/station/:station_id/handovers/:handover_id/sign
+		}
+
+		// Schedule change approval routes (HQ reviews, Admin switches the flow on)
+		changes := api.Group("/schedule-changes")
+		changes.Use(middleware.JWTMiddleware(userService), middleware.StationAccessMiddleware())
+		{
+			changes.GET("", scheduleHandler.ListScheduleChanges)                 // GET /schedule-changes?status&station_id&group_id
+			changes.GET("/pending", scheduleHandler.ListPendingScheduleChanges)  // GET /schedule-changes/pending?station_id&group_id
+			changes.GET("/settings", scheduleHandler.GetScheduleApprovalSetting) // GET /schedule-changes/settings
+			changes.GET("/:id", scheduleHandler.GetScheduleChange)               // GET /schedule-changes/:id
+		}
+		changesHQ := api.Group("/schedule-changes")
+		changesHQ.Use(middleware.JWTMiddleware(userService), middleware.HQMiddleware())
+		{
+			changesHQ.POST("/:id/approve", scheduleHandler.ApproveScheduleChange) // POST /schedule-changes/:id/approve
+			changesHQ.POST("/:id/reject", scheduleHandler.RejectScheduleChange)   // POST /schedule-changes/:id/reject
+		}
+		changesAdmin := api.Group("/schedule-changes")
+		changesAdmin.Use(middleware.JWTMiddleware(userService), middleware.AdminMiddleware())
+		{
+			changesAdmin.PUT("/settings", scheduleHandler.UpdateScheduleApprovalSetting) // PUT /schedule-changes/settings
+		}
+
+		// Schedule template routes (Operator only for CUD and apply)
+		templates := api.Group("/schedule-templates")
+		templates.Use(middleware.JWTMiddleware(userService), middleware.StationAccessMiddleware())
+		{
+			templates.GET("", scheduleHandler.ListScheduleTemplates)   // GET /schedule-templates
+			templates.GET("/:id", scheduleHandler.GetScheduleTemplate) // GET /schedule-templates/:id
+		}
+		templatesOperator := api.Group("/schedule-templates")
+		templatesOperator.Use(middleware.JWTMiddleware(userService), middleware.OperatorMiddleware())
+		{
+			templatesOperator.POST("", scheduleHandler.CreateScheduleTemplate)                             // POST /schedule-templates
+			templatesOperator.POST("/from-station/:station_id", scheduleHandler.CreateTemplateFromStation) // POST /schedule-templates/from-station/:station_id
+			templatesOperator.PUT("/:id", scheduleHandler.UpdateScheduleTemplate)                          // PUT /schedule-templates/:id
+			templatesOperator.DELETE("/:id", scheduleHandler.DeleteScheduleTemplate)                       // DELETE /schedule-templates/:id
+			templatesOperator.POST("/:id/apply", scheduleHandler.ApplyScheduleTemplate)                    // POST /schedule-templates/:id/apply
 		}
 
 		// Command management routes
@@ -196,6 +335,19 @@ func main() {
 			files.POST("/upload", documentHandler.UploadFile) // POST /files/upload
 		}
 
+		// Equipment and maintenance routes
+		equipment := api.Group("/equipment")
+		equipment.Use(middleware.JWTMiddleware(userService), middleware.StationAccessMiddleware())
+		{
+			equipment.GET("", equipmentHandler.ListEquipment)                   // GET /equipment
+			equipment.GET("/maintenance-due", equipmentHandler.MaintenanceDue)  // GET /equipment/maintenance-due?within_days
+			equipment.GET("/:id", equipmentHandler.GetEquipment)                // GET /equipment/:id
+			equipment.PUT("/:id", equipmentHandler.UpdateEquipment)             // PUT /equipment/:id
+			equipment.DELETE("/:id", equipmentHandler.DeleteEquipment)          // DELETE /equipment/:id
+			equipment.GET("/:id/maintenance", equipmentHandler.ListMaintenance) // GET /equipment/:id/maintenance
+			equipment.POST("/:id/maintenance", equipmentHandler.AddMaintenance) // POST /equipment/:id/maintenance
+		}
+
 		// Document management routes (require authentication)
 		documents := api.Group("/documents")
 		documents.Use(middleware.JWTMiddleware(userService))
@@ -211,12 +363,67 @@ func main() {
 		vessels := api.Group("/vessels")
 		vessels.Use(middleware.JWTMiddleware(userService))
 		{
-			vessels.POST("", vesselHandler.CreateVessel)              // POST /vessels
-			vessels.GET("", vesselHandler.ListVessels)                // GET /vessels (supports ?name=search_term)
-			vessels.GET("/:id", vesselHandler.GetVessel)              // GET /vessels/:id
-			vessels.GET("/mmsi/:mmsi", vesselHandler.GetVesselByMMSI) // GET /vessels/mmsi/:mmsi
-			vessels.PUT("/:id", vesselHandler.UpdateVessel)           // PUT /vessels/:id
-			vessels.DELETE("/:id", vesselHandler.DeleteVessel)        // DELETE /vessels/:id
+			vessels.POST("", vesselHandler.CreateVessel)                 // POST /vessels
+			vessels.GET("", vesselHandler.ListVessels)                   // GET /vessels (supports ?name=search_term)
+			vessels.POST("/import", vesselHandler.ImportVessels)         // POST /vessels/import
+			vessels.GET("/export", vesselHandler.ExportVessels)          // GET /vessels/export
+			vessels.GET("/search", vesselHandler.SearchVessels)          // GET /vessels/search?q=
+			vessels.GET("/:id", vesselHandler.GetVessel)                 // GET /vessels/:id
+			vessels.GET("/mmsi/:mmsi", vesselHandler.GetVesselByMMSI)    // GET /vessels/mmsi/:mmsi
+			vessels.PUT("/:id", vesselHandler.UpdateVessel)              // PUT /vessels/:id
+			vessels.DELETE("/:id", vesselHandler.DeleteVessel)           // DELETE /vessels/:id
+			vessels.POST("/:id/positions", vesselHandler.ReportPosition) // POST /vessels/:id/positions
+			vessels.GET("/:id/positions", vesselHandler.GetTrack)        // GET /vessels/:id/positions
+		}
+
+		// Collision-risk routes
+		risk := api.Group("/risk")
+		risk.Use(middleware.JWTMiddleware(userService), middleware.StationAccessMiddleware())
+		{
+			risk.GET("/encounters", riskHandler.ListEncounters) // GET /risk/encounters
+		}
+
+		// Watchlist management routes (HQ only)
+		watchlists := api.Group("/watchlists")
+		watchlists.Use(middleware.JWTMiddleware(userService), middleware.HQMiddleware())
+		{
+			watchlists.POST("", watchlistHandler.CreateWatchlist)                             // POST /watchlists
+			watchlists.GET("", watchlistHandler.ListWatchlists)                               // GET /watchlists
+			watchlists.GET("/:id", watchlistHandler.GetWatchlist)                             // GET /watchlists/:id
+			watchlists.PUT("/:id", watchlistHandler.UpdateWatchlist)                          // PUT /watchlists/:id
+			watchlists.DELETE("/:id", watchlistHandler.DeleteWatchlist)                       // DELETE /watchlists/:id
+			watchlists.POST("/:id/entries", watchlistHandler.AddEntry)                        // POST /watchlists/:id/entries
+			watchlists.GET("/:id/entries", watchlistHandler.ListEntries)                      // GET /watchlists/:id/entries
+			watchlists.DELETE("/:id/entries/:entry_id", watchlistHandler.RemoveEntry)         // DELETE /watchlists/:id/entries/:entry_id
+			watchlists.GET("/:id/entries/:entry_id/alerts", watchlistHandler.ListEntryAlerts) // GET /watchlists/:id/entries/:entry_id/alerts
+		}
+
+		// Manual sightings (any station user)
+		sightings := api.Group("/sightings")
+		sightings.Use(middleware.JWTMiddleware(userService), middleware.StationAccessMiddleware())
+		{
+			sightings.POST("", watchlistHandler.ReportSighting) // POST /sightings
+		}
+
+		// GIS export routes (GeoJSON and KML)
+		export := api.Group("/export")
+		export.Use(middleware.JWTMiddleware(userService), middleware.StationAccessMiddleware())
+		{
+			export.GET("/stations.geojson", exportHandler.ExportStations)             // GET /export/stations.geojson
+			export.GET("/stations.kml", exportHandler.ExportStations)                 // GET /export/stations.kml
+			export.GET("/coverage.geojson", exportHandler.ExportCoverage)             // GET /export/coverage.geojson
+			export.GET("/coverage.kml", exportHandler.ExportCoverage)                 // GET /export/coverage.kml
+			export.GET("/vessels.geojson", exportHandler.ExportVesselPositions)       // GET /export/vessels.geojson
+			export.GET("/vessels.kml", exportHandler.ExportVesselPositions)           // GET /export/vessels.kml
+			export.GET("/vessels/:id/track.geojson", exportHandler.ExportVesselTrack) // GET /export/vessels/:id/track.geojson
+			export.GET("/vessels/:id/track.kml", exportHandler.ExportVesselTrack)     // GET /export/vessels/:id/track.kml
+		}
+
+		// Real-time event stream (HQ only)
+		events := api.Group("/events")
+		events.Use(middleware.JWTMiddleware(userService), middleware.HQMiddleware())
+		{
+			events.GET("/stream", eventHandler.Stream) // GET /events/stream (Server-Sent Events)
 		}
 	}
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
 	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/services"
@@ -50,7 +51,7 @@ func main() {
 			log.Println("Failed to create user:", err)
 		}
 	}
-	scheduleService := services.NewScheduleService(db)
+	scheduleService := services.NewScheduleService(db, time.Now)
 	for _, schedule := range testData.Schedules {
 		if err := scheduleService.Create(&schedule); err != nil {
 			log.Println("Failed to create schedule:", err)
@@ -58,7 +59,8 @@ func main() {
 	}
 
 	// Create stations
-	stationService := services.NewStationService(db, scheduleService)
+	healthService := services.NewHealthService(db, scheduleService, services.NewNotifier())
+	stationService := services.NewStationService(db, scheduleService, healthService)
 	for _, station := range testData.Stations {
 		if err := stationService.Create(&station); err != nil {
 			log.Println("Failed to create station:", err)
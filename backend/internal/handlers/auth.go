@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
@@ -99,3 +100,92 @@ func (h *AuthHandler) GetUserInfo(c *gin.Context) {
 
 	c.JSON(http.StatusOK, user)
 }
+
+// CalendarTokenResponse is the token and feed URLs for calendar subscriptions
+type CalendarTokenResponse struct {
+	Token       string `json:"token"`
+	FleetFeed   string `json:"fleet_feed" example:"/v1/api/radar-hub-manager/station-schedules/calendar.ics?token=..."`
+	StationFeed string `json:"station_feed" example:"/v1/api/radar-hub-manager/station-schedules/station/{station_id}/calendar.ics?token=..."`
+}
+
+func calendarTokenResponse(c *gin.Context, token string) CalendarTokenResponse {
+	base := strings.TrimSuffix(c.Request.URL.Path, "/auth/calendar-token")
+	return CalendarTokenResponse{
+		Token:       token,
+		FleetFeed:   base + "/station-schedules/calendar.ics?token=" + token,
+		StationFeed: base + "/station-schedules/station/{station_id}/calendar.ics?token=" + token,
+	}
+}
+
+// GetCalendarToken godoc
+// @Summary Get my calendar feed token
+// @Description Get the token that lets calendar apps fetch the duty schedule feeds without a JWT, creating it on first use. Feeds show the same stations as the API does for this user.
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} CalendarTokenResponse "Token and feed URLs"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /auth/calendar-token [get]
+func (h *AuthHandler) GetCalendarToken(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	token, err := h.userService.CalendarToken(user.Username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get calendar token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendarTokenResponse(c, token))
+}
+
+// RotateCalendarToken godoc
+// @Summary Replace my calendar feed token
+// @Description Issue a new calendar feed token. Subscriptions using the old token stop working.
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} CalendarTokenResponse "New token and feed URLs"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /auth/calendar-token [post]
+func (h *AuthHandler) RotateCalendarToken(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	token, err := h.userService.RotateCalendarToken(user.Username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to rotate calendar token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendarTokenResponse(c, token))
+}
+
+// currentUser returns the user set by JWTMiddleware, writing the error
+// response itself when it is missing.
+func currentUser(c *gin.Context) (*models.User, bool) {
+	userInterface, exists := c.Get("user")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "User not found in context"})
+		return nil, false
+	}
+
+	user, ok := userInterface.(*models.User)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Invalid user data"})
+		return nil, false
+	}
+	return user, true
+}
+
+// ownsStation reports whether the user may act for a station: operators
+// assigned to a station only for that one, everyone else for all.
+func ownsStation(user *models.User, stationID uint) bool {
+	return user.RoleID != models.RoleOperator || user.StationID == nil || *user.StationID == stationID
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/services"
+)
+
+const (
+	calendarPastDays    = 7
+	calendarDefaultDays = 60
+	calendarMaxDays     = 366
+)
+
+// calendarPeriod returns the feed period: the past week and the next days
+// (query "days", default 60).
+func calendarPeriod(c *gin.Context) (from, to int64, ok bool) {
+	days := calendarDefaultDays
+	if raw := c.Query("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > calendarMaxDays {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("days must be between 1 and %d", calendarMaxDays)})
+			return 0, 0, false
+		}
+		days = n
+	}
+	now := time.Now()
+	return now.AddDate(0, 0, -calendarPastDays).Unix(), now.AddDate(0, 0, days).Unix(), true
+}
+
+func writeCalendar(c *gin.Context, filename, name string, watches []services.Watch, stations map[uint]models.Station) {
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Header("Content-Disposition", `inline; filename="`+filename+`.ics"`)
+	c.Status(http.StatusOK)
+	if err := services.WriteCalendar(c.Writer, name, watches, stations, time.Now()); err != nil {
+		c.Error(err)
+	}
+}
+
+// StationCalendar godoc
+// @Summary Duty schedule of a station as an iCalendar feed
+// @Description The watches of a station from a week ago to the given number of days ahead, one event per watch with commander, crew and phone in the description. Recurrence rules, exception dates and one-off overrides are applied. Calendar apps can subscribe with ?token= (see /auth/calendar-token) instead of a JWT header.
+// @Tags schedules
+// @Produce text/calendar
+// @Param station_id path int true "Station ID"
+// @Param days query int false "Days ahead (default 60, max 366)"
+// @Param token query string false "Calendar feed token"
+// @Security BearerAuth
+// @Success 200 {string} string "iCalendar feed"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /station-schedules/station/{station_id}/calendar.ics [get]
+func (h *ScheduleHandler) StationCalendar(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("station_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	from, to, ok := calendarPeriod(c)
+	if !ok {
+		return
+	}
+
+	station, err := h.stationService.GetByID(uint(stationID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, station.ID) {
+		return
+	}
+
+	watches, err := h.scheduleService.Watches(station.ID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to build calendar"})
+		return
+	}
+
+	writeCalendar(c, fmt.Sprintf("station-%d", station.ID), "Duty – "+station.Name, watches, map[uint]models.Station{station.ID: *station})
+}
+
+// FleetCalendar godoc
+// @Summary Duty schedules of all stations as an iCalendar feed
+// @Description The watches of every station the user can see (or of a group), from a week ago to the given number of days ahead. Calendar apps can subscribe with ?token= (see /auth/calendar-token) instead of a JWT header.
+// @Tags schedules
+// @Produce text/calendar
+// @Param group_id query int false "Only stations in this group or the groups below it"
+// @Param days query int false "Days ahead (default 60, max 366)"
+// @Param token query string false "Calendar feed token"
+// @Security BearerAuth
+// @Success 200 {string} string "iCalendar feed"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /station-schedules/calendar.ics [get]
+func (h *ScheduleHandler) FleetCalendar(c *gin.Context) {
+	from, to, ok := calendarPeriod(c)
+	if !ok {
+		return
+	}
+	scope, ok := stationScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	stations, err := h.stationService.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list stations"})
+		return
+	}
+	byID := make(map[uint]models.Station)
+	watches := []services.Watch{}
+	for _, st := range stations {
+		if !inScope(scope, st.ID) {
+			continue
+		}
+		byID[st.ID] = *st
+		ws, err := h.scheduleService.Watches(st.ID, from, to)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to build calendar"})
+			return
+		}
+		watches = append(watches, ws...)
+	}
+
+	writeCalendar(c, "duty-schedules", "Radar duty schedules", watches, byID)
+}
@@ -12,12 +12,14 @@ import (
 type CommandHandler struct {
 	commandService *services.CommandService
 	stationService *services.StationService
+	groupService   *services.GroupService
 }
 
-func NewCommandHandler(commandService *services.CommandService, stationService *services.StationService) *CommandHandler {
+func NewCommandHandler(commandService *services.CommandService, stationService *services.StationService, groupService *services.GroupService) *CommandHandler {
 	return &CommandHandler{
 		commandService: commandService,
 		stationService: stationService,
+		groupService:   groupService,
 	}
 }
 
@@ -73,6 +75,9 @@ func (h *CommandHandler) CreateCommand(c *gin.Context) {
 		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
 		return
 	}
+	if !canSeeStation(c, h.groupService, req.ToStationID) {
+		return
+	}
 
 	command := &models.Command{
 		ToStationID: req.ToStationID,
@@ -90,10 +95,11 @@ func (h *CommandHandler) CreateCommand(c *gin.Context) {
 
 // ListCommands lists commands
 // @Summary List commands
-// @Description List all commands or commands for a specific station
+// @Description List all commands or commands for a specific station or group. Users assigned to a group only see commands to its stations.
 // @Tags commands
 // @Produce json
 // @Param station_id query int false "Station ID to filter commands"
+// @Param group_id query int false "Only commands to stations in this group or the groups below it"
 // @Security BearerAuth
 // @Success 200 {array} models.Command
 // @Failure 400 {object} ErrorResponse
@@ -103,6 +109,10 @@ func (h *CommandHandler) CreateCommand(c *gin.Context) {
 // @Router /commands [get]
 func (h *CommandHandler) ListCommands(c *gin.Context) {
 	stationIDStr := c.Query("station_id")
+	scope, ok := stationScope(c, h.groupService)
+	if !ok {
+		return
+	}
 
 	var commands []models.Command
 	var err error
@@ -123,7 +133,14 @@ func (h *CommandHandler) ListCommands(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, commands)
+	out := []models.Command{}
+	for _, cmd := range commands {
+		if inScope(scope, cmd.ToStationID) {
+			out = append(out, cmd)
+		}
+	}
+
+	c.JSON(http.StatusOK, out)
 }
 
 // GetCommand retrieves a specific command
@@ -152,6 +169,9 @@ func (h *CommandHandler) GetCommand(c *gin.Context) {
 		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Command not found"})
 		return
 	}
+	if !canSeeStation(c, h.groupService, command.ToStationID) {
+		return
+	}
 
 	c.JSON(http.StatusOK, command)
 }
@@ -0,0 +1,468 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/services"
+)
+
+type EquipmentHandler struct {
+	equipmentService *services.EquipmentService
+	groupService     *services.GroupService
+}
+
+func NewEquipmentHandler(equipmentService *services.EquipmentService, groupService *services.GroupService) *EquipmentHandler {
+	return &EquipmentHandler{equipmentService: equipmentService, groupService: groupService}
+}
+
+// CreateEquipmentRequest represents the create equipment request payload
+type CreateEquipmentRequest struct {
+	Type        string `json:"type" binding:"required" example:"GENERATOR"`
+	Model       string `json:"model" binding:"required" example:"Cummins C22D5"`
+	Serial      string `json:"serial,omitempty" example:"GEN-2023-0042"`
+	InstalledAt int64  `json:"installed_at,omitempty" example:"1672531200"`
+	Status      string `json:"status,omitempty" example:"OPERATIONAL"`
+	Note        string `json:"note,omitempty" example:"Backup generator"`
+	NextDueAt   *int64 `json:"next_due_at,omitempty" example:"1735689600"`
+}
+
+// UpdateEquipmentRequest represents the update equipment request payload
+type UpdateEquipmentRequest struct {
+	Type        *string `json:"type,omitempty" example:"GENERATOR"`
+	Model       *string `json:"model,omitempty" example:"Cummins C22D5"`
+	Serial      *string `json:"serial,omitempty" example:"GEN-2023-0042"`
+	InstalledAt *int64  `json:"installed_at,omitempty" example:"1672531200"`
+	Status      *string `json:"status,omitempty" example:"UNDER_REPAIR"`
+	Note        *string `json:"note,omitempty" example:"Fuel pump replaced"`
+	NextDueAt   *int64  `json:"next_due_at,omitempty" example:"1735689600"`
+}
+
+// MaintenanceRequest represents a maintenance record payload
+type MaintenanceRequest struct {
+	PerformedBy string   `json:"performed_by" binding:"required" example:"Nguyen Van A"`
+	PerformedAt int64    `json:"performed_at,omitempty" example:"1704067200"`
+	WorkDone    string   `json:"work_done" binding:"required" example:"Oil and filter change"`
+	Parts       []string `json:"parts,omitempty" example:"oil filter"`
+	NextDueAt   *int64   `json:"next_due_at,omitempty" example:"1719792000"`
+}
+
+// writeEquipmentError maps service errors to responses.
+func writeEquipmentError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidEquipment):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case err.Error() == "station not found":
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
+	case err.Error() == "equipment not found":
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Equipment not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
+
+// equipmentForRead loads the equipment in the :id path parameter and checks
+// that its station is in the user's group, writing the error response
+// otherwise.
+func (h *EquipmentHandler) equipmentForRead(c *gin.Context) (*models.Equipment, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid equipment ID"})
+		return nil, false
+	}
+	eq, err := h.equipmentService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Equipment not found"})
+		return nil, false
+	}
+	if !canSeeStation(c, h.groupService, eq.StationID) {
+		return nil, false
+	}
+	return eq, true
+}
+
+// equipmentForWrite is equipmentForRead that also checks that the user may
+// change the equipment.
+func (h *EquipmentHandler) equipmentForWrite(c *gin.Context) (*models.Equipment, *models.User, bool) {
+	eq, ok := h.equipmentForRead(c)
+	if !ok {
+		return nil, nil, false
+	}
+	user, ok := currentUser(c)
+	if !ok {
+		return nil, nil, false
+	}
+	if !ownsStation(user, eq.StationID) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Operators can only manage equipment of their own station"})
+		return nil, nil, false
+	}
+	return eq, user, true
+}
+
+// ListStationEquipment godoc
+// @Summary List station equipment
+// @Description Equipment registered at a station.
+// @Tags equipment
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Station ID"
+// @Success 200 {array} models.Equipment "Equipment"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - station outside the user's group"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/{id}/equipment [get]
+func (h *EquipmentHandler) ListStationEquipment(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+
+	equipment, err := h.equipmentService.List(uint(stationID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list equipment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, equipment)
+}
+
+// CreateEquipment godoc
+// @Summary Register equipment at a station
+// @Description Add a radar, generator, comms set, UPS or other unit to a station. Serial numbers must be unique. Operators assigned to a station may only register equipment there.
+// @Tags equipment
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Station ID"
+// @Param request body CreateEquipmentRequest true "Equipment data"
+// @Success 201 {object} models.Equipment "Equipment created"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - not the operator's station"
+// @Failure 404 {object} ErrorResponse "Station not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/{id}/equipment [post]
+func (h *EquipmentHandler) CreateEquipment(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if !ownsStation(user, uint(stationID)) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Operators can only manage equipment of their own station"})
+		return
+	}
+
+	var req CreateEquipmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	eq := &models.Equipment{
+		StationID:   uint(stationID),
+		Type:        req.Type,
+		Model:       req.Model,
+		Serial:      req.Serial,
+		InstalledAt: req.InstalledAt,
+		Status:      req.Status,
+		Note:        req.Note,
+		NextDueAt:   req.NextDueAt,
+	}
+	if err := h.equipmentService.Create(eq); err != nil {
+		writeEquipmentError(c, err, "Failed to create equipment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, eq)
+}
+
+// ListEquipment godoc
+// @Summary List equipment
+// @Description Equipment of the stations the user can see (or of a group), or of one station with station_id.
+// @Tags equipment
+// @Produce json
+// @Security ApiKeyAuth
+// @Param station_id query int false "Station ID"
+// @Param group_id query int false "Only stations in this group or the groups below it"
+// @Success 200 {array} models.Equipment "Equipment"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /equipment [get]
+func (h *EquipmentHandler) ListEquipment(c *gin.Context) {
+	var stationID uint64
+	if raw := c.Query("station_id"); raw != "" {
+		var err error
+		if stationID, err = strconv.ParseUint(raw, 10, 32); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+			return
+		}
+	}
+
+	scope, ok := stationScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	equipment, err := h.equipmentService.List(uint(stationID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list equipment"})
+		return
+	}
+	out := []models.Equipment{}
+	for _, eq := range equipment {
+		if inScope(scope, eq.StationID) {
+			out = append(out, eq)
+		}
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// GetEquipment godoc
+// @Summary Get equipment
+// @Tags equipment
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Equipment ID"
+// @Success 200 {object} models.Equipment "Equipment"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - station outside the user's group"
+// @Failure 404 {object} ErrorResponse "Equipment not found"
+// @Router /equipment/{id} [get]
+func (h *EquipmentHandler) GetEquipment(c *gin.Context) {
+	eq, ok := h.equipmentForRead(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, eq)
+}
+
+// UpdateEquipment godoc
+// @Summary Update equipment
+// @Description Update equipment details; omitted fields are left unchanged. Operators assigned to a station may only update its equipment.
+// @Tags equipment
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Equipment ID"
+// @Param request body UpdateEquipmentRequest true "Fields to update"
+// @Success 200 {object} models.Equipment "Equipment updated"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - not the operator's station"
+// @Failure 404 {object} ErrorResponse "Equipment not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /equipment/{id} [put]
+func (h *EquipmentHandler) UpdateEquipment(c *gin.Context) {
+	eq, _, ok := h.equipmentForWrite(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateEquipmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+	if req.Type != nil {
+		eq.Type = *req.Type
+	}
+	if req.Model != nil {
+		eq.Model = *req.Model
+	}
+	if req.Serial != nil {
+		eq.Serial = *req.Serial
+	}
+	if req.InstalledAt != nil {
+		eq.InstalledAt = *req.InstalledAt
+	}
+	if req.Status != nil {
+		eq.Status = *req.Status
+	}
+	if req.Note != nil {
+		eq.Note = *req.Note
+	}
+	if req.NextDueAt != nil {
+		eq.NextDueAt = req.NextDueAt
+	}
+
+	if err := h.equipmentService.Update(eq); err != nil {
+		writeEquipmentError(c, err, "Failed to update equipment")
+		return
+	}
+
+	c.JSON(http.StatusOK, eq)
+}
+
+// DeleteEquipment godoc
+// @Summary Delete equipment
+// @Description Remove equipment and its maintenance log. Operators assigned to a station may only delete its equipment.
+// @Tags equipment
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Equipment ID"
+// @Success 200 {object} map[string]string "Equipment deleted successfully"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - not the operator's station"
+// @Failure 404 {object} ErrorResponse "Equipment not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /equipment/{id} [delete]
+func (h *EquipmentHandler) DeleteEquipment(c *gin.Context) {
+	eq, _, ok := h.equipmentForWrite(c)
+	if !ok {
+		return
+	}
+
+	if err := h.equipmentService.Delete(eq.ID); err != nil {
+		writeEquipmentError(c, err, "Failed to delete equipment")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Equipment deleted successfully"})
+}
+
+// ListMaintenance godoc
+// @Summary List maintenance records
+// @Description Maintenance log of equipment, most recent first.
+// @Tags equipment
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Equipment ID"
+// @Success 200 {array} models.MaintenanceRecord "Maintenance records"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - station outside the user's group"
+// @Failure 404 {object} ErrorResponse "Equipment not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /equipment/{id}/maintenance [get]
+func (h *EquipmentHandler) ListMaintenance(c *gin.Context) {
+	eq, ok := h.equipmentForRead(c)
+	if !ok {
+		return
+	}
+
+	records, err := h.equipmentService.MaintenanceLog(eq.ID)
+	if err != nil {
+		writeEquipmentError(c, err, "Failed to get maintenance log")
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+// AddMaintenance godoc
+// @Summary Log maintenance
+// @Description Record maintenance work on equipment. performed_at defaults to now. The latest record sets the equipment's next due date. Operators assigned to a station may only log maintenance of its equipment.
+// @Tags equipment
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Equipment ID"
+// @Param request body MaintenanceRequest true "Maintenance record"
+// @Success 201 {object} models.MaintenanceRecord "Maintenance recorded"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - not the operator's station"
+// @Failure 404 {object} ErrorResponse "Equipment not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /equipment/{id}/maintenance [post]
+func (h *EquipmentHandler) AddMaintenance(c *gin.Context) {
+	eq, user, ok := h.equipmentForWrite(c)
+	if !ok {
+		return
+	}
+
+	var req MaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	rec := &models.MaintenanceRecord{
+		EquipmentID: eq.ID,
+		PerformedBy: req.PerformedBy,
+		PerformedAt: req.PerformedAt,
+		WorkDone:    req.WorkDone,
+		Parts:       req.Parts,
+		NextDueAt:   req.NextDueAt,
+		RecordedBy:  user.Username,
+	}
+	if err := h.equipmentService.AddMaintenance(rec); err != nil {
+		writeEquipmentError(c, err, "Failed to record maintenance")
+		return
+	}
+
+	c.JSON(http.StatusCreated, rec)
+}
+
+// MaintenanceDue godoc
+// @Summary Equipment due for maintenance
+// @Description Equipment in service across the stations the user can see (or a group, or one station with station_id) whose next maintenance is overdue or due within the given number of days, most urgent first.
+// @Tags equipment
+// @Produce json
+// @Security ApiKeyAuth
+// @Param within_days query int false "Look-ahead in days (default 30)"
+// @Param station_id query int false "Station ID"
+// @Param group_id query int false "Only stations in this group or the groups below it"
+// @Success 200 {array} services.MaintenanceDue "Equipment due for maintenance"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /equipment/maintenance-due [get]
+func (h *EquipmentHandler) MaintenanceDue(c *gin.Context) {
+	withinDays := 30
+	if raw := c.Query("within_days"); raw != "" {
+		var err error
+		if withinDays, err = strconv.Atoi(raw); err != nil || withinDays < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "within_days must be a non-negative integer"})
+			return
+		}
+	}
+	var stationID uint64
+	if raw := c.Query("station_id"); raw != "" {
+		var err error
+		if stationID, err = strconv.ParseUint(raw, 10, 32); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+			return
+		}
+	}
+
+	scope, ok := stationScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	due, err := h.equipmentService.DueForMaintenance(withinDays, uint(stationID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get maintenance report"})
+		return
+	}
+	out := []services.MaintenanceDue{}
+	for _, d := range due {
+		if inScope(scope, d.StationID) {
+			out = append(out, d)
+		}
+	}
+
+	c.JSON(http.StatusOK, out)
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"io"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/services"
+)
+
+type EventHandler struct {
+	notifier *services.Notifier
+}
+
+func NewEventHandler(notifier *services.Notifier) *EventHandler {
+	return &EventHandler{notifier: notifier}
+}
+
+// Stream pushes real-time events over Server-Sent Events
+// @Summary Real-time event stream
+// @Description Server-Sent Events stream of real-time notifications such as watchlist alerts (HQ only). A "ping" event is sent every 30 seconds to keep the connection open.
+// @Tags events
+// @Produce text/event-stream
+// @Security ApiKeyAuth
+// @Success 200 {object} services.Event
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Router /events/stream [get]
+func (h *EventHandler) Stream(c *gin.Context) {
+	events, cancel := h.notifier.Subscribe()
+	defer cancel()
+
+	ping := time.NewTicker(30 * time.Second)
+	defer ping.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // disable nginx buffering
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(ev.Type, ev)
+			return true
+		case t := <-ping.C:
+			c.SSEvent("ping", t.Unix())
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/services"
+)
+
+type ExportHandler struct {
+	exportService *services.ExportService
+	groupService  *services.GroupService
+}
+
+func NewExportHandler(exportService *services.ExportService, groupService *services.GroupService) *ExportHandler {
+	return &ExportHandler{exportService: exportService, groupService: groupService}
+}
+
+// scopeFeatures drops the features whose station, read from the key
+// property, is outside scope.
+func scopeFeatures(fc *services.GeoJSONFeatureCollection, key string, scope map[uint]bool) *services.GeoJSONFeatureCollection {
+	if scope == nil {
+		return fc
+	}
+	kept := fc.Features[:0]
+	for _, f := range fc.Features {
+		if id, ok := f.Properties[key].(uint); ok && scope[id] {
+			kept = append(kept, f)
+		}
+	}
+	fc.Features = kept
+	return fc
+}
+
+// writeGeo sends fc as GeoJSON or, for paths ending in ".kml", as KML.
+func writeGeo(c *gin.Context, name string, fc *services.GeoJSONFeatureCollection) {
+	if strings.HasSuffix(c.Request.URL.Path, ".kml") {
+		c.Header("Content-Disposition", `attachment; filename="`+name+`.kml"`)
+		c.Header("Content-Type", "application/vnd.google-earth.kml+xml")
+		c.Status(http.StatusOK)
+		if err := services.WriteKML(c.Writer, name, fc); err != nil {
+			c.Error(err)
+		}
+		return
+	}
+	c.Header("Content-Disposition", `attachment; filename="`+name+`.geojson"`)
+	c.Header("Content-Type", "application/geo+json")
+	c.JSON(http.StatusOK, fc)
+}
+
+// ExportStations godoc
+// @Summary Export stations as GeoJSON or KML
+// @Description The stations the user can see (or of a group) as points with their current status (from the schedule), note, elevation, distance to coast, radar parameters and daily schedules as properties. Use the .geojson or .kml suffix to pick the format.
+// @Tags export
+// @Produce json,application/vnd.google-earth.kml+xml
+// @Security ApiKeyAuth
+// @Param group_id query int false "Only stations in this group or the groups below it"
+// @Success 200 {object} services.GeoJSONFeatureCollection
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Group not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /export/stations.geojson [get]
+// @Router /export/stations.kml [get]
+func (h *ExportHandler) ExportStations(c *gin.Context) {
+	scope, ok := stationScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	fc, err := h.exportService.Stations()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to export stations"})
+		return
+	}
+	writeGeo(c, "stations", scopeFeatures(fc, "id", scope))
+}
+
+// ExportCoverage godoc
+// @Summary Export radar coverage zones as GeoJSON or KML
+// @Description Coverage polygons of the stations with radar parameters that the user can see (or of a group). Only stations ACTIVE right now (on schedule and healthy) are included unless include_inactive=true.
+// @Tags export
+// @Produce json,application/vnd.google-earth.kml+xml
+// @Security ApiKeyAuth
+// @Param target_height query number false "Target height above sea level in metres (default 10)"
+// @Param include_inactive query bool false "Also include stations that are not ACTIVE"
+// @Param group_id query int false "Only stations in this group or the groups below it"
+// @Success 200 {object} services.GeoJSONFeatureCollection
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Group not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /export/coverage.geojson [get]
+// @Router /export/coverage.kml [get]
+func (h *ExportHandler) ExportCoverage(c *gin.Context) {
+	targetHeight, err := parseTargetHeight(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	scope, ok := stationScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	fc, err := h.exportService.Coverage(targetHeight, c.Query("include_inactive") != "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to export coverage"})
+		return
+	}
+	writeGeo(c, "coverage", scopeFeatures(fc, "station_id", scope))
+}
+
+// ExportVesselPositions godoc
+// @Summary Export latest vessel positions as GeoJSON or KML
+// @Description The latest reported position of every vessel as a point with name, MMSI, course, speed, source and timestamp.
+// @Tags export
+// @Produce json,application/vnd.google-earth.kml+xml
+// @Security ApiKeyAuth
+// @Success 200 {object} services.GeoJSONFeatureCollection
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /export/vessels.geojson [get]
+// @Router /export/vessels.kml [get]
+func (h *ExportHandler) ExportVesselPositions(c *gin.Context) {
+	fc, err := h.exportService.VesselPositions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to export vessel positions"})
+		return
+	}
+	writeGeo(c, "vessels", fc)
+}
+
+// ExportVesselTrack godoc
+// @Summary Export a vessel track as GeoJSON or KML
+// @Description The reported positions of a vessel as a line, with the report times in the "timestamps" property.
+// @Tags export
+// @Produce json,application/vnd.google-earth.kml+xml
+// @Security ApiKeyAuth
+// @Param id path int true "Vessel ID"
+// @Param from query int false "Start time (Unix seconds)"
+// @Param to query int false "End time (Unix seconds)"
+// @Success 200 {object} services.GeoJSONFeatureCollection
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Vessel not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /export/vessels/{id}/track.geojson [get]
+// @Router /export/vessels/{id}/track.kml [get]
+func (h *ExportHandler) ExportVesselTrack(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid vessel ID"})
+		return
+	}
+	var from, to int64
+	if raw := c.Query("from"); raw != "" {
+		if from, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid from timestamp"})
+			return
+		}
+	}
+	if raw := c.Query("to"); raw != "" {
+		if to, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid to timestamp"})
+			return
+		}
+	}
+
+	fc, err := h.exportService.VesselTrack(uint(id), from, to)
+	if err != nil {
+		if err.Error() == "vessel not found" {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Vessel not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to export track"})
+		return
+	}
+	writeGeo(c, "track-"+c.Param("id"), fc)
+}
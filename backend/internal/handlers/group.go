@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/services"
+)
+
+type GroupHandler struct {
+	groupService   *services.GroupService
+	stationService *services.StationService
+}
+
+func NewGroupHandler(groupService *services.GroupService, stationService *services.StationService) *GroupHandler {
+	return &GroupHandler{groupService: groupService, stationService: stationService}
+}
+
+// GroupRequest represents the create/update station group request payload
+type GroupRequest struct {
+	Name        string `json:"name" binding:"required" example:"North Coast"`
+	Kind        string `json:"kind" binding:"required" example:"REGION"`
+	ParentID    *uint  `json:"parent_id,omitempty" example:"1"`
+	Description string `json:"description,omitempty" example:"Quang Ninh to Ninh Binh"`
+}
+
+// userScope returns the stations the current user may see, or nil for all.
+// It writes the error response itself.
+func userScope(c *gin.Context, groups *services.GroupService) (map[uint]bool, bool) {
+	user, ok := currentUser(c)
+	if !ok {
+		return nil, false
+	}
+	if user.GroupID == nil {
+		return nil, true
+	}
+	ids, err := groups.StationIDs(*user.GroupID)
+	if err != nil {
+		return map[uint]bool{}, true // Group gone: nothing visible
+	}
+	return ids, true
+}
+
+// stationScope is userScope narrowed by the group_id query parameter.
+func stationScope(c *gin.Context, groups *services.GroupService) (map[uint]bool, bool) {
+	scope, ok := userScope(c, groups)
+	if !ok {
+		return nil, false
+	}
+	raw := c.Query("group_id")
+	if raw == "" {
+		return scope, true
+	}
+	id, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid group ID"})
+		return nil, false
+	}
+	ids, err := groups.StationIDs(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Group not found"})
+		return nil, false
+	}
+	if scope != nil {
+		for stID := range ids {
+			if !scope[stID] {
+				delete(ids, stID)
+			}
+		}
+	}
+	return ids, true
+}
+
+func inScope(scope map[uint]bool, stationID uint) bool {
+	return scope == nil || scope[stationID]
+}
+
+// canSeeStation reports whether the user's group includes the station,
+// writing a 403 response otherwise.
+func canSeeStation(c *gin.Context, groups *services.GroupService, stationID uint) bool {
+	scope, ok := userScope(c, groups)
+	if !ok {
+		return false
+	}
+	if !inScope(scope, stationID) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Station is outside your group"})
+		return false
+	}
+	return true
+}
+
+func writeGroupError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidGroup):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case err.Error() == "group not found":
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Group not found"})
+	case err.Error() == "group is not empty":
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Group still has sectors, stations or users"})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
+
+// CreateGroup godoc
+// @Summary Create a station group (Admin only)
+// @Description Create a region, or a sector (command unit) inside a region.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body GroupRequest true "Group data"
+// @Success 201 {object} models.StationGroup "Group created"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /groups [post]
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	var req GroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	group := &models.StationGroup{Name: req.Name, Kind: req.Kind, ParentID: req.ParentID, Description: req.Description}
+	if err := h.groupService.Create(group); err != nil {
+		writeGroupError(c, err, "Failed to create group")
+		return
+	}
+
+	c.JSON(http.StatusCreated, group)
+}
+
+// ListGroups godoc
+// @Summary List station groups
+// @Tags groups
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.StationGroup "Groups"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /groups [get]
+func (h *GroupHandler) ListGroups(c *gin.Context) {
+	groups, err := h.groupService.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list groups"})
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// GetGroup godoc
+// @Summary Get a station group
+// @Tags groups
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Group ID"
+// @Success 200 {object} models.StationGroup "Group"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Group not found"
+// @Router /groups/{id} [get]
+func (h *GroupHandler) GetGroup(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid group ID"})
+		return
+	}
+
+	group, err := h.groupService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Group not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// UpdateGroup godoc
+// @Summary Update a station group (Admin only)
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Group ID"
+// @Param request body GroupRequest true "Group data"
+// @Success 200 {object} models.StationGroup "Group updated"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Group not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /groups/{id} [put]
+func (h *GroupHandler) UpdateGroup(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid group ID"})
+		return
+	}
+
+	var req GroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	group := &models.StationGroup{ID: uint(id), Name: req.Name, Kind: req.Kind, ParentID: req.ParentID, Description: req.Description}
+	if err := h.groupService.Update(group); err != nil {
+		writeGroupError(c, err, "Failed to update group")
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// DeleteGroup godoc
+// @Summary Delete a station group (Admin only)
+// @Description Delete a group that no longer has sectors, stations or users.
+// @Tags groups
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Group ID"
+// @Success 200 {object} map[string]string "Group deleted successfully"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Group not found"
+// @Failure 409 {object} ErrorResponse "Group not empty"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /groups/{id} [delete]
+func (h *GroupHandler) DeleteGroup(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid group ID"})
+		return
+	}
+
+	if err := h.groupService.Delete(uint(id)); err != nil {
+		writeGroupError(c, err, "Failed to delete group")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Group deleted successfully"})
+}
+
+// ListGroupStations godoc
+// @Summary List the stations of a group
+// @Description Stations in a group and the groups below it, with their current status. Users assigned to a group only see its stations.
+// @Tags groups
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Group ID"
+// @Success 200 {array} models.Station "Stations"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Group not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /groups/{id}/stations [get]
+func (h *GroupHandler) ListGroupStations(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid group ID"})
+		return
+	}
+	ids, err := h.groupService.StationIDs(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Group not found"})
+		return
+	}
+	scope, ok := userScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	stations, err := h.stationService.ListWithStatus()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list stations"})
+		return
+	}
+	out := []models.Station{}
+	for _, st := range stations {
+		if ids[st.ID] && inScope(scope, st.ID) {
+			out = append(out, st)
+		}
+	}
+
+	c.JSON(http.StatusOK, out)
+}
@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/services"
+)
+
+type HandoverHandler struct {
+	handoverService *services.HandoverService
+	stationService  *services.StationService
+	groupService    *services.GroupService
+}
+
+func NewHandoverHandler(handoverService *services.HandoverService, stationService *services.StationService, groupService *services.GroupService) *HandoverHandler {
+	return &HandoverHandler{
+		handoverService: handoverService,
+		stationService:  stationService,
+		groupService:    groupService,
+	}
+}
+
+// CreateHandoverRequest represents the handover recorded by the outgoing commander
+type CreateHandoverRequest struct {
+	At    *int64                `json:"at,omitempty" example:"1756713600"` // a time in the outgoing watch (default now)
+	Items []models.HandoverItem `json:"items"`
+	Note  string                `json:"note,omitempty" example:"Quiet watch"`
+}
+
+// SignHandoverRequest represents the incoming commander's sign-off
+type SignHandoverRequest struct {
+	Note string `json:"note,omitempty" example:"Items accepted"`
+}
+
+func writeHandoverError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidHandover):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, services.ErrNotWatchCommander):
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	case err.Error() == "station not found":
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
+	case err.Error() == "handover not found":
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Handover not found"})
+	case err.Error() == "handover already recorded":
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Handover already recorded for this watch"})
+	case err.Error() == "handover already signed":
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Handover already signed"})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
+
+// CreateHandover godoc
+// @Summary Record a shift handover
+// @Description The outgoing commander records the handover items (open commands, equipment issues, notable contacts) of the watch in progress at the given time, or of the last watch that ended within the past 24 hours, to the next watch of the station. When the outgoing commander is linked to a user account only that user may record it (403). One handover per watch (409).
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param station_id path int true "Station ID"
+// @Param handover body CreateHandoverRequest true "Handover items"
+// @Security BearerAuth
+// @Success 201 {object} models.Handover
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /station-schedules/station/{station_id}/handovers [post]
+func (h *HandoverHandler) CreateHandover(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("station_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+
+	var req CreateHandoverRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if !ownsStation(user, uint(stationID)) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Operators can only hand over watches of their own station"})
+		return
+	}
+
+	at := time.Now().Unix()
+	if req.At != nil {
+		at = *req.At
+	}
+	handover := &models.Handover{StationID: uint(stationID), Items: req.Items, Note: req.Note}
+	if err := h.handoverService.Create(handover, at, user.Username); err != nil {
+		writeHandoverError(c, err, "Failed to record handover")
+		return
+	}
+
+	c.JSON(http.StatusCreated, handover)
+}
+
+// ListHandovers godoc
+// @Summary Get the handover log of a station
+// @Description The handovers of a station, newest watch first.
+// @Tags schedules
+// @Produce json
+// @Param station_id path int true "Station ID"
+// @Security BearerAuth
+// @Success 200 {array} models.Handover
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /station-schedules/station/{station_id}/handovers [get]
+func (h *HandoverHandler) ListHandovers(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("station_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+
+	handovers, err := h.handoverService.List(uint(stationID), false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list handovers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, handovers)
+}
+
+// GetHandover godoc
+// @Summary Get a handover
+// @Tags schedules
+// @Produce json
+// @Param station_id path int true "Station ID"
+// @Param handover_id path int true "Handover ID"
+// @Security BearerAuth
+// @Success 200 {object} models.Handover
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /station-schedules/station/{station_id}/handovers/{handover_id} [get]
+func (h *HandoverHandler) GetHandover(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("station_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	handoverID, err := strconv.ParseUint(c.Param("handover_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid handover ID"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+
+	handover, err := h.handoverService.GetByID(uint(stationID), uint(handoverID))
+	if err != nil {
+		writeHandoverError(c, err, "Failed to get handover")
+		return
+	}
+
+	c.JSON(http.StatusOK, handover)
+}
+
+// SignHandover godoc
+// @Summary Sign a shift handover
+// @Description The incoming commander accepts the handover items. The user who recorded the handover cannot sign it, and when the incoming commander is linked to a user account only that user may sign (403).
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param station_id path int true "Station ID"
+// @Param handover_id path int true "Handover ID"
+// @Param sign body SignHandoverRequest false "Sign-off remarks"
+// @Security BearerAuth
+// @Success 200 {object} models.Handover
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /station-schedules/station/{station_id}/handovers/{handover_id}/sign [post]
+func (h *HandoverHandler) SignHandover(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("station_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	handoverID, err := strconv.ParseUint(c.Param("handover_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid handover ID"})
+		return
+	}
+
+	var req SignHandoverRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+			return
+		}
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if !ownsStation(user, uint(stationID)) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Operators can only sign handovers of their own station"})
+		return
+	}
+
+	handover, err := h.handoverService.Sign(uint(stationID), uint(handoverID), user.Username, req.Note)
+	if err != nil {
+		writeHandoverError(c, err, "Failed to sign handover")
+		return
+	}
+
+	c.JSON(http.StatusOK, handover)
+}
+
+// ListUnsignedHandovers godoc
+// @Summary Get handovers waiting for sign-off
+// @Description The handovers of every station the user can see (or of a group) that the incoming commander has not signed yet, oldest incoming watch first.
+// @Tags schedules
+// @Produce json
+// @Param group_id query int false "Only stations in this group or the groups below it"
+// @Security BearerAuth
+// @Success 200 {array} models.Handover
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /station-schedules/handovers/unsigned [get]
+func (h *HandoverHandler) ListUnsignedHandovers(c *gin.Context) {
+	scope, ok := stationScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	handovers, err := h.handoverService.List(0, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list handovers"})
+		return
+	}
+	out := []models.Handover{}
+	for _, ho := range handovers {
+		if inScope(scope, ho.StationID) {
+			out = append(out, ho)
+		}
+	}
+
+	c.JSON(http.StatusOK, out)
+}
@@ -0,0 +1,474 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/services"
+)
+
+type HealthHandler struct {
+	healthService *services.HealthService
+	groupService  *services.GroupService
+}
+
+func NewHealthHandler(healthService *services.HealthService, groupService *services.GroupService) *HealthHandler {
+	return &HealthHandler{healthService: healthService, groupService: groupService}
+}
+
+// HeartbeatRequest represents the heartbeat payload posted by station agents
+type HeartbeatRequest struct {
+	TransmitterOn bool     `json:"transmitter_on" example:"true"`
+	Uptime        int64    `json:"uptime" example:"86400"`
+	Temperature   float64  `json:"temperature" example:"41.5"`
+	ErrorCodes    []string `json:"error_codes,omitempty" example:"E101"`
+}
+
+// PostHeartbeat godoc
+// @Summary Post a station heartbeat
+// @Description Station agents post heartbeats with equipment metrics. The station is marked DEGRADED when heartbeats are late or report errors or overheating, and OFFLINE when they stop or the transmitter is off. Operators assigned to a station may only post for that station.
+// @Tags stations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Station ID"
+// @Param request body HeartbeatRequest true "Equipment metrics"
+// @Success 200 {object} models.StationHealth "Evaluated health"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - not the operator's station"
+// @Failure 404 {object} ErrorResponse "Station not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/{id}/heartbeat [post]
+func (h *HealthHandler) PostHeartbeat(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+	if user, ok := currentUser(c); ok && !ownsStation(user, uint(stationID)) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Operators can only report for their own station"})
+		return
+	}
+
+	var req HeartbeatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	health, err := h.healthService.Record(&models.Heartbeat{
+		StationID:     uint(stationID),
+		TransmitterOn: req.TransmitterOn,
+		Uptime:        req.Uptime,
+		Temperature:   req.Temperature,
+		ErrorCodes:    req.ErrorCodes,
+	})
+	if err != nil {
+		switch err.Error() {
+		case "station not found":
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
+		case "uptime must not be negative":
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to record heartbeat"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, health)
+}
+
+// GetHealth godoc
+// @Summary Get station health
+// @Description Current health, combined status and latest heartbeat of a station.
+// @Tags stations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Station ID"
+// @Success 200 {object} models.StationHealth "Station health"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - station outside the user's group"
+// @Failure 404 {object} ErrorResponse "Station not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/{id}/health [get]
+func (h *HealthHandler) GetHealth(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+
+	health, err := h.healthService.Evaluate(uint(stationID))
+	if err != nil {
+		if err.Error() == "station not found" {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get station health"})
+		return
+	}
+
+	c.JSON(http.StatusOK, health)
+}
+
+// GetStatusHistory godoc
+// @Summary Get station status history
+// @Description Changes of the combined station status (schedule and health), newest first.
+// @Tags stations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Station ID"
+// @Param from query int false "Start time (Unix seconds)"
+// @Param to query int false "End time (Unix seconds)"
+// @Param limit query int false "Maximum number of entries (default 100)"
+// @Success 200 {array} models.StationStatusChange "Status changes"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - station outside the user's group"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/{id}/status-history [get]
+func (h *HealthHandler) GetStatusHistory(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+	var from, to int64
+	if raw := c.Query("from"); raw != "" {
+		if from, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid from timestamp"})
+			return
+		}
+	}
+	if raw := c.Query("to"); raw != "" {
+		if to, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid to timestamp"})
+			return
+		}
+	}
+	limit := 100
+	if raw := c.Query("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be a positive integer"})
+			return
+		}
+	}
+
+	history, err := h.healthService.History(uint(stationID), from, to, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get status history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// defaultReportPeriod is the period covered by reports when from is omitted.
+const defaultReportPeriod = 30 * 24 * time.Hour
+
+// parsePeriod reads the from/to query parameters (Unix seconds). to
+// defaults to now and from to 30 days before to.
+func parsePeriod(c *gin.Context) (int64, int64, error) {
+	to := time.Now().Unix()
+	if raw := c.Query("to"); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return 0, 0, errors.New("Invalid to timestamp")
+		}
+		to = v
+	}
+	from := to - int64(defaultReportPeriod/time.Second)
+	if raw := c.Query("from"); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return 0, 0, errors.New("Invalid from timestamp")
+		}
+		from = v
+	}
+	if from >= to {
+		return 0, 0, errors.New("from must be before to")
+	}
+	return from, to, nil
+}
+
+// GetTimeline godoc
+// @Summary Get station status timeline
+// @Description Status of a station over a period as consecutive intervals, rebuilt from the status history. Time before the first recorded status has an empty status.
+// @Tags stations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Station ID"
+// @Param from query int false "Start time (Unix seconds, default 30 days before to)"
+// @Param to query int false "End time (Unix seconds, default now)"
+// @Success 200 {array} services.StatusInterval "Status intervals"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - station outside the user's group"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/{id}/timeline [get]
+func (h *HealthHandler) GetTimeline(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+	from, to, err := parsePeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	timeline, err := h.healthService.Timeline(uint(stationID), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get timeline"})
+		return
+	}
+
+	c.JSON(http.StatusOK, timeline)
+}
+
+// GetAvailability godoc
+// @Summary Get station availability
+// @Description Hours a station operated (ACTIVE or DEGRADED) over a period versus its scheduled hours, with the uptime percentage (operation on schedule only), the deviation from schedule split into missed and off-schedule hours, and the outage intervals.
+// @Tags stations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Station ID"
+// @Param from query int false "Start time (Unix seconds, default 30 days before to)"
+// @Param to query int false "End time (Unix seconds, default now)"
+// @Success 200 {object} services.Availability "Availability report"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - station outside the user's group"
+// @Failure 404 {object} ErrorResponse "Station not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/{id}/availability [get]
+func (h *HealthHandler) GetAvailability(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+	from, to, err := parsePeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	report, err := h.healthService.Availability(uint(stationID), from, to)
+	if err != nil {
+		if err.Error() == "station not found" {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to compute availability"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetFleetAvailability godoc
+// @Summary Get fleet availability
+// @Description Availability of every station the user can see (or of a group) over a period with fleet-wide totals.
+// @Tags stations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param from query int false "Start time (Unix seconds, default 30 days before to)"
+// @Param to query int false "End time (Unix seconds, default now)"
+// @Param group_id query int false "Only stations in this group or the groups below it"
+// @Success 200 {object} services.FleetAvailability "Fleet availability report"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Group not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/availability [get]
+func (h *HealthHandler) GetFleetAvailability(c *gin.Context) {
+	from, to, err := parsePeriod(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	scope, ok := stationScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	report, err := h.healthService.FleetAvailability(from, to, scope)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to compute availability"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// OverrideRequest represents a manual status override
+type OverrideRequest struct {
+	State     string `json:"state" binding:"required" example:"MAINTENANCE"`
+	Reason    string `json:"reason" binding:"required" example:"Replacing magnetron"`
+	ExpiresAt *int64 `json:"expires_at,omitempty" example:"1735689600"`
+}
+
+// GetOverride godoc
+// @Summary Get station status override
+// @Description The manual status override currently in effect for a station.
+// @Tags stations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Station ID"
+// @Success 200 {object} models.StationOverride "Override in effect"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - station outside the user's group"
+// @Failure 404 {object} ErrorResponse "No active override"
+// @Router /stations/{id}/override [get]
+func (h *HealthHandler) GetOverride(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+
+	ov := h.healthService.ActiveOverride(uint(stationID))
+	if ov == nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "No active override"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ov)
+}
+
+// SetOverride godoc
+// @Summary Set station status override
+// @Description Manually put a station in MAINTENANCE, OUT_OF_SERVICE or FORCED_ACTIVE (shown as ACTIVE). The override takes precedence over the schedule and heartbeats until it expires or is cleared, and is recorded in the status history. Operators assigned to a station may only override that station.
+// @Tags stations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Station ID"
+// @Param request body OverrideRequest true "Override state, reason and optional expiry (Unix seconds)"
+// @Success 200 {object} models.StationHealth "Evaluated health"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - not the operator's station"
+// @Failure 404 {object} ErrorResponse "Station not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/{id}/override [put]
+func (h *HealthHandler) SetOverride(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if !ownsStation(user, uint(stationID)) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Operators can only override their own station"})
+		return
+	}
+
+	var req OverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	health, err := h.healthService.SetOverride(&models.StationOverride{
+		StationID: uint(stationID),
+		State:     req.State,
+		Reason:    req.Reason,
+		SetBy:     user.Username,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidOverride):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case err.Error() == "station not found":
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to set override"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, health)
+}
+
+// ClearOverride godoc
+// @Summary Clear station status override
+// @Description Remove the manual status override of a station; its status is derived from the schedule and heartbeats again.
+// @Tags stations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Station ID"
+// @Success 200 {object} models.StationHealth "Evaluated health"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - not the operator's station"
+// @Failure 404 {object} ErrorResponse "Station not found or no active override"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/{id}/override [delete]
+func (h *HealthHandler) ClearOverride(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if !ownsStation(user, uint(stationID)) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Operators can only override their own station"})
+		return
+	}
+
+	health, err := h.healthService.ClearOverride(uint(stationID), user.Username)
+	if err != nil {
+		switch err.Error() {
+		case "station not found":
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
+		case "no active override":
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "No active override"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to clear override"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, health)
+}
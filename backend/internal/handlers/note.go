@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// AddNoteRequest represents a new station note
+type AddNoteRequest struct {
+	Text   string `json:"text" binding:"required" example:"Shift handed over, generator 2 on standby"`
+	Pinned bool   `json:"pinned" example:"false"`
+}
+
+// PinNoteRequest represents a pin or unpin of a station note
+type PinNoteRequest struct {
+	Pinned bool `json:"pinned" example:"true"`
+}
+
+// NotePage is one page of a station's note history
+type NotePage struct {
+	Notes    []models.StationNote `json:"notes"`
+	Page     int                  `json:"page"`
+	PageSize int                  `json:"page_size"`
+	Total    int                  `json:"total"`
+}
+
+// ListNotes godoc
+// @Summary Get station note history
+// @Description The note log of a station, newest first, one page at a time.
+// @Tags stations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Station ID"
+// @Param page query int false "Page number, from 1 (default 1)"
+// @Param page_size query int false "Notes per page (default 20, max 100)"
+// @Success 200 {object} NotePage "Page of notes"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - station outside the user's group"
+// @Failure 404 {object} ErrorResponse "Station not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/{id}/notes [get]
+func (h *StationHandler) ListNotes(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	page, pageSize := 1, 20
+	if raw := c.Query("page"); raw != "" {
+		if page, err = strconv.Atoi(raw); err != nil || page <= 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "page must be a positive integer"})
+			return
+		}
+	}
+	if raw := c.Query("page_size"); raw != "" {
+		if pageSize, err = strconv.Atoi(raw); err != nil || pageSize <= 0 || pageSize > 100 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "page_size must be between 1 and 100"})
+			return
+		}
+	}
+	if _, err := h.stationService.GetByID(uint(stationID)); err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+
+	notes, total, err := h.stationService.NoteHistory(uint(stationID), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get station notes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, NotePage{Notes: notes, Page: page, PageSize: pageSize, Total: total})
+}
+
+// AddNote godoc
+// @Summary Add a station note
+// @Description Append a note to the station's log. The latest pinned note is also shown as the station's note. Operators assigned to a station may only write notes there.
+// @Tags stations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Station ID"
+// @Param request body AddNoteRequest true "Note text"
+// @Success 201 {object} models.StationNote "Note added"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Station not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/{id}/notes [post]
+func (h *StationHandler) AddNote(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if !ownsStation(user, uint(stationID)) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Operators can only write notes for their own station"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+
+	var req AddNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	note, err := h.stationService.AddNote(uint(stationID), user.Username, req.Text, req.Pinned)
+	if err != nil {
+		switch err.Error() {
+		case "station not found":
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
+		case "note text is required":
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to add note"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, note)
+}
+
+// PinNote godoc
+// @Summary Pin or unpin a station note
+// @Description Change the pinned flag of a note; its text cannot be changed. Operators assigned to a station may only pin notes there.
+// @Tags stations
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Station ID"
+// @Param note_id path int true "Note ID"
+// @Param request body PinNoteRequest true "Pinned flag"
+// @Success 200 {object} models.StationNote "Note updated"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden"
+// @Failure 404 {object} ErrorResponse "Note not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/{id}/notes/{note_id} [put]
+func (h *StationHandler) PinNote(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	noteID, err := strconv.ParseUint(c.Param("note_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid note ID"})
+		return
+	}
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if !ownsStation(user, uint(stationID)) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Operators can only write notes for their own station"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+
+	var req PinNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	note, err := h.stationService.SetNotePinned(uint(stationID), uint(noteID), req.Pinned)
+	if err != nil {
+		if err.Error() == "note not found" {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Note not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update note"})
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
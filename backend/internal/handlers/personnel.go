@@ -0,0 +1,358 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/services"
+)
+
+// defaultDutyPeriod is how far ahead duty queries look by default.
+const defaultDutyPeriod = 7 * 24 * time.Hour
+
+type PersonnelHandler struct {
+	personnelService *services.PersonnelService
+	groupService     *services.GroupService
+}
+
+func NewPersonnelHandler(personnelService *services.PersonnelService, groupService *services.GroupService) *PersonnelHandler {
+	return &PersonnelHandler{personnelService: personnelService, groupService: groupService}
+}
+
+// PersonnelRequest represents the create/update personnel request payload
+type PersonnelRequest struct {
+	FullName       string   `json:"full_name" binding:"required" example:"Nguyen Van An"`
+	Rank           string   `json:"rank,omitempty" example:"Lt."`
+	Phone          string   `json:"phone,omitempty" example:"0912345678"`
+	Email          string   `json:"email,omitempty" example:"an.nv@example.com"`
+	Qualifications []string `json:"qualifications,omitempty" example:"RADAR_OPERATOR"`
+	Username       string   `json:"username,omitempty" example:"operator"` // linked user account
+	StationID      *uint    `json:"station_id,omitempty" example:"1"`
+}
+
+func (r *PersonnelRequest) toModel(id uint) *models.Personnel {
+	return &models.Personnel{
+		ID:             id,
+		FullName:       r.FullName,
+		Rank:           r.Rank,
+		Phone:          r.Phone,
+		Email:          r.Email,
+		Qualifications: r.Qualifications,
+		Username:       r.Username,
+		StationID:      r.StationID,
+	}
+}
+
+func writePersonnelError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidPersonnel):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case err.Error() == "personnel not found":
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Personnel not found"})
+	case err.Error() == "station not found":
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Station not found"})
+	case err.Error() == "personnel is assigned to schedules":
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Personnel is still assigned to schedules"})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
+
+// parseUpcoming reads from/to (Unix seconds), defaulting to the next week.
+func parseUpcoming(c *gin.Context) (int64, int64, bool) {
+	from := time.Now().Unix()
+	if raw := c.Query("from"); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid from timestamp"})
+			return 0, 0, false
+		}
+		from = v
+	}
+	to := from + int64(defaultDutyPeriod/time.Second)
+	if raw := c.Query("to"); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid to timestamp"})
+			return 0, 0, false
+		}
+		to = v
+	}
+	if from >= to {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "from must be before to"})
+		return 0, 0, false
+	}
+	return from, to, true
+}
+
+// CreatePersonnel godoc
+// @Summary Add a person to the duty roster (Admin only)
+// @Tags personnel
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body PersonnelRequest true "Personnel data"
+// @Success 201 {object} models.Personnel "Personnel created"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /personnel [post]
+func (h *PersonnelHandler) CreatePersonnel(c *gin.Context) {
+	var req PersonnelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	p := req.toModel(0)
+	if err := h.personnelService.Create(p); err != nil {
+		writePersonnelError(c, err, "Failed to create personnel")
+		return
+	}
+
+	c.JSON(http.StatusCreated, p)
+}
+
+// ListPersonnel godoc
+// @Summary List the duty roster
+// @Tags personnel
+// @Produce json
+// @Security ApiKeyAuth
+// @Param station_id query int false "Only people of this station"
+// @Success 200 {array} models.Personnel "Personnel"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /personnel [get]
+func (h *PersonnelHandler) ListPersonnel(c *gin.Context) {
+	var stationID uint64
+	if raw := c.Query("station_id"); raw != "" {
+		var err error
+		if stationID, err = strconv.ParseUint(raw, 10, 32); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+			return
+		}
+	}
+
+	list, err := h.personnelService.List(uint(stationID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list personnel"})
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// GetPersonnel godoc
+// @Summary Get a person of the duty roster
+// @Tags personnel
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Personnel ID"
+// @Success 200 {object} models.Personnel "Personnel"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Personnel not found"
+// @Router /personnel/{id} [get]
+func (h *PersonnelHandler) GetPersonnel(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid personnel ID"})
+		return
+	}
+
+	p, err := h.personnelService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Personnel not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, p)
+}
+
+// UpdatePersonnel godoc
+// @Summary Update a person of the duty roster (Admin only)
+// @Description Replace a personnel record. Names and phones copied onto schedules are refreshed when those schedules are next saved.
+// @Tags personnel
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Personnel ID"
+// @Param request body PersonnelRequest true "Personnel data"
+// @Success 200 {object} models.Personnel "Personnel updated"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Personnel not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /personnel/{id} [put]
+func (h *PersonnelHandler) UpdatePersonnel(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid personnel ID"})
+		return
+	}
+
+	var req PersonnelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	p := req.toModel(uint(id))
+	if err := h.personnelService.Update(p); err != nil {
+		writePersonnelError(c, err, "Failed to update personnel")
+		return
+	}
+
+	c.JSON(http.StatusOK, p)
+}
+
+// DeletePersonnel godoc
+// @Summary Remove a person from the duty roster (Admin only)
+// @Description Remove a person who is no longer assigned to any schedule or extra watch.
+// @Tags personnel
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Personnel ID"
+// @Success 200 {object} map[string]string "Personnel deleted successfully"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
+// @Failure 404 {object} ErrorResponse "Personnel not found"
+// @Failure 409 {object} ErrorResponse "Personnel still assigned"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /personnel/{id} [delete]
+func (h *PersonnelHandler) DeletePersonnel(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid personnel ID"})
+		return
+	}
+
+	if err := h.personnelService.Delete(uint(id)); err != nil {
+		writePersonnelError(c, err, "Failed to delete personnel")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Personnel deleted successfully"})
+}
+
+func (h *PersonnelHandler) writeDuties(c *gin.Context, id uint) {
+	from, to, ok := parseUpcoming(c)
+	if !ok {
+		return
+	}
+	scope, ok := userScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	duties, err := h.personnelService.Duties(id, from, to)
+	if err != nil {
+		writePersonnelError(c, err, "Failed to list duties")
+		return
+	}
+	out := []services.Duty{}
+	for _, d := range duties {
+		if inScope(scope, d.StationID) {
+			out = append(out, d)
+		}
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// ListDuties godoc
+// @Summary List a person's duties
+// @Description The watches a person is assigned to as commander or crew, across all stations, within a period (default the next 7 days). Users assigned to a group only see its stations.
+// @Tags personnel
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Personnel ID"
+// @Param from query int false "Start time (Unix seconds, default now)"
+// @Param to query int false "End time (Unix seconds, default 7 days after from)"
+// @Success 200 {array} services.Duty "Duties"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Personnel not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /personnel/{id}/duties [get]
+func (h *PersonnelHandler) ListDuties(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid personnel ID"})
+		return
+	}
+	h.writeDuties(c, uint(id))
+}
+
+// ListMyDuties godoc
+// @Summary List my duties
+// @Description The duties of the person linked to the current user account (see ListDuties).
+// @Tags personnel
+// @Produce json
+// @Security ApiKeyAuth
+// @Param from query int false "Start time (Unix seconds, default now)"
+// @Param to query int false "End time (Unix seconds, default 7 days after from)"
+// @Success 200 {array} services.Duty "Duties"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "No personnel linked to this user"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /personnel/me/duties [get]
+func (h *PersonnelHandler) ListMyDuties(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	p, err := h.personnelService.GetByUsername(user.Username)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "No personnel linked to this user"})
+		return
+	}
+	h.writeDuties(c, p.ID)
+}
+
+// ListDutyConflicts godoc
+// @Summary List double-booked personnel
+// @Description People assigned to two overlapping watches within a period (default the next 7 days), e.g. after schedules were changed before assignments were checked. Users assigned to a group only see conflicts involving its stations.
+// @Tags personnel
+// @Produce json
+// @Security ApiKeyAuth
+// @Param from query int false "Start time (Unix seconds, default now)"
+// @Param to query int false "End time (Unix seconds, default 7 days after from)"
+// @Success 200 {array} services.DutyConflict "Conflicts"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /personnel/conflicts [get]
+func (h *PersonnelHandler) ListDutyConflicts(c *gin.Context) {
+	from, to, ok := parseUpcoming(c)
+	if !ok {
+		return
+	}
+	scope, ok := userScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	conflicts, err := h.personnelService.Conflicts(from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to check duty conflicts"})
+		return
+	}
+	out := []services.DutyConflict{}
+	for _, cf := range conflicts {
+		if inScope(scope, cf.Watch.StationID) || inScope(scope, cf.Other.StationID) {
+			out = append(out, cf)
+		}
+	}
+
+	c.JSON(http.StatusOK, out)
+}
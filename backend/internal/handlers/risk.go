@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/services"
+)
+
+type RiskHandler struct {
+	riskService  *services.RiskService
+	groupService *services.GroupService
+}
+
+func NewRiskHandler(riskService *services.RiskService, groupService *services.GroupService) *RiskHandler {
+	return &RiskHandler{riskService: riskService, groupService: groupService}
+}
+
+// ListEncounters godoc
+// @Summary List collision-risk encounters
+// @Description Compute CPA/TCPA between tracked vessels near station coverage and return the pairs within the thresholds. Only pairs nearest to a station the user can see (or of a group) are returned.
+// @Tags risk
+// @Produce json
+// @Param cpa_nm query number false "Maximum CPA in nautical miles (default 0.5)"
+// @Param tcpa_min query number false "Maximum TCPA in minutes (default 30)"
+// @Param radius_nm query number false "Pair search radius in nautical miles (default 12)"
+// @Param coverage_nm query number false "Maximum distance from a station in nautical miles (default 48)"
+// @Param station_id query int false "Only consider vessels covered by this station"
+// @Param max_age query int false "Ignore positions older than this many seconds (default 900, 0 = no limit)"
+// @Param group_id query int false "Only stations in this group or the groups below it"
+// @Security ApiKeyAuth
+// @Success 200 {array} models.Encounter
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /risk/encounters [get]
+func (h *RiskHandler) ListEncounters(c *gin.Context) {
+	opts := services.DefaultEncounterOptions()
+
+	floatParams := map[string]*float64{
+		"cpa_nm":      &opts.CPAThreshold,
+		"tcpa_min":    &opts.TCPAThreshold,
+		"radius_nm":   &opts.SearchRadius,
+		"coverage_nm": &opts.CoverageRange,
+	}
+	for name, dst := range floatParams {
+		if v := c.Query(name); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil || f <= 0 {
+				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid " + name})
+				return
+			}
+			*dst = f
+		}
+	}
+	if v := c.Query("station_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+			return
+		}
+		if !canSeeStation(c, h.groupService, uint(id)) {
+			return
+		}
+		opts.StationID = uint(id)
+	}
+	if v := c.Query("max_age"); v != "" {
+		age, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || age < 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid max_age"})
+			return
+		}
+		opts.MaxAge = age
+	}
+
+	scope, ok := stationScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	encounters, err := h.riskService.Encounters(opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to compute encounters"})
+		return
+	}
+	out := []models.Encounter{}
+	for _, e := range encounters {
+		if inScope(scope, e.StationID) {
+			out = append(out, e)
+		}
+	}
+
+	c.JSON(http.StatusOK, out)
+}
@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
@@ -11,13 +13,17 @@ import (
 
 type ScheduleHandler struct {
 	scheduleService *services.ScheduleService
+	changeService   *services.ScheduleChangeService
 	stationService  *services.StationService
+	groupService    *services.GroupService
 }
 
-func NewScheduleHandler(scheduleService *services.ScheduleService, stationService *services.StationService) *ScheduleHandler {
+func NewScheduleHandler(scheduleService *services.ScheduleService, changeService *services.ScheduleChangeService, stationService *services.StationService, groupService *services.GroupService) *ScheduleHandler {
 	return &ScheduleHandler{
 		scheduleService: scheduleService,
+		changeService:   changeService,
 		stationService:  stationService,
+		groupService:    groupService,
 	}
 }
 
@@ -28,6 +34,12 @@ type CreateScheduleRequest struct {
 	Commander string `json:"commander"`
 	Crew      string `json:"crew"`
 	Phone     string `json:"phone"`
+
+	CommanderID *uint  `json:"commander_id,omitempty" example:"3"` // fills commander and phone from the roster
+	CrewIDs     []uint `json:"crew_ids,omitempty"`                 // fills crew from the roster
+
+	Recurrence  *models.Recurrence `json:"recurrence,omitempty"`
+	ExceptDates []string           `json:"except_dates,omitempty" example:"2025-09-02"`
 }
 
 // UpdateScheduleRequest represents the request for updating a schedule
@@ -37,22 +49,62 @@ type UpdateScheduleRequest struct {
 	Commander string `json:"commander"`
 	Crew      string `json:"crew"`
 	Phone     string `json:"phone"`
+
+	CommanderID *uint  `json:"commander_id,omitempty"` // 0 removes the assigned commander, with the name and phone filled from the roster
+	CrewIDs     []uint `json:"crew_ids,omitempty"`     // [] removes the assigned crew, with the names filled from the roster
+
+	Recurrence  *models.Recurrence `json:"recurrence,omitempty"`
+	ExceptDates []string           `json:"except_dates,omitempty"` // [] clears the exception dates
+}
+
+// ScheduleConflictResponse lists the schedules a window overlaps
+type ScheduleConflictResponse struct {
+	Error     string            `json:"error"`
+	Conflicts []models.Schedule `json:"conflicts"`
+}
+
+// DutyConflictResponse lists the people an assignment would double-book
+type DutyConflictResponse struct {
+	Error     string                  `json:"error"`
+	Conflicts []services.DutyConflict `json:"conflicts"`
+}
+
+// writeScheduleError maps validation errors to 400/409 responses and reports
+// whether err was one.
+func writeScheduleError(c *gin.Context, err error) bool {
+	var conflict *services.ScheduleConflictError
+	var duty *services.DutyConflictError
+	switch {
+	case errors.As(err, &conflict):
+		c.JSON(http.StatusConflict, ScheduleConflictResponse{Error: "Schedule overlaps existing schedules", Conflicts: conflict.Conflicts})
+	case errors.As(err, &duty):
+		c.JSON(http.StatusConflict, DutyConflictResponse{Error: "Personnel already on duty at that time", Conflicts: duty.Conflicts})
+	case errors.Is(err, services.ErrInvalidSchedule):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	default:
+		return false
+	}
+	return true
 }
 
 // CreateSchedule creates a new schedule
 // @Summary Create a new schedule
-// @Description Create a new schedule for a station (Operator only)
+// @Description Create a new schedule for a station (Operator only). Windows run from start up to (not including) end and may wrap past midnight; start and end must differ and the window must not overlap another schedule of the station on the same day. Touching windows (one ends when the next starts) are allowed. Commander and crew can be assigned from the personnel roster (commander_id, crew_ids); a person already on another watch at the same time in the next 60 days is rejected with 409. Without a recurrence the window repeats every day; a recurrence limits it by weekday (by_day), every N days or weeks (interval, from start_date), and a last date (until) or number of occurrences (count). Dates in except_dates are skipped. When HQ approval is switched on (see /schedule-changes/settings) the change is queued as a proposal and 202 is returned; with emergency=true and a reason it is applied at once and logged.
 // @Tags schedules
 // @Accept json
 // @Produce json
 // @Param station_id path int true "Station ID"
 // @Param schedule body CreateScheduleRequest true "Schedule data"
+// @Param emergency query bool false "Apply at once without approval (logged; needs reason)"
+// @Param reason query string false "Reason for the change"
 // @Security BearerAuth
 // @Success 201 {object} models.Schedule
+// @Success 202 {object} models.ScheduleChange
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ScheduleConflictResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /station-schedules/station/{station_id} [post]
 func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
@@ -89,9 +141,21 @@ func (h *ScheduleHandler) CreateSchedule(c *gin.Context) {
 		Commander: req.Commander,
 		Crew:      req.Crew,
 		Phone:     req.Phone,
+
+		CommanderID: req.CommanderID,
+		CrewIDs:     req.CrewIDs,
+		Recurrence:  req.Recurrence,
+		ExceptDates: req.ExceptDates,
+	}
+	if h.changeService.RequireApproval() {
+		h.submitChange(c, &models.ScheduleChange{StationID: uint(stationID), Action: models.ScheduleChangeCreate, Schedule: schedule})
+		return
 	}
 
 	if err := h.scheduleService.Create(schedule); err != nil {
+		if writeScheduleError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create schedule"})
 		return
 	}
@@ -126,6 +190,9 @@ func (h *ScheduleHandler) ListSchedules(c *gin.Context) {
 		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
 		return
 	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
 
 	schedules, err := h.scheduleService.ListByStation(uint(stationID))
 	if err != nil {
@@ -166,6 +233,10 @@ func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
 		return
 	}
 
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+
 	schedule, err := h.scheduleService.GetByID(uint(stationID), uint(scheduleID))
 	if err != nil {
 		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Schedule not found"})
@@ -175,21 +246,60 @@ func (h *ScheduleHandler) GetSchedule(c *gin.Context) {
 	c.JSON(http.StatusOK, schedule)
 }
 
+// ListAllSchedules lists the schedules of all stations
+// @Summary List schedules of all stations
+// @Description Get the schedules of every station, or of the stations in a group. Users assigned to a group only see its stations.
+// @Tags schedules
+// @Produce json
+// @Param group_id query int false "Only stations in this group or the groups below it"
+// @Security BearerAuth
+// @Success 200 {array} models.Schedule
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /station-schedules [get]
+func (h *ScheduleHandler) ListAllSchedules(c *gin.Context) {
+	scope, ok := stationScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	schedules, err := h.scheduleService.ListAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve schedules"})
+		return
+	}
+
+	out := []models.Schedule{}
+	for _, sc := range schedules {
+		if inScope(scope, sc.StationID) {
+			out = append(out, sc)
+		}
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
 // UpdateSchedule updates an existing schedule
 // @Summary Update a schedule
-// @Description Update an existing schedule by station ID and schedule ID (Operator only)
+// @Description Update an existing schedule by station ID and schedule ID (Operator only). When HQ approval is switched on (see /schedule-changes/settings) the change is queued as a proposal and 202 is returned; with emergency=true and a reason it is applied at once and logged.
 // @Tags schedules
 // @Accept json
 // @Produce json
 // @Param station_id path int true "Station ID"
 // @Param schedule_id path int true "Schedule ID"
 // @Param schedule body UpdateScheduleRequest true "Updated schedule data"
+// @Param emergency query bool false "Apply at once without approval (logged; needs reason)"
+// @Param reason query string false "Reason for the change"
 // @Security BearerAuth
 // @Success 200 {object} models.Schedule
+// @Success 202 {object} models.ScheduleChange
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ScheduleConflictResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /station-schedules/station/{station_id}/{schedule_id} [put]
 func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
@@ -241,28 +351,51 @@ func (h *ScheduleHandler) UpdateSchedule(c *gin.Context) {
 	if req.Phone != "" {
 		updates["phone"] = req.Phone
 	}
+	if req.CommanderID != nil {
+		updates["commander_id"] = req.CommanderID
+	}
+	if req.CrewIDs != nil {
+		updates["crew_ids"] = req.CrewIDs
+	}
+	if req.Recurrence != nil {
+		updates["recurrence"] = req.Recurrence
+	}
+	if req.ExceptDates != nil {
+		updates["except_dates"] = req.ExceptDates
+	}
 
-	schedule, err := h.scheduleService.UpdatePartial(uint(stationID), uint(scheduleID), updates)
+	update := h.scheduleService.UpdatePartial
+	if h.changeService.RequireApproval() {
+		update = h.scheduleService.PreviewUpdate
+	}
+	schedule, err := update(uint(stationID), uint(scheduleID), updates)
 	if err != nil {
 		if err.Error() == "schedule not found" {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Schedule not found"})
-		} else {
+		} else if !writeScheduleError(c, err) {
 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update schedule"})
 		}
 		return
 	}
+	if h.changeService.RequireApproval() {
+		h.submitChange(c, &models.ScheduleChange{StationID: uint(stationID), Action: models.ScheduleChangeUpdate, ScheduleID: uint(scheduleID), Schedule: schedule})
+		return
+	}
 
 	c.JSON(http.StatusOK, schedule)
 }
 
 // DeleteSchedule deletes a schedule
 // @Summary Delete a schedule
-// @Description Delete a schedule by station ID and schedule ID (Operator only)
+// @Description Delete a schedule by station ID and schedule ID (Operator only). When HQ approval is switched on (see /schedule-changes/settings) the change is queued as a proposal and 202 is returned; with emergency=true and a reason it is applied at once and logged.
 // @Tags schedules
 // @Param station_id path int true "Station ID"
 // @Param schedule_id path int true "Schedule ID"
+// @Param emergency query bool false "Apply at once without approval (logged; needs reason)"
+// @Param reason query string false "Reason for the change"
 // @Security BearerAuth
 // @Success 204
+// @Success 202 {object} models.ScheduleChange
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
@@ -291,6 +424,10 @@ func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
 		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Schedule not found"})
 		return
 	}
+	if h.changeService.RequireApproval() {
+		h.submitChange(c, &models.ScheduleChange{StationID: uint(stationID), Action: models.ScheduleChangeDelete, ScheduleID: uint(scheduleID)})
+		return
+	}
 
 	if err := h.scheduleService.Delete(uint(stationID), uint(scheduleID)); err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete schedule"})
@@ -300,6 +437,230 @@ func (h *ScheduleHandler) DeleteSchedule(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// ScheduleOverrideRequest represents a one-off change to a station's schedule
+type ScheduleOverrideRequest struct {
+	Date      string `json:"date" binding:"required" example:"2025-09-02"`
+	Kind      string `json:"kind" binding:"required" example:"CANCEL"`
+	StartHHMM string `json:"start_hhmm" binding:"required" example:"0000"`
+	EndHHMM   string `json:"end_hhmm" binding:"required" example:"0000"`
+	Reason    string `json:"reason,omitempty" example:"National Day"`
+	Commander string `json:"commander,omitempty"`
+	Crew      string `json:"crew,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+
+	CommanderID *uint  `json:"commander_id,omitempty"`
+	CrewIDs     []uint `json:"crew_ids,omitempty"`
+}
+
+// ScheduleActiveResponse tells whether a station is on schedule at a time
+type ScheduleActiveResponse struct {
+	StationID uint  `json:"station_id"`
+	At        int64 `json:"at"`
+	Active    bool  `json:"active"`
+}
+
+// ListScheduleOverrides lists the one-off overrides of a station
+// @Summary List one-off schedule overrides
+// @Description Get the extra watches and cancelled windows of a station, ordered by date
+// @Tags schedules
+// @Produce json
+// @Param station_id path int true "Station ID"
+// @Security BearerAuth
+// @Success 200 {array} models.ScheduleOverride
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /station-schedules/station/{station_id}/overrides [get]
+func (h *ScheduleHandler) ListScheduleOverrides(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("station_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+
+	overrides, err := h.scheduleService.ListOverrides(uint(stationID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve overrides"})
+		return
+	}
+
+	c.JSON(http.StatusOK, overrides)
+}
+
+// CreateScheduleOverride adds a one-off override
+// @Summary Add a one-off schedule override
+// @Description Add an extra watch (EXTRA) or take the station off schedule (CANCEL) for one window on one date (Operator only). Overrides take precedence over the schedules; CANCEL wins over EXTRA. Equal start and end times (e.g. 0000-0000) cover a whole day. When HQ approval is switched on (see /schedule-changes/settings) the change is queued as a proposal and 202 is returned; with emergency=true and a reason it is applied at once and logged.
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param station_id path int true "Station ID"
+// @Param override body ScheduleOverrideRequest true "Override data"
+// @Param emergency query bool false "Apply at once without approval (logged; needs reason)"
+// @Param reason query string false "Reason for the change"
+// @Security BearerAuth
+// @Success 201 {object} models.ScheduleOverride
+// @Success 202 {object} models.ScheduleChange
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /station-schedules/station/{station_id}/overrides [post]
+func (h *ScheduleHandler) CreateScheduleOverride(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("station_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+
+	var req ScheduleOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	if _, err := h.stationService.GetByID(uint(stationID)); err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
+		return
+	}
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if !ownsStation(user, uint(stationID)) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Operators can only change schedules of their own station"})
+		return
+	}
+
+	override := &models.ScheduleOverride{
+		StationID: uint(stationID),
+		Date:      req.Date,
+		Kind:      req.Kind,
+		StartHHMM: req.StartHHMM,
+		EndHHMM:   req.EndHHMM,
+		Reason:    req.Reason,
+		Commander: req.Commander,
+		Crew:      req.Crew,
+		Phone:     req.Phone,
+		CreatedBy: user.Username,
+
+		CommanderID: req.CommanderID,
+		CrewIDs:     req.CrewIDs,
+	}
+	if h.changeService.RequireApproval() {
+		h.submitChange(c, &models.ScheduleChange{StationID: uint(stationID), Action: models.ScheduleChangeCreateOverride, Override: override})
+		return
+	}
+
+	if err := h.scheduleService.CreateOverride(override); err != nil {
+		if !writeScheduleError(c, err) {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create override"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, override)
+}
+
+// DeleteScheduleOverride removes a one-off override
+// @Summary Delete a one-off schedule override
+// @Description Delete a one-off override (Operator only). When HQ approval is switched on (see /schedule-changes/settings) the change is queued as a proposal and 202 is returned; with emergency=true and a reason it is applied at once and logged.
+// @Tags schedules
+// @Param station_id path int true "Station ID"
+// @Param override_id path int true "Override ID"
+// @Param emergency query bool false "Apply at once without approval (logged; needs reason)"
+// @Param reason query string false "Reason for the change"
+// @Security BearerAuth
+// @Success 204
+// @Success 202 {object} models.ScheduleChange
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /station-schedules/station/{station_id}/overrides/{override_id} [delete]
+func (h *ScheduleHandler) DeleteScheduleOverride(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("station_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	overrideID, err := strconv.ParseUint(c.Param("override_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid override ID"})
+		return
+	}
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if !ownsStation(user, uint(stationID)) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Operators can only change schedules of their own station"})
+		return
+	}
+	if h.changeService.RequireApproval() {
+		h.submitChange(c, &models.ScheduleChange{StationID: uint(stationID), Action: models.ScheduleChangeDeleteOverride, OverrideID: uint(overrideID)})
+		return
+	}
+
+	if err := h.scheduleService.DeleteOverride(uint(stationID), uint(overrideID)); err != nil {
+		if err.Error() == "override not found" {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Override not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete override"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetScheduleActive tells whether a station is on schedule at a time
+// @Summary Check whether a station is on schedule
+// @Description Evaluate the schedules, recurrence rules and one-off overrides of a station at a time (default now)
+// @Tags schedules
+// @Produce json
+// @Param station_id path int true "Station ID"
+// @Param at query int false "Unix timestamp (default now)"
+// @Security BearerAuth
+// @Success 200 {object} ScheduleActiveResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /station-schedules/station/{station_id}/active [get]
+func (h *ScheduleHandler) GetScheduleActive(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("station_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	at := time.Now()
+	if raw := c.Query("at"); raw != "" {
+		ts, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid at timestamp"})
+			return
+		}
+		at = time.Unix(ts, 0)
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+
+	active, err := h.scheduleService.ActiveAt(uint(stationID), at)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to evaluate schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ScheduleActiveResponse{StationID: uint(stationID), At: at.Unix(), Active: active})
+}
+
 // isValidHHMM validates HHMM format (e.g., "0130", "1445")
 func isValidHHMM(timeStr string) bool {
 	if len(timeStr) != 4 {
@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// ReviewScheduleChangeRequest represents HQ's decision on a proposed change
+type ReviewScheduleChangeRequest struct {
+	Comment string `json:"comment,omitempty" example:"Agreed with the regional command"`
+}
+
+// ScheduleApprovalSetting tells whether schedule changes need HQ approval
+type ScheduleApprovalSetting struct {
+	RequireApproval bool `json:"require_approval" example:"true"`
+}
+
+func writeScheduleChangeError(c *gin.Context, err error, fallback string) {
+	if writeScheduleError(c, err) {
+		return
+	}
+	switch err.Error() {
+	case "change not found":
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Change not found"})
+	case "station not found":
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
+	case "schedule not found":
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Schedule not found"})
+	case "override not found":
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Override not found"})
+	case "change is not pending":
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Change has already been reviewed"})
+	case "schedule changed since the proposal":
+		c.JSON(http.StatusConflict, ErrorResponse{Error: "Schedule changed since the proposal"})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
+
+// submitChange queues a schedule write for HQ approval (202), or applies it
+// at once when the request is flagged as an emergency (200).
+func (h *ScheduleHandler) submitChange(c *gin.Context, change *models.ScheduleChange) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if !ownsStation(user, change.StationID) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Operators can only change schedules of their own station"})
+		return
+	}
+	change.RequestedBy = user.Username
+	change.Emergency, _ = strconv.ParseBool(c.Query("emergency"))
+	change.Reason = c.Query("reason")
+
+	if err := h.changeService.Submit(change); err != nil {
+		writeScheduleChangeError(c, err, "Failed to submit schedule change")
+		return
+	}
+	status := http.StatusAccepted
+	if change.Emergency {
+		status = http.StatusOK
+	}
+	c.JSON(status, change)
+}
+
+// ListScheduleChanges godoc
+// @Summary List schedule change proposals
+// @Description Proposed schedule changes of the stations the user can see (or of a group or station), newest first; pending ones oldest first.
+// @Tags schedules
+// @Produce json
+// @Param status query string false "PENDING, APPROVED or REJECTED"
+// @Param station_id query int false "Only this station"
+// @Param group_id query int false "Only stations in this group or the groups below it"
+// @Security BearerAuth
+// @Success 200 {array} models.ScheduleChange
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /schedule-changes [get]
+func (h *ScheduleHandler) ListScheduleChanges(c *gin.Context) {
+	status := strings.ToUpper(c.Query("status"))
+	switch status {
+	case "", models.ScheduleChangePending, models.ScheduleChangeApproved, models.ScheduleChangeRejected:
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "status must be PENDING, APPROVED or REJECTED"})
+		return
+	}
+	h.listScheduleChanges(c, status)
+}
+
+// ListPendingScheduleChanges godoc
+// @Summary Pending schedule changes awaiting HQ approval
+// @Description The queue of proposed schedule changes not yet approved or rejected, oldest first.
+// @Tags schedules
+// @Produce json
+// @Param station_id query int false "Only this station"
+// @Param group_id query int false "Only stations in this group or the groups below it"
+// @Security BearerAuth
+// @Success 200 {array} models.ScheduleChange
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /schedule-changes/pending [get]
+func (h *ScheduleHandler) ListPendingScheduleChanges(c *gin.Context) {
+	h.listScheduleChanges(c, models.ScheduleChangePending)
+}
+
+func (h *ScheduleHandler) listScheduleChanges(c *gin.Context, status string) {
+	var stationID uint64
+	if raw := c.Query("station_id"); raw != "" {
+		var err error
+		if stationID, err = strconv.ParseUint(raw, 10, 32); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+			return
+		}
+	}
+	scope, ok := stationScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	changes, err := h.changeService.List(uint(stationID), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list schedule changes"})
+		return
+	}
+	out := []models.ScheduleChange{}
+	for _, ch := range changes {
+		if inScope(scope, ch.StationID) {
+			out = append(out, ch)
+		}
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// GetScheduleChange godoc
+// @Summary Get a schedule change proposal
+// @Tags schedules
+// @Produce json
+// @Param id path int true "Change ID"
+// @Security BearerAuth
+// @Success 200 {object} models.ScheduleChange
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /schedule-changes/{id} [get]
+func (h *ScheduleHandler) GetScheduleChange(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid change ID"})
+		return
+	}
+
+	change, err := h.changeService.GetByID(uint(id))
+	if err != nil {
+		writeScheduleChangeError(c, err, "Failed to get schedule change")
+		return
+	}
+	if !canSeeStation(c, h.groupService, change.StationID) {
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// ApproveScheduleChange godoc
+// @Summary Approve a schedule change
+// @Description Apply a pending schedule change of a station in the user's group (HQ only). If the schedule was changed since the proposal, or the change now overlaps other schedules, it is refused with 409 and stays pending.
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param id path int true "Change ID"
+// @Param review body ReviewScheduleChangeRequest false "Comment"
+// @Security BearerAuth
+// @Success 200 {object} models.ScheduleChange
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /schedule-changes/{id}/approve [post]
+func (h *ScheduleHandler) ApproveScheduleChange(c *gin.Context) {
+	h.reviewScheduleChange(c, true)
+}
+
+// RejectScheduleChange godoc
+// @Summary Reject a schedule change
+// @Description Turn down a pending schedule change of a station in the user's group with a comment (HQ only).
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param id path int true "Change ID"
+// @Param review body ReviewScheduleChangeRequest true "Comment"
+// @Security BearerAuth
+// @Success 200 {object} models.ScheduleChange
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /schedule-changes/{id}/reject [post]
+func (h *ScheduleHandler) RejectScheduleChange(c *gin.Context) {
+	h.reviewScheduleChange(c, false)
+}
+
+func (h *ScheduleHandler) reviewScheduleChange(c *gin.Context, approve bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid change ID"})
+		return
+	}
+	var req ReviewScheduleChangeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+			return
+		}
+	}
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	change, err := h.changeService.GetByID(uint(id))
+	if err != nil {
+		writeScheduleChangeError(c, err, "Failed to review schedule change")
+		return
+	}
+	if !canSeeStation(c, h.groupService, change.StationID) {
+		return
+	}
+
+	review := h.changeService.Reject
+	if approve {
+		review = h.changeService.Approve
+	}
+	change, err = review(change.ID, user.Username, req.Comment)
+	if err != nil {
+		writeScheduleChangeError(c, err, "Failed to review schedule change")
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// GetScheduleApprovalSetting godoc
+// @Summary Whether schedule changes need HQ approval
+// @Tags schedules
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ScheduleApprovalSetting
+// @Failure 401 {object} ErrorResponse
+// @Router /schedule-changes/settings [get]
+func (h *ScheduleHandler) GetScheduleApprovalSetting(c *gin.Context) {
+	c.JSON(http.StatusOK, ScheduleApprovalSetting{RequireApproval: h.changeService.RequireApproval()})
+}
+
+// UpdateScheduleApprovalSetting godoc
+// @Summary Switch the schedule approval flow on or off
+// @Description When on, creating, updating and deleting schedules and one-off overrides through the station-schedules endpoints queues a proposal for HQ approval instead of applying it (Admin only). Pending proposals stay in the queue when it is switched off.
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param setting body ScheduleApprovalSetting true "Setting"
+// @Security BearerAuth
+// @Success 200 {object} ScheduleApprovalSetting
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /schedule-changes/settings [put]
+func (h *ScheduleHandler) UpdateScheduleApprovalSetting(c *gin.Context) {
+	var req ScheduleApprovalSetting
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	if err := h.changeService.SetRequireApproval(req.RequireApproval); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save setting"})
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
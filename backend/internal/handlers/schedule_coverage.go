@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/services"
+)
+
+// coverageMaxPeriod bounds the hourly breakdown of a coverage report.
+const coverageMaxPeriod = 31 * 24 * time.Hour
+
+// GetScheduleCoverage godoc
+// @Summary Coverage gaps across station schedules
+// @Description Merges the scheduled watches of every station the user can see (or of a group) over a period and reports the spans when no station is on schedule, the spans covered by a single station (single points of failure) and the number of stations on schedule in each hour of the time zone tz. Recurrence rules, exception dates and one-off overrides are applied. The period defaults to the next 7 days and may be at most 31 days.
+// @Tags schedules
+// @Produce json
+// @Produce text/csv
+// @Param from query int false "Unix seconds, default now"
+// @Param to query int false "Unix seconds, default from + 7 days"
+// @Param group_id query int false "Only stations in this group or the groups below it"
+// @Param tz query string false "IANA time zone of the hourly rows and CSV times, e.g. Asia/Kolkata (default UTC)"
+// @Param format query string false "json (default) or csv"
+// @Security BearerAuth
+// @Success 200 {object} services.ScheduleCoverage
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /station-schedules/coverage [get]
+func (h *ScheduleHandler) GetScheduleCoverage(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "format must be csv or json"})
+		return
+	}
+	from, to, ok := parseUpcoming(c)
+	if !ok {
+		return
+	}
+	if to-from > int64(coverageMaxPeriod/time.Second) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("period must be at most %d days", coverageMaxPeriod/(24*time.Hour))})
+		return
+	}
+	scope, ok := stationScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	stations, err := h.stationService.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list stations"})
+		return
+	}
+	ids := []uint{}
+	for _, st := range stations {
+		if inScope(scope, st.ID) {
+			ids = append(ids, st.ID)
+		}
+	}
+
+	cov, err := h.scheduleService.ScheduleCoverage(ids, from, to, c.Query("tz"))
+	if errors.Is(err, services.ErrInvalidTimeZone) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to analyse coverage"})
+		return
+	}
+	if format == "json" {
+		c.JSON(http.StatusOK, cov)
+		return
+	}
+
+	filename := "schedule-coverage-" + time.Now().Format("20060102") + ".csv"
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+	if err := services.WriteScheduleCoverageCSV(c.Writer, cov); err != nil {
+		c.Error(err)
+	}
+}
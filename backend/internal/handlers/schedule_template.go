@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/services"
+)
+
+// ScheduleTemplateRequest represents the create/update template request payload
+type ScheduleTemplateRequest struct {
+	Name        string                        `json:"name" binding:"required" example:"4 x 6h watches"`
+	Description string                        `json:"description,omitempty"`
+	Slots       []models.ScheduleTemplateSlot `json:"slots"`
+}
+
+// TemplateFromStationRequest names a template copied from a station's schedules
+type TemplateFromStationRequest struct {
+	Name        string `json:"name" binding:"required" example:"Station 1 pattern"`
+	Description string `json:"description,omitempty"`
+}
+
+// ApplyTemplateRequest represents a template applied to stations
+type ApplyTemplateRequest struct {
+	StationIDs []uint `json:"station_ids" binding:"required" example:"1"`
+	Mode       string `json:"mode,omitempty" example:"skip"` // fail (default), skip or replace
+	DryRun     bool   `json:"dry_run,omitempty"`
+}
+
+// TemplateConflictResponse lists the existing schedules a template overlaps
+type TemplateConflictResponse struct {
+	Error  string                        `json:"error"`
+	Result *services.TemplateApplyResult `json:"result"`
+}
+
+func writeTemplateError(c *gin.Context, err error, fallback string) {
+	var conflict *services.TemplateConflictError
+	switch {
+	case errors.As(err, &conflict):
+		c.JSON(http.StatusConflict, TemplateConflictResponse{Error: "Template overlaps existing schedules", Result: conflict.Result})
+	case errors.Is(err, services.ErrInvalidSchedule):
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case err.Error() == "template not found":
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Template not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: fallback})
+	}
+}
+
+func parseTemplateID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid template ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// ListScheduleTemplates godoc
+// @Summary List schedule templates
+// @Tags schedules
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.ScheduleTemplate
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /schedule-templates [get]
+func (h *ScheduleHandler) ListScheduleTemplates(c *gin.Context) {
+	templates, err := h.scheduleService.ListTemplates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetScheduleTemplate godoc
+// @Summary Get a schedule template
+// @Tags schedules
+// @Produce json
+// @Param id path int true "Template ID"
+// @Security BearerAuth
+// @Success 200 {object} models.ScheduleTemplate
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /schedule-templates/{id} [get]
+func (h *ScheduleHandler) GetScheduleTemplate(c *gin.Context) {
+	id, ok := parseTemplateID(c)
+	if !ok {
+		return
+	}
+
+	template, err := h.scheduleService.GetTemplate(id)
+	if err != nil {
+		writeTemplateError(c, err, "Failed to get template")
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// CreateScheduleTemplate godoc
+// @Summary Create a schedule template
+// @Description Save a named set of schedule windows (Operator only), for example four 6-hour watches. Windows use the same fields as schedules (start_hhmm, end_hhmm, recurrence, except_dates) and must not overlap each other. Names are unique regardless of case.
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param template body ScheduleTemplateRequest true "Template data"
+// @Security BearerAuth
+// @Success 201 {object} models.ScheduleTemplate
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /schedule-templates [post]
+func (h *ScheduleHandler) CreateScheduleTemplate(c *gin.Context) {
+	var req ScheduleTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	template := &models.ScheduleTemplate{Name: req.Name, Description: req.Description, Slots: req.Slots, CreatedBy: user.Username}
+	if err := h.scheduleService.CreateTemplate(template); err != nil {
+		writeTemplateError(c, err, "Failed to create template")
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// CreateTemplateFromStation godoc
+// @Summary Copy a station's schedules into a template
+// @Description Save the windows and recurrence rules of a station's schedules as a new template (Operator only), to apply them to other stations. Assigned personnel are not copied.
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param station_id path int true "Station ID"
+// @Param template body TemplateFromStationRequest true "Template name"
+// @Security BearerAuth
+// @Success 201 {object} models.ScheduleTemplate
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /schedule-templates/from-station/{station_id} [post]
+func (h *ScheduleHandler) CreateTemplateFromStation(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("station_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+
+	var req TemplateFromStationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	if _, err := h.stationService.GetByID(uint(stationID)); err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	template := &models.ScheduleTemplate{Name: req.Name, Description: req.Description, CreatedBy: user.Username}
+	if err := h.scheduleService.TemplateFromStation(uint(stationID), template); err != nil {
+		writeTemplateError(c, err, "Failed to create template")
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// UpdateScheduleTemplate godoc
+// @Summary Update a schedule template
+// @Description Replace the name, description and windows of a template (Operator only). Schedules already created from it are not changed.
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param template body ScheduleTemplateRequest true "Template data"
+// @Security BearerAuth
+// @Success 200 {object} models.ScheduleTemplate
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /schedule-templates/{id} [put]
+func (h *ScheduleHandler) UpdateScheduleTemplate(c *gin.Context) {
+	id, ok := parseTemplateID(c)
+	if !ok {
+		return
+	}
+
+	var req ScheduleTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	template := &models.ScheduleTemplate{ID: id, Name: req.Name, Description: req.Description, Slots: req.Slots}
+	if err := h.scheduleService.UpdateTemplate(template); err != nil {
+		writeTemplateError(c, err, "Failed to update template")
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteScheduleTemplate godoc
+// @Summary Delete a schedule template
+// @Description Delete a template (Operator only). Schedules created from it stay.
+// @Tags schedules
+// @Param id path int true "Template ID"
+// @Security BearerAuth
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /schedule-templates/{id} [delete]
+func (h *ScheduleHandler) DeleteScheduleTemplate(c *gin.Context) {
+	id, ok := parseTemplateID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.scheduleService.DeleteTemplate(id); err != nil {
+		writeTemplateError(c, err, "Failed to delete template")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ApplyScheduleTemplate godoc
+// @Summary Apply a schedule template to stations
+// @Description Create the windows of a template as schedules on one or more stations in a single transaction (Operator only). A window that overlaps an existing schedule is handled by mode: fail (default) applies nothing and returns 409 with the conflicts, skip keeps the existing schedule and drops the window, replace deletes the existing schedule. With dry_run the result is returned without saving anything. While HQ approval of schedule changes is switched on only dry runs are allowed (403).
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param apply body ApplyTemplateRequest true "Target stations and conflict mode"
+// @Security BearerAuth
+// @Success 200 {object} services.TemplateApplyResult
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} TemplateConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /schedule-templates/{id}/apply [post]
+func (h *ScheduleHandler) ApplyScheduleTemplate(c *gin.Context) {
+	id, ok := parseTemplateID(c)
+	if !ok {
+		return
+	}
+
+	var req ApplyTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = services.TemplateConflictFail
+	}
+	if !req.DryRun && h.changeService.RequireApproval() {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "Schedule changes need HQ approval; templates can only be applied as a dry run"})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	for _, stID := range req.StationIDs {
+		if !ownsStation(user, stID) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Error: "Operators can only apply templates to their own station"})
+			return
+		}
+	}
+
+	result, err := h.scheduleService.ApplyTemplate(id, req.StationIDs, req.Mode, req.DryRun)
+	if err != nil {
+		writeTemplateError(c, err, "Failed to apply template")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
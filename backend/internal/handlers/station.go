@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
@@ -11,10 +14,11 @@ import (
 
 type StationHandler struct {
 	stationService *services.StationService
+	groupService   *services.GroupService
 }
 
-func NewStationHandler(stationService *services.StationService) *StationHandler {
-	return &StationHandler{stationService: stationService}
+func NewStationHandler(stationService *services.StationService, groupService *services.GroupService) *StationHandler {
+	return &StationHandler{stationService: stationService, groupService: groupService}
 }
 
 // CreateStationRequest represents the create station request payload
@@ -26,7 +30,17 @@ type CreateStationRequest struct {
 	Elevation       float64 `json:"elevation" example:"10.5"`
 	DistanceToCoast float64 `json:"distance_to_coast" example:"15.2"`
 	Status          string  `json:"status" example:"ACTIVE"`
-	Note            string  `json:"note,omitempty" example:"Main radar station"`
+	Note            string  `json:"note,omitempty" example:"Main radar station"` // first pinned note
+	GroupID         *uint   `json:"group_id,omitempty" example:"2"`
+	TimeZone        string  `json:"time_zone,omitempty" example:"Asia/Ho_Chi_Minh"` // IANA zone of the schedules; default UTC+7
+
+	Radar *models.RadarParams `json:"radar,omitempty"`
+}
+
+// StationDetail is a station with its latest notes
+type StationDetail struct {
+	models.Station
+	RecentNotes []models.StationNote `json:"recent_notes"`
 }
 
 // UpdateStationRequest represents the update station request payload
@@ -36,8 +50,12 @@ type UpdateStationRequest struct {
 	Longitude       *float64 `json:"longitude,omitempty" example:"105.8550"`
 	Elevation       *float64 `json:"elevation,omitempty" example:"12.0"`
 	DistanceToCoast *float64 `json:"distance_to_coast,omitempty" example:"16.0"`
-	Status          *string  `json:"status,omitempty" example:"INACTIVE"`
-	Note            *string  `json:"note,omitempty" example:"Updated radar station"`
+	Status          *string  `json:"status,omitempty" example:"INACTIVE"`            // rejected (400); see PUT /stations/{id}/override
+	Note            *string  `json:"note,omitempty" example:"Updated radar station"` // appended as a pinned note; "" unpins all
+	GroupID         *uint    `json:"group_id,omitempty" example:"2"`                 // 0 removes the station from its group
+	TimeZone        *string  `json:"time_zone,omitempty" example:"Asia/Bangkok"`     // "" resets to UTC+7
+
+	Radar *models.RadarParams `json:"radar,omitempty"`
 }
 
 // CreateStation godoc
@@ -61,6 +79,10 @@ func (h *StationHandler) CreateStation(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
 		return
 	}
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
 
 	// Create station model
 	station := &models.Station{
@@ -70,7 +92,9 @@ func (h *StationHandler) CreateStation(c *gin.Context) {
 		Elevation:       req.Elevation,
 		DistanceToCoast: req.DistanceToCoast,
 		Status:          req.Status,
-		Note:            req.Note,
+		GroupID:         req.GroupID,
+		TimeZone:        req.TimeZone,
+		Radar:           req.Radar,
 	}
 
 	// Set ID if provided
@@ -90,16 +114,31 @@ func (h *StationHandler) CreateStation(c *gin.Context) {
 			c.JSON(http.StatusConflict, ErrorResponse{Error: "Station with this ID already exists"})
 			return
 		}
+		if errors.Is(err, services.ErrInvalidRadar) || errors.Is(err, services.ErrInvalidTimeZone) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if err.Error() == "group not found" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Group not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create station"})
 		return
 	}
+	if strings.TrimSpace(req.Note) != "" {
+		if err := h.stationService.UpdateNote(station.ID, user.Username, req.Note); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save station note"})
+			return
+		}
+		station.Note = strings.TrimSpace(req.Note)
+	}
 
 	c.JSON(http.StatusCreated, station)
 }
 
 // UpdateStation godoc
 // @Summary Update an existing station (Admin only)
-// @Description Update station information. Only users with ADMIN role can perform this action.
+// @Description Update station information. Only users with ADMIN role can perform this action. The status cannot be set here (400); it follows the schedule and heartbeats, use PUT /stations/{id}/override to set it by hand.
 // @Tags stations
 // @Accept json
 // @Produce json
@@ -121,11 +160,23 @@ func (h *StationHandler) UpdateStation(c *gin.Context) {
 		return
 	}
 
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
 	var req UpdateStationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
 		return
 	}
+	if req.Status != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "status is derived from the schedule and heartbeats; use PUT /stations/{id}/override to set it by hand"})
+		return
+	}
 
 	// Update station using service
 	updateMap := make(map[string]interface{})
@@ -144,11 +195,14 @@ func (h *StationHandler) UpdateStation(c *gin.Context) {
 	if req.DistanceToCoast != nil {
 		updateMap["distance_to_coast"] = *req.DistanceToCoast
 	}
-	if req.Status != nil {
-		updateMap["status"] = *req.Status
+	if req.Radar != nil {
+		updateMap["radar"] = req.Radar
 	}
-	if req.Note != nil {
-		updateMap["note"] = *req.Note
+	if req.GroupID != nil {
+		updateMap["group_id"] = req.GroupID
+	}
+	if req.TimeZone != nil {
+		updateMap["time_zone"] = *req.TimeZone
 	}
 
 	station, err := h.stationService.UpdatePartial(uint(stationID), updateMap)
@@ -157,16 +211,34 @@ func (h *StationHandler) UpdateStation(c *gin.Context) {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
 			return
 		}
+		if errors.Is(err, services.ErrInvalidRadar) || errors.Is(err, services.ErrInvalidTimeZone) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if err.Error() == "group not found" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Group not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update station"})
 		return
 	}
+	if req.Note != nil {
+		if err := h.stationService.UpdateNote(station.ID, user.Username, *req.Note); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to save station note"})
+			return
+		}
+		if station, err = h.stationService.GetByID(station.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update station"})
+			return
+		}
+	}
 
 	c.JSON(http.StatusOK, station)
 }
 
 // DeleteStation godoc
 // @Summary Delete a station (Admin only)
-// @Description Delete a radar station. Only users with ADMIN role can perform this action.
+// @Description Delete a radar station. Only users with ADMIN role can perform this action. A station that still has equipment cannot be deleted.
 // @Tags stations
 // @Accept json
 // @Produce json
@@ -177,6 +249,7 @@ func (h *StationHandler) UpdateStation(c *gin.Context) {
 // @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
 // @Failure 404 {object} ErrorResponse "Station not found"
+// @Failure 409 {object} ErrorResponse "Station still has equipment"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /stations/{id} [delete]
 func (h *StationHandler) DeleteStation(c *gin.Context) {
@@ -194,6 +267,10 @@ func (h *StationHandler) DeleteStation(c *gin.Context) {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
 			return
 		}
+		if err.Error() == "station has equipment" {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: "Station still has equipment; delete it first"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete station"})
 		return
 	}
@@ -203,13 +280,13 @@ func (h *StationHandler) DeleteStation(c *gin.Context) {
 
 // GetStation godoc
 // @Summary Get station by ID (Admin only)
-// @Description Get station information by ID. Only users with ADMIN role can perform this action.
+// @Description Get station information by ID. Only users with ADMIN role can perform this action. next_change_at and next_status tell when the station next goes on (ACTIVE) or off (INACTIVE) schedule; back-to-back and overnight watches count as one.
 // @Tags stations
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
 // @Param id path int true "Station ID"
-// @Success 200 {object} models.Station "Station information"
+// @Success 200 {object} StationDetail "Station information with its latest notes"
 // @Failure 400 {object} ErrorResponse "Bad request"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
@@ -224,8 +301,12 @@ func (h *StationHandler) GetStation(c *gin.Context) {
 		return
 	}
 
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+
 	// Get station using service
-	station, err := h.stationService.GetByID(uint(stationID))
+	station, err := h.stationService.GetWithStatus(uint(stationID))
 	if err != nil {
 		if err.Error() == "station not found" {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
@@ -234,29 +315,310 @@ func (h *StationHandler) GetStation(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get station"})
 		return
 	}
+	notes, err := h.stationService.RecentNotes(station.ID, services.RecentNotesCount)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get station notes"})
+		return
+	}
 
-	c.JSON(http.StatusOK, station)
+	c.JSON(http.StatusOK, StationDetail{Station: *station, RecentNotes: notes})
 }
 
 // ListStations godoc
 // @Summary List all stations (Admin only)
-// @Description Get a list of all radar stations. Only users with ADMIN role can perform this action.
+// @Description Get a list of all radar stations (only those of their group for users assigned to one), optionally restricted to a radius (great-circle distance, nearest first, with distance_km) or a bounding box, but not both. A box with min_lon > max_lon crosses the antimeridian. Each station has next_change_at and next_status as in GET /stations/{id}.
 // @Tags stations
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
+// @Param within query string false "lat,lon,radius_km"
+// @Param bbox query string false "min_lat,min_lon,max_lat,max_lon"
+// @Param active query bool false "Only stations ACTIVE right now (on schedule and healthy)"
+// @Param group_id query int false "Only stations in this group or the groups below it"
 // @Success 200 {array} models.Station "List of stations"
+// @Failure 400 {object} ErrorResponse "Bad request"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Failure 403 {object} ErrorResponse "Forbidden - Admin access required"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /stations [get]
 func (h *StationHandler) ListStations(c *gin.Context) {
-	// List all stations using service
-	stations, err := h.stationService.List()
+	within, bbox := c.Query("within"), c.Query("bbox")
+	activeOnly := c.Query("active") == "true"
+	if within != "" && bbox != "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Use either within or bbox, not both"})
+		return
+	}
+	scope, ok := stationScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	var box []float64
+	if bbox != "" {
+		var err error
+		if box, err = parseFloatList(bbox, 4); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "bbox must be min_lat,min_lon,max_lat,max_lon"})
+			return
+		}
+	}
+
+	if within != "" {
+		w, err := parseFloatList(within, 3)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "within must be lat,lon,radius_km"})
+			return
+		}
+		stations, err := h.stationService.WithinRadius(w[0], w[1], w[2], activeOnly)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		out := []services.StationDistance{}
+		for _, sd := range stations {
+			if inScope(scope, sd.ID) {
+				out = append(out, sd)
+			}
+		}
+		c.JSON(http.StatusOK, out)
+		return
+	}
+
+	var stations []models.Station
+	var err error
+	if box != nil {
+		stations, err = h.stationService.WithinBox(box[0], box[1], box[2], box[3], activeOnly)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	} else {
+		// List all stations using service
+		stations, err = h.stationService.ListWithStatus()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list stations"})
+			return
+		}
+	}
+
+	out := []models.Station{}
+	for _, st := range stations {
+		if inScope(scope, st.ID) && (!activeOnly || st.Status == models.StationActive) {
+			out = append(out, st)
+		}
+	}
+
+	c.JSON(http.StatusOK, out)
+}
+
+// NearestStations godoc
+// @Summary Find the nearest stations
+// @Description Get the k stations closest to a point by great-circle distance, nearest first.
+// @Tags stations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param lat query number true "Latitude"
+// @Param lon query number true "Longitude"
+// @Param k query int false "Number of stations (default 5, max 100)"
+// @Param active query bool false "Only stations ACTIVE right now (on schedule and healthy)"
+// @Success 200 {array} services.StationDistance "Nearest stations"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/nearest [get]
+func (h *StationHandler) NearestStations(c *gin.Context) {
+	lat, errLat := strconv.ParseFloat(c.Query("lat"), 64)
+	lon, errLon := strconv.ParseFloat(c.Query("lon"), 64)
+	if errLat != nil || errLon != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "lat and lon are required"})
+		return
+	}
+
+	k := 5
+	if raw := c.Query("k"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > 100 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "k must be between 1 and 100"})
+			return
+		}
+		k = n
+	}
+
+	scope, ok := stationScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	stations, err := h.stationService.Nearest(lat, lon, k, c.Query("active") == "true", scope)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to list stations"})
+		if err.Error() == "invalid coordinates" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid coordinates"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to find stations"})
 		return
 	}
 
 	c.JSON(http.StatusOK, stations)
 }
+
+// parseFloatList parses exactly n comma-separated numbers.
+func parseFloatList(s string, n int) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d values", n)
+	}
+	out := make([]float64, n)
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = f
+	}
+	return out, nil
+}
+
+// parseTargetHeight reads the target_height query parameter (m).
+func parseTargetHeight(c *gin.Context) (float64, error) {
+	raw := c.Query("target_height")
+	if raw == "" {
+		return services.DefaultTargetHeight, nil
+	}
+	h, err := strconv.ParseFloat(raw, 64)
+	if err != nil || h < 0 {
+		return 0, errors.New("target_height must be a non-negative number")
+	}
+	return h, nil
+}
+
+// GetStationCoverage godoc
+// @Summary Get the radar coverage of a station
+// @Description Coverage area of one station as a GeoJSON Feature (Polygon). The range is the instrumented range limited by the radio horizon for the antenna height (elevation + antenna_height) and the target height, with the sector limits and blind arcs cut out.
+// @Tags stations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "Station ID"
+// @Param target_height query number false "Target height above sea level in metres (default 10)"
+// @Success 200 {object} services.GeoJSONFeature "Coverage polygon"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - station outside the user's group"
+// @Failure 404 {object} ErrorResponse "Station not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/{id}/coverage [get]
+func (h *StationHandler) GetStationCoverage(c *gin.Context) {
+	stationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid station ID"})
+		return
+	}
+	if !canSeeStation(c, h.groupService, uint(stationID)) {
+		return
+	}
+	targetHeight, err := parseTargetHeight(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	feature, err := h.stationService.Coverage(uint(stationID), targetHeight)
+	if err != nil {
+		switch err.Error() {
+		case "station not found":
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station not found"})
+		case "station has no radar parameters":
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Station has no radar parameters"})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to compute coverage"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, feature)
+}
+
+// GetCoverageMap godoc
+// @Summary Get the current radar coverage
+// @Description Coverage areas of all stations with radar parameters as a GeoJSON FeatureCollection. Only stations ACTIVE right now (on schedule and healthy) are included unless include_inactive=true.
+// @Tags stations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param target_height query number false "Target height above sea level in metres (default 10)"
+// @Param include_inactive query bool false "Also include stations that are not ACTIVE"
+// @Success 200 {object} services.GeoJSONFeatureCollection "Coverage polygons"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/coverage [get]
+func (h *StationHandler) GetCoverageMap(c *gin.Context) {
+	targetHeight, err := parseTargetHeight(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	scope, ok := stationScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	fc, err := h.stationService.CoverageMap(targetHeight, c.Query("include_inactive") != "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to compute coverage"})
+		return
+	}
+
+	c.JSON(http.StatusOK, scopeFeatures(fc, "station_id", scope))
+}
+
+// CoveringStations godoc
+// @Summary Find the stations covering a point
+// @Description Stations whose radar covers the point for a target of the given height, nearest first. Only stations ACTIVE right now (on schedule and healthy) count unless include_inactive=true.
+// @Tags stations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param lat query number true "Latitude"
+// @Param lon query number true "Longitude"
+// @Param target_height query number false "Target height above sea level in metres (default 10)"
+// @Param include_inactive query bool false "Also count stations that are not ACTIVE"
+// @Success 200 {array} services.StationCoverage "Covering stations"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /stations/covering [get]
+func (h *StationHandler) CoveringStations(c *gin.Context) {
+	lat, errLat := strconv.ParseFloat(c.Query("lat"), 64)
+	lon, errLon := strconv.ParseFloat(c.Query("lon"), 64)
+	if errLat != nil || errLon != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "lat and lon are required"})
+		return
+	}
+	targetHeight, err := parseTargetHeight(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	scope, ok := stationScope(c, h.groupService)
+	if !ok {
+		return
+	}
+
+	stations, err := h.stationService.CoveringStations(lat, lon, targetHeight, c.Query("include_inactive") != "true")
+	if err != nil {
+		if err.Error() == "invalid coordinates" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid coordinates"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to find covering stations"})
+		return
+	}
+	out := []services.StationCoverage{}
+	for _, sc := range stations {
+		if inScope(scope, sc.ID) {
+			out = append(out, sc)
+		}
+	}
+
+	c.JSON(http.StatusOK, out)
+}
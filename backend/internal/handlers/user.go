@@ -23,6 +23,7 @@ type CreateUserRequest struct {
 	FullName  string          `json:"full_name" binding:"required" example:"John Doe"`
 	RoleID    models.RoleName `json:"role_id" binding:"required" example:"OPERATOR"`
 	StationID *uint           `json:"station_id,omitempty" example:"1"`
+	GroupID   *uint           `json:"group_id,omitempty" example:"2"`
 }
 
 // UpdateUserRequest represents the update user request payload
@@ -31,6 +32,7 @@ type UpdateUserRequest struct {
 	FullName  *string          `json:"full_name,omitempty" example:"John Smith"`
 	RoleID    *models.RoleName `json:"role_id,omitempty" example:"ADMIN"`
 	StationID *uint            `json:"station_id,omitempty" example:"2"`
+	GroupID   *uint            `json:"group_id,omitempty" example:"2"` // 0 removes the user from their group
 }
 
 // CreateUser godoc
@@ -62,6 +64,7 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		FullName:  req.FullName,
 		RoleID:    req.RoleID,
 		StationID: req.StationID,
+		GroupID:   req.GroupID,
 	}
 
 	// Create user using service
@@ -71,6 +74,10 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 			c.JSON(http.StatusConflict, ErrorResponse{Error: "Username already exists"})
 			return
 		}
+		if err.Error() == "group not found" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Group not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create user"})
 		return
 	}
@@ -124,6 +131,9 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	if req.StationID != nil {
 		updateMap["station_id"] = req.StationID
 	}
+	if req.GroupID != nil {
+		updateMap["group_id"] = req.GroupID
+	}
 
 	user, err := h.userService.UpdateByUsername(username, updateMap)
 	if err != nil {
@@ -131,6 +141,10 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
 			return
 		}
+		if err.Error() == "group not found" {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Group not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update user"})
 		return
 	}
@@ -1,8 +1,14 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
@@ -10,12 +16,14 @@ import (
 )
 
 type VesselHandler struct {
-	vesselService *services.VesselService
+	vesselService   *services.VesselService
+	positionService *services.PositionService
 }
 
-func NewVesselHandler(vesselService *services.VesselService) *VesselHandler {
+func NewVesselHandler(vesselService *services.VesselService, positionService *services.PositionService) *VesselHandler {
 	return &VesselHandler{
-		vesselService: vesselService,
+		vesselService:   vesselService,
+		positionService: positionService,
 	}
 }
 
@@ -51,10 +59,18 @@ func (h *VesselHandler) CreateVessel(c *gin.Context) {
 		Specs:       req.Specs,
 		MaxSpeed:    req.MaxSpeed,
 		Description: req.Description,
+
+		Length:        req.Length,
+		Beam:          req.Beam,
+		Draught:       req.Draught,
+		GrossTonnage:  req.GrossTonnage,
+		MaxSpeedKnots: req.MaxSpeedKnots,
+		IMO:           req.IMO,
+		CallSign:      req.CallSign,
 	}
 
 	if err := h.vesselService.Create(vessel); err != nil {
-		if err.Error() == "vessel with this MMSI already exists" {
+		if err.Error() == "vessel with this MMSI already exists" || errors.Is(err, services.ErrInvalidVessel) {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error":   "Bad Request",
 				"message": err.Error(),
@@ -76,19 +92,42 @@ func (h *VesselHandler) CreateVessel(c *gin.Context) {
 
 // ListVessels godoc
 // @Summary Get all vessels or search by name
-// @Description Get a list of all vessels or search by name
+// @Description Get a list of all vessels or search by name, with optional numeric range filters and sorting
 // @Tags vessels
 // @Produce json
-// @Param name query string false "Search by vessel name (partial match)"
+// @Param name query string false "Search by vessel name (accent-insensitive, tolerates typos; results ranked unless sort is given)"
+// @Param min_length query number false "Minimum length (m)"
+// @Param max_length query number false "Maximum length (m)"
+// @Param min_beam query number false "Minimum beam (m)"
+// @Param max_beam query number false "Maximum beam (m)"
+// @Param min_draught query number false "Minimum draught (m)"
+// @Param max_draught query number false "Maximum draught (m)"
+// @Param min_tonnage query number false "Minimum gross tonnage"
+// @Param max_tonnage query number false "Maximum gross tonnage"
+// @Param min_speed query number false "Minimum max speed (knots)"
+// @Param max_speed query number false "Maximum max speed (knots)"
+// @Param flag_country query string false "Flag state (ISO 3166-1 alpha-2 code or country name)"
+// @Param mmsi_type query string false "MMSI type: SHIP, GROUP, COAST_STATION, SAR_AIRCRAFT, ATON, AUXILIARY_CRAFT, HANDHELD_VHF, SAFETY_DEVICE"
+// @Param sort query string false "Sort by: name, length, beam, draught, gross_tonnage, max_speed_knots"
+// @Param order query string false "asc (default) or desc"
 // @Success 200 {array} models.Vessel
+// @Failure 400 {object} map[string]interface{}
 // @Failure 500 {object} map[string]interface{}
 // @Security ApiKeyAuth
 // @Router /vessels [get]
 func (h *VesselHandler) ListVessels(c *gin.Context) {
 	name := c.Query("name")
 
+	filter, err := parseVesselFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": err.Error(),
+		})
+		return
+	}
+
 	var vessels []*models.Vessel
-	var err error
 
 	if name != "" {
 		// Search by name
@@ -108,7 +147,119 @@ func (h *VesselHandler) ListVessels(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Vessels retrieved successfully",
-		"data":    vessels,
+		"data":    filter.Apply(vessels),
+	})
+}
+
+// parseVesselFilter reads the range and sort query parameters of ListVessels.
+func parseVesselFilter(c *gin.Context) (services.VesselFilter, error) {
+	var f services.VesselFilter
+	bounds := map[string]**float64{
+		"min_length":  &f.MinLength,
+		"max_length":  &f.MaxLength,
+		"min_beam":    &f.MinBeam,
+		"max_beam":    &f.MaxBeam,
+		"min_draught": &f.MinDraught,
+		"max_draught": &f.MaxDraught,
+		"min_tonnage": &f.MinGrossTonnage,
+		"max_tonnage": &f.MaxGrossTonnage,
+		"min_speed":   &f.MinSpeed,
+		"max_speed":   &f.MaxSpeed,
+	}
+	for name, dst := range bounds {
+		v := c.Query(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || n < 0 {
+			return f, errors.New("invalid " + name)
+		}
+		*dst = &n
+	}
+
+	f.FlagCountry = c.Query("flag_country")
+	f.MMSIType = c.Query("mmsi_type")
+
+	f.SortBy = c.Query("sort")
+	if f.SortBy != "" && !services.ValidSortField(f.SortBy) {
+		return f, errors.New("invalid sort field")
+	}
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		f.Desc = true
+	default:
+		return f, errors.New("order must be asc or desc")
+	}
+	return f, nil
+}
+
+// SearchVessels godoc
+// @Summary Search vessels
+// @Description Ranked fuzzy search over vessel name, MMSI, call sign and description. Matching ignores case and diacritics ("dai duong" finds "Đại Dương") and tolerates small typos.
+// @Tags vessels
+// @Produce json
+// @Param q query string true "Search text"
+// @Param fields query string false "Comma-separated fields to search: name, mmsi, call_sign, description (default all)"
+// @Param limit query int false "Maximum number of results (default 20)"
+// @Success 200 {array} services.VesselMatch
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security ApiKeyAuth
+// @Router /vessels/search [get]
+func (h *VesselHandler) SearchVessels(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": "q is required",
+		})
+		return
+	}
+
+	var fields []string
+	if raw := c.Query("fields"); raw != "" {
+		for _, f := range strings.Split(raw, ",") {
+			f = strings.TrimSpace(f)
+			switch f {
+			case "name", "mmsi", "call_sign", "description":
+				fields = append(fields, f)
+			default:
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":   "Bad Request",
+					"message": "Invalid search field: " + f,
+				})
+				return
+			}
+		}
+	}
+
+	limit := 20
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Bad Request",
+				"message": "limit must be a positive integer",
+			})
+			return
+		}
+		limit = n
+	}
+
+	matches, err := h.vesselService.Search(query, fields, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Internal Server Error",
+			"message": "Failed to search vessels",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vessels retrieved successfully",
+		"data":    matches,
 	})
 }
 
@@ -278,9 +429,30 @@ func (h *VesselHandler) UpdateVessel(c *gin.Context) {
 	if req.Description != nil {
 		vessel.Description = *req.Description
 	}
+	if req.Length != nil {
+		vessel.Length = *req.Length
+	}
+	if req.Beam != nil {
+		vessel.Beam = *req.Beam
+	}
+	if req.Draught != nil {
+		vessel.Draught = *req.Draught
+	}
+	if req.GrossTonnage != nil {
+		vessel.GrossTonnage = *req.GrossTonnage
+	}
+	if req.MaxSpeedKnots != nil {
+		vessel.MaxSpeedKnots = *req.MaxSpeedKnots
+	}
+	if req.IMO != nil {
+		vessel.IMO = *req.IMO
+	}
+	if req.CallSign != nil {
+		vessel.CallSign = *req.CallSign
+	}
 
 	if err := h.vesselService.Update(vessel); err != nil {
-		if err.Error() == "vessel with this MMSI already exists" {
+		if err.Error() == "vessel with this MMSI already exists" || errors.Is(err, services.ErrInvalidVessel) {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error":   "Bad Request",
 				"message": err.Error(),
@@ -337,11 +509,290 @@ func (h *VesselHandler) DeleteVessel(c *gin.Context) {
 		return
 	}
 
+	h.positionService.DeleteByVessel(uint(id)) // Ignore error
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Vessel deleted successfully",
 	})
 }
 
+// ReportPosition godoc
+// @Summary Report a vessel position
+// @Description Record a position report (with course and speed) for a vessel
+// @Tags vessels
+// @Accept json
+// @Produce json
+// @Param id path int true "Vessel ID"
+// @Param position body ReportPositionRequest true "Position data"
+// @Success 201 {object} models.VesselPosition
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security ApiKeyAuth
+// @Router /vessels/{id}/positions [post]
+func (h *VesselHandler) ReportPosition(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": "Invalid vessel ID",
+		})
+		return
+	}
+
+	var req ReportPositionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	pos := &models.VesselPosition{
+		VesselID:  uint(id),
+		Latitude:  *req.Latitude,
+		Longitude: *req.Longitude,
+		Course:    req.Course,
+		Speed:     req.Speed,
+		Source:    req.Source,
+		Timestamp: req.Timestamp,
+	}
+
+	if err := h.positionService.Report(pos); err != nil {
+		if err.Error() == "vessel not found" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Not Found",
+				"message": "Vessel not found",
+			})
+			return
+		}
+		if errors.Is(err, services.ErrInvalidPosition) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Bad Request",
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Internal Server Error",
+			"message": "Failed to record position",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Position recorded successfully",
+		"data":    pos,
+	})
+}
+
+// GetTrack godoc
+// @Summary Get a vessel track
+// @Description Get the recorded positions of a vessel, optionally limited to a time range
+// @Tags vessels
+// @Produce json
+// @Param id path int true "Vessel ID"
+// @Param from query int false "Start time (unix seconds)"
+// @Param to query int false "End time (unix seconds)"
+// @Success 200 {array} models.VesselPosition
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security ApiKeyAuth
+// @Router /vessels/{id}/positions [get]
+func (h *VesselHandler) GetTrack(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": "Invalid vessel ID",
+		})
+		return
+	}
+
+	var from, to int64
+	if v := c.Query("from"); v != "" {
+		if from, err = strconv.ParseInt(v, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Bad Request",
+				"message": "Invalid from",
+			})
+			return
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = strconv.ParseInt(v, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Bad Request",
+				"message": "Invalid to",
+			})
+			return
+		}
+	}
+
+	track, err := h.positionService.Track(uint(id), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Internal Server Error",
+			"message": "Failed to retrieve track",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Track retrieved successfully",
+		"data":    track,
+	})
+}
+
+// maxImportSize limits the size of an uploaded import file.
+const maxImportSize = 20 << 20
+
+// ImportVessels godoc
+// @Summary Bulk import vessels
+// @Description Import vessels from CSV (with header) or a JSON array. Rows are upserted by MMSI: known MMSIs update the existing vessel, others create a new one. Use dry_run to validate without writing.
+// @Tags vessels
+// @Accept multipart/form-data
+// @Accept text/csv
+// @Accept json
+// @Produce json
+// @Param file formData file false "CSV or JSON file (alternatively send the content as the request body)"
+// @Param format query string false "csv or json (default: from file extension or Content-Type)"
+// @Param mapping query string false "JSON object mapping source columns to vessel fields, e.g. {\"Ship Name\":\"name\"}"
+// @Param dry_run query bool false "Validate only, do not write"
+// @Success 200 {object} services.ImportReport
+// @Failure 400 {object} map[string]interface{}
+// @Security ApiKeyAuth
+// @Router /vessels/import [post]
+func (h *VesselHandler) ImportVessels(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	var body io.Reader = c.Request.Body
+	format := strings.ToLower(c.Query("format"))
+	mappingParam := c.Query("mapping")
+
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Bad Request",
+				"message": "No file provided",
+			})
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Bad Request",
+				"message": "Cannot read file",
+			})
+			return
+		}
+		defer file.Close()
+		body = file
+		if format == "" {
+			format = strings.TrimPrefix(strings.ToLower(filepath.Ext(fileHeader.Filename)), ".")
+		}
+		if mappingParam == "" {
+			mappingParam = c.PostForm("mapping")
+		}
+	} else if format == "" {
+		switch c.ContentType() {
+		case "text/csv":
+			format = "csv"
+		case "application/json":
+			format = "json"
+		}
+	}
+
+	var mapping map[string]string
+	if mappingParam != "" {
+		if err := json.Unmarshal([]byte(mappingParam), &mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Bad Request",
+				"message": "mapping must be a JSON object of strings",
+			})
+			return
+		}
+	}
+
+	rows, err := services.ParseVesselRows(body, format, mapping)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+	report := h.vesselService.Import(rows, dryRun)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vessel import processed",
+		"data":    report,
+	})
+}
+
+// ExportVessels godoc
+// @Summary Export vessels
+// @Description Export vessels as CSV or JSON. Accepts the same filters as the vessel list.
+// @Tags vessels
+// @Produce text/csv
+// @Produce json
+// @Param format query string false "csv (default) or json"
+// @Param name query string false "Search by vessel name (accent-insensitive, tolerates typos; results ranked unless sort is given)"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Security ApiKeyAuth
+// @Router /vessels/export [get]
+func (h *VesselHandler) ExportVessels(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", "csv"))
+	if format != "csv" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": "format must be csv or json",
+		})
+		return
+	}
+
+	filter, err := parseVesselFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Bad Request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	var vessels []*models.Vessel
+	if name := c.Query("name"); name != "" {
+		vessels, err = h.vesselService.SearchByName(name)
+	} else {
+		vessels, err = h.vesselService.List()
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Internal Server Error",
+			"message": "Failed to retrieve vessels",
+		})
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == "json" {
+		contentType = "application/json; charset=utf-8"
+	}
+	filename := "vessels-" + time.Now().Format("20060102") + "." + format
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+	if err := services.ExportVessels(c.Writer, filter.Apply(vessels), format); err != nil {
+		c.Error(err)
+	}
+}
+
 // Request/Response models
 type CreateVesselRequest struct {
 	Name        string `json:"name" binding:"required"` // Tên tàu
@@ -353,6 +804,14 @@ type CreateVesselRequest struct {
 	Specs       string `json:"specs"`                   // Thông số kỹ thuật
 	MaxSpeed    string `json:"max_speed"`               // Tốc độ tối đa
 	Description string `json:"description"`             // Mô tả thêm về tàu
+
+	Length        float64 `json:"length"`          // Chiều dài (m)
+	Beam          float64 `json:"beam"`            // Chiều rộng (m)
+	Draught       float64 `json:"draught"`         // Mớn nước (m)
+	GrossTonnage  float64 `json:"gross_tonnage"`   // Tổng dung tích (GT)
+	MaxSpeedKnots float64 `json:"max_speed_knots"` // Tốc độ tối đa (knots)
+	IMO           string  `json:"imo_number"`      // Số IMO
+	CallSign      string  `json:"call_sign"`       // Hô hiệu
 }
 
 type UpdateVesselRequest struct {
@@ -365,4 +824,21 @@ type UpdateVesselRequest struct {
 	Specs       *string `json:"specs"`       // Thông số kỹ thuật
 	MaxSpeed    *string `json:"max_speed"`   // Tốc độ tối đa
 	Description *string `json:"description"` // Mô tả thêm về tàu
+
+	Length        *float64 `json:"length"`          // Chiều dài (m)
+	Beam          *float64 `json:"beam"`            // Chiều rộng (m)
+	Draught       *float64 `json:"draught"`         // Mớn nước (m)
+	GrossTonnage  *float64 `json:"gross_tonnage"`   // Tổng dung tích (GT)
+	MaxSpeedKnots *float64 `json:"max_speed_knots"` // Tốc độ tối đa (knots)
+	IMO           *string  `json:"imo_number"`      // Số IMO
+	CallSign      *string  `json:"call_sign"`       // Hô hiệu
+}
+
+type ReportPositionRequest struct {
+	Latitude  *float64 `json:"latitude" binding:"required"`  // Vĩ độ
+	Longitude *float64 `json:"longitude" binding:"required"` // Kinh độ
+	Course    float64  `json:"course"`                       // Hướng đi (độ)
+	Speed     float64  `json:"speed"`                        // Tốc độ (knots)
+	Source    string   `json:"source"`                       // "AIS" / "RADAR" / "MANUAL"
+	Timestamp int64    `json:"timestamp"`                    // Thời điểm ghi nhận (unix), mặc định là hiện tại
 }
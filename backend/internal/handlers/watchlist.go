@@ -0,0 +1,418 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/services"
+)
+
+type WatchlistHandler struct {
+	watchlistService *services.WatchlistService
+}
+
+func NewWatchlistHandler(watchlistService *services.WatchlistService) *WatchlistHandler {
+	return &WatchlistHandler{watchlistService: watchlistService}
+}
+
+// CreateWatchlistRequest represents the request for creating a watchlist
+type CreateWatchlistRequest struct {
+	Name        string `json:"name" binding:"required" example:"Tàu cá vi phạm"`
+	Description string `json:"description,omitempty"`
+}
+
+// UpdateWatchlistRequest represents the request for updating a watchlist
+type UpdateWatchlistRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// AddWatchlistEntryRequest represents the request for adding a vessel to a watchlist
+type AddWatchlistEntryRequest struct {
+	MMSI      string `json:"mmsi,omitempty" example:"574123456"`
+	VesselID  uint   `json:"vessel_id,omitempty"`
+	Reason    string `json:"reason" binding:"required"`
+	ExpiresAt *int64 `json:"expires_at,omitempty"`
+}
+
+// ReportSightingRequest represents a manual sighting report
+type ReportSightingRequest struct {
+	MMSI      string   `json:"mmsi,omitempty" example:"574123456"`
+	VesselID  uint     `json:"vessel_id,omitempty"`
+	Latitude  *float64 `json:"latitude" binding:"required"`
+	Longitude *float64 `json:"longitude" binding:"required"`
+	Note      string   `json:"note,omitempty"`
+	SightedAt int64    `json:"sighted_at,omitempty"`
+}
+
+// CreateWatchlist creates a new watchlist
+// @Summary Create a watchlist
+// @Description Create a named list of vessels of interest (HQ only)
+// @Tags watchlists
+// @Accept json
+// @Produce json
+// @Param watchlist body CreateWatchlistRequest true "Watchlist data"
+// @Security ApiKeyAuth
+// @Success 201 {object} models.Watchlist
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /watchlists [post]
+func (h *WatchlistHandler) CreateWatchlist(c *gin.Context) {
+	var req CreateWatchlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	watchlist := &models.Watchlist{
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   user.ID,
+	}
+	if err := h.watchlistService.Create(watchlist); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to create watchlist"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, watchlist)
+}
+
+// ListWatchlists lists all watchlists
+// @Summary List watchlists
+// @Description Get all watchlists (HQ only)
+// @Tags watchlists
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.Watchlist
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /watchlists [get]
+func (h *WatchlistHandler) ListWatchlists(c *gin.Context) {
+	watchlists, err := h.watchlistService.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve watchlists"})
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlists)
+}
+
+// GetWatchlist retrieves a watchlist
+// @Summary Get a watchlist
+// @Description Get a watchlist by ID (HQ only)
+// @Tags watchlists
+// @Produce json
+// @Param id path int true "Watchlist ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.Watchlist
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /watchlists/{id} [get]
+func (h *WatchlistHandler) GetWatchlist(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid watchlist ID"})
+		return
+	}
+
+	watchlist, err := h.watchlistService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Watchlist not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlist)
+}
+
+// UpdateWatchlist updates a watchlist
+// @Summary Update a watchlist
+// @Description Update the name or description of a watchlist (HQ only)
+// @Tags watchlists
+// @Accept json
+// @Produce json
+// @Param id path int true "Watchlist ID"
+// @Param watchlist body UpdateWatchlistRequest true "Watchlist data"
+// @Security ApiKeyAuth
+// @Success 200 {object} models.Watchlist
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /watchlists/{id} [put]
+func (h *WatchlistHandler) UpdateWatchlist(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid watchlist ID"})
+		return
+	}
+
+	var req UpdateWatchlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	watchlist, err := h.watchlistService.Update(uint(id), req.Name, req.Description)
+	if err != nil {
+		if err.Error() == "watchlist not found" {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Watchlist not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update watchlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlist)
+}
+
+// DeleteWatchlist deletes a watchlist
+// @Summary Delete a watchlist
+// @Description Delete a watchlist with its entries and alert history (HQ only)
+// @Tags watchlists
+// @Param id path int true "Watchlist ID"
+// @Security ApiKeyAuth
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /watchlists/{id} [delete]
+func (h *WatchlistHandler) DeleteWatchlist(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid watchlist ID"})
+		return
+	}
+
+	if err := h.watchlistService.Delete(uint(id)); err != nil {
+		if err.Error() == "watchlist not found" {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Watchlist not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete watchlist"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddEntry adds a vessel to a watchlist
+// @Summary Add a watchlist entry
+// @Description Add a vessel (by MMSI or vessel ID) to a watchlist with a reason and optional expiry (HQ only)
+// @Tags watchlists
+// @Accept json
+// @Produce json
+// @Param id path int true "Watchlist ID"
+// @Param entry body AddWatchlistEntryRequest true "Entry data"
+// @Security ApiKeyAuth
+// @Success 201 {object} models.WatchlistEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /watchlists/{id}/entries [post]
+func (h *WatchlistHandler) AddEntry(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid watchlist ID"})
+		return
+	}
+
+	var req AddWatchlistEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	entry := &models.WatchlistEntry{
+		WatchlistID: uint(id),
+		MMSI:        req.MMSI,
+		VesselID:    req.VesselID,
+		Reason:      req.Reason,
+		ExpiresAt:   req.ExpiresAt,
+		AddedBy:     user.ID,
+	}
+	if err := h.watchlistService.AddEntry(entry); err != nil {
+		if strings.HasSuffix(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, entry)
+}
+
+// ListEntries lists the entries of a watchlist
+// @Summary List watchlist entries
+// @Description Get all entries of a watchlist, including expired ones (HQ only)
+// @Tags watchlists
+// @Produce json
+// @Param id path int true "Watchlist ID"
+// @Security ApiKeyAuth
+// @Success 200 {array} models.WatchlistEntry
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /watchlists/{id}/entries [get]
+func (h *WatchlistHandler) ListEntries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid watchlist ID"})
+		return
+	}
+
+	if _, err := h.watchlistService.GetByID(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Watchlist not found"})
+		return
+	}
+
+	entries, err := h.watchlistService.ListEntries(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve entries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// RemoveEntry removes a vessel from a watchlist
+// @Summary Remove a watchlist entry
+// @Description Remove an entry and its alert history from a watchlist (HQ only)
+// @Tags watchlists
+// @Param id path int true "Watchlist ID"
+// @Param entry_id path int true "Entry ID"
+// @Security ApiKeyAuth
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /watchlists/{id}/entries/{entry_id} [delete]
+func (h *WatchlistHandler) RemoveEntry(c *gin.Context) {
+	id, entryID, ok := parseWatchlistEntryParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.watchlistService.RemoveEntry(id, entryID); err != nil {
+		if err.Error() == "entry not found" {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: "Entry not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to remove entry"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListEntryAlerts lists the alert history of a watchlist entry
+// @Summary List alerts of a watchlist entry
+// @Description Get every alert raised for a watchlist entry, oldest first (HQ only)
+// @Tags watchlists
+// @Produce json
+// @Param id path int true "Watchlist ID"
+// @Param entry_id path int true "Entry ID"
+// @Security ApiKeyAuth
+// @Success 200 {array} models.WatchlistAlert
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /watchlists/{id}/entries/{entry_id}/alerts [get]
+func (h *WatchlistHandler) ListEntryAlerts(c *gin.Context) {
+	id, entryID, ok := parseWatchlistEntryParams(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.watchlistService.GetEntry(id, entryID); err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "Entry not found"})
+		return
+	}
+
+	alerts, err := h.watchlistService.ListAlerts(entryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to retrieve alerts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+// ReportSighting records a manual sighting and checks it against watchlists
+// @Summary Report a manual sighting
+// @Description Report a visual or manual sighting of a vessel; alerts are raised for every matching watchlist entry
+// @Tags watchlists
+// @Accept json
+// @Produce json
+// @Param sighting body ReportSightingRequest true "Sighting data"
+// @Security ApiKeyAuth
+// @Success 200 {array} models.WatchlistAlert
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /sightings [post]
+func (h *WatchlistHandler) ReportSighting(c *gin.Context) {
+	var req ReportSightingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request format"})
+		return
+	}
+	if req.MMSI == "" && req.VesselID == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "mmsi or vessel_id is required"})
+		return
+	}
+
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	alerts, err := h.watchlistService.CheckSighting(services.Sighting{
+		VesselID:   req.VesselID,
+		MMSI:       req.MMSI,
+		Source:     models.SightingManual,
+		Latitude:   *req.Latitude,
+		Longitude:  *req.Longitude,
+		Note:       req.Note,
+		ReportedBy: user.ID,
+		Time:       req.SightedAt,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to process sighting"})
+		return
+	}
+	if alerts == nil {
+		alerts = []models.WatchlistAlert{}
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+func parseWatchlistEntryParams(c *gin.Context) (uint, uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid watchlist ID"})
+		return 0, 0, false
+	}
+	entryID, err := strconv.ParseUint(c.Param("entry_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid entry ID"})
+		return 0, 0, false
+	}
+	return uint(id), uint(entryID), true
+}
@@ -43,6 +43,30 @@ func JWTMiddleware(userService *services.UserService) gin.HandlerFunc {
 	}
 }
 
+// CalendarTokenMiddleware authenticates calendar feeds, which calendar
+// clients fetch without headers: a "token" query parameter holding the
+// user's calendar token, or else a JWT as in JWTMiddleware.
+func CalendarTokenMiddleware(userService *services.UserService) gin.HandlerFunc {
+	jwtAuth := JWTMiddleware(userService)
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if token == "" {
+			jwtAuth(c)
+			return
+		}
+
+		user, err := userService.GetUserFromCalendarToken(token)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid calendar token"})
+			c.Abort()
+			return
+		}
+
+		c.Set("user", user)
+		c.Next()
+	}
+}
+
 // AdminMiddleware ensures only users with ADMIN role can access the endpoint
 func AdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -33,6 +33,9 @@ type User struct {
 	StationID *uint    `json:"station_id,omitempty"`
 	Station   *Station `json:"station,omitempty"`
 
+	// Nếu được gán: chỉ xem và ra lệnh cho các trạm thuộc nhóm (vùng/khu vực)
+	GroupID *uint `json:"group_id,omitempty"`
+
 	LastLogin *int64 `json:"last_login,omitempty"`
 	CreatedAt int64  `json:"created_at"`
 	UpdatedAt int64  `json:"updated_at"`
@@ -49,20 +52,80 @@ type Station struct {
 	Longitude       float64 `json:"longitude"`
 	Elevation       float64 `json:"elevation"`         // mét so với mực nước biển
 	DistanceToCoast float64 `json:"distance_to_coast"` // km
-	Status          string  `json:"status"`            // "ACTIVE" / "INACTIVE" (tính từ lịch)
+	Status          string  `json:"status"`            // ACTIVE / INACTIVE / DEGRADED / OFFLINE (tính từ lịch + heartbeat), MAINTENANCE / OUT_OF_SERVICE (đặt tay)
+
+	NextChangeAt *int64 `json:"next_change_at,omitempty"` // thời điểm trạm vào/ra lịch trực lần tới (trống = không đổi trong 1 năm hoặc đang đặt tay không thời hạn)
+	NextStatus   string `json:"next_status,omitempty"`    // ACTIVE / INACTIVE sau thời điểm NextChangeAt
+
+	Note string `json:"note,omitempty"` // ghi chú ghim mới nhất (xem StationNote)
+
+	GroupID *uint `json:"group_id,omitempty"` // khu vực (SECTOR) hoặc vùng (REGION) quản lý trạm
+
+	TimeZone string `json:"time_zone,omitempty"` // múi giờ IANA của lịch trực, ví dụ "Asia/Ho_Chi_Minh" (trống = UTC+7)
 
-	Note string `json:"note,omitempty"` // ghi chú tự do
+	Radar *RadarParams `json:"radar,omitempty"` // thông số radar (nil = chưa khai báo, không tính vùng phủ)
 
 	CreatedAt int64 `json:"created_at"`
 	UpdatedAt int64 `json:"updated_at"`
 }
 
+// Thông số radar của trạm, dùng để tính vùng phủ sóng.
+// Góc tính theo độ, chiều kim đồng hồ từ hướng Bắc thật.
+type RadarParams struct {
+	AntennaHeight float64 `json:"antenna_height"`       // mét trên mặt đất (cộng với Elevation)
+	RangeKm       float64 `json:"range_km"`             // cự ly thiết kế (instrumented range)
+	Sector        *Arc    `json:"sector,omitempty"`     // giới hạn góc quét (nil = 360°)
+	BlindArcs     []Arc   `json:"blind_arcs,omitempty"` // các cung mù (bị địa hình/công trình che)
+}
+
+// Cung góc từ From đến To theo chiều kim đồng hồ, ví dụ 300→60 đi qua hướng Bắc.
+type Arc struct {
+	From float64 `json:"from"`
+	To   float64 `json:"to"`
+}
+
+// Nhật ký ghi chú của trạm (chỉ thêm, không sửa nội dung).
+// Station.Note hiển thị ghi chú ghim mới nhất để tương thích.
+type StationNote struct {
+	ID        uint   `json:"id"`
+	StationID uint   `json:"station_id"`
+	Author    string `json:"author"` // Username người ghi ("" = ghi chú cũ trước khi có nhật ký)
+	Text      string `json:"text"`
+	Pinned    bool   `json:"pinned"` // Ghim: ghi chú ghim mới nhất hiện ở Station.Note
+	CreatedAt int64  `json:"created_at"`
+}
+
+//========================
+// Station Groups – phân cấp vùng → khu vực → trạm
+//========================
+// Vùng (REGION) là cấp cao nhất; khu vực / đơn vị chỉ huy (SECTOR) thuộc một
+// vùng. Trạm gắn vào một khu vực hoặc trực tiếp vào một vùng.
+
+// Station group kinds
+const (
+	GroupRegion = "REGION" // Vùng duyên hải
+	GroupSector = "SECTOR" // Khu vực / đơn vị chỉ huy
+)
+
+type StationGroup struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`                // REGION / SECTOR
+	ParentID    *uint  `json:"parent_id,omitempty"` // SECTOR: vùng chứa khu vực
+	Description string `json:"description,omitempty"`
+	CreatedAt   int64  `json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+}
+
 //========================
 // Station Schedule – khung giờ bật máy + thông tin kíp trực
 //========================
 // Mỗi bản ghi đại diện cho một khoảng HH:MM hằng ngày.
 // Ví dụ: 01:30–03:30 => StartHHMM="0130", EndHHMM="0330".
 // Trạm khai báo người trực ngay trên lịch để HQ xem một bảng duy nhất.
+// Recurrence (tuỳ chọn) giới hạn các ngày áp dụng theo tinh thần RRULE
+// (RFC 5545); không có Recurrence => lặp lại mỗi ngày, vô thời hạn.
+// Giờ và ngày ("YYYY-MM-DD") tính theo múi giờ của trạm (Station.TimeZone).
 
 type Schedule struct {
 	ID        uint `json:"id"`
@@ -76,10 +139,189 @@ type Schedule struct {
 	Crew      string `json:"crew"`      // Danh sách kíp trực
 	Phone     string `json:"phone"`     // Số liên lạc
 
+	// Khi gán theo danh sách quân số (Personnel), Commander/Crew/Phone được
+	// điền tự động từ hồ sơ.
+	CommanderID *uint  `json:"commander_id,omitempty"`
+	CrewIDs     []uint `json:"crew_ids,omitempty"`
+
+	// ====== Quy tắc lặp lại ======
+	Recurrence  *Recurrence `json:"recurrence,omitempty"`
+	ExceptDates []string    `json:"except_dates,omitempty"` // Ngày nghỉ trực (EXDATE), ví dụ ngày lễ
+
 	CreatedAt int64 `json:"created_at"`
 	UpdatedAt int64 `json:"updated_at"`
 }
 
+const (
+	FreqDaily  = "DAILY"
+	FreqWeekly = "WEEKLY"
+)
+
+// Recurrence – tập con của RRULE: FREQ, INTERVAL, BYDAY, DTSTART, UNTIL, COUNT.
+// Một ca đêm thuộc về ngày bắt đầu của nó.
+type Recurrence struct {
+	Freq      string   `json:"freq"`                 // DAILY | WEEKLY
+	Interval  int      `json:"interval,omitempty"`   // Mỗi N ngày/tuần, mặc định 1
+	ByDay     []string `json:"by_day,omitempty"`     // MO TU WE TH FR SA SU
+	StartDate string   `json:"start_date,omitempty"` // DTSTART; bắt buộc khi có interval > 1 hoặc count
+	Until     string   `json:"until,omitempty"`      // Ngày cuối cùng (bao gồm)
+	Count     int      `json:"count,omitempty"`      // Số lần xuất hiện tối đa (không dùng cùng until)
+}
+
+const (
+	ScheduleOverrideExtra  = "EXTRA"  // Ca trực bổ sung
+	ScheduleOverrideCancel = "CANCEL" // Tắt máy trong khung giờ dù lịch đang bật
+)
+
+// ScheduleOverride – thay đổi một lần cho một ngày cụ thể, ưu tiên hơn lịch.
+// CANCEL thắng EXTRA khi chồng lên nhau.
+type ScheduleOverride struct {
+	ID        uint   `json:"id"`
+	StationID uint   `json:"station_id"`
+	Date      string `json:"date"` // "YYYY-MM-DD"
+	Kind      string `json:"kind"` // EXTRA | CANCEL
+	StartHHMM string `json:"start_hhmm"`
+	EndHHMM   string `json:"end_hhmm"` // Nhỏ hơn StartHHMM => kéo sang ngày hôm sau
+	Reason    string `json:"reason,omitempty"`
+	Commander string `json:"commander,omitempty"`
+	Crew      string `json:"crew,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+	CreatedBy string `json:"created_by"`
+	CreatedAt int64  `json:"created_at"`
+
+	CommanderID *uint  `json:"commander_id,omitempty"`
+	CrewIDs     []uint `json:"crew_ids,omitempty"`
+}
+
+// Schedule change actions
+const (
+	ScheduleChangeCreate = "CREATE"
+	ScheduleChangeUpdate = "UPDATE"
+	ScheduleChangeDelete = "DELETE"
+
+	ScheduleChangeCreateOverride = "CREATE_OVERRIDE" // Thêm ca bổ sung / tắt máy một lần
+	ScheduleChangeDeleteOverride = "DELETE_OVERRIDE"
+)
+
+// Schedule change statuses
+const (
+	ScheduleChangePending  = "PENDING"
+	ScheduleChangeApproved = "APPROVED" // Đã áp dụng
+	ScheduleChangeRejected = "REJECTED"
+)
+
+// ScheduleChange – đề xuất thay đổi lịch của trạm, chỉ có hiệu lực khi HQ
+// phê duyệt. Emergency: trạm áp dụng ngay không chờ duyệt (được ghi nhận để
+// HQ xem lại).
+type ScheduleChange struct {
+	ID         uint      `json:"id"`
+	StationID  uint      `json:"station_id"`
+	Action     string    `json:"action"`                // CREATE / UPDATE / DELETE / CREATE_OVERRIDE / DELETE_OVERRIDE
+	ScheduleID uint      `json:"schedule_id,omitempty"` // CREATE: gán khi được duyệt
+	Schedule   *Schedule `json:"schedule,omitempty"`    // Lịch đề xuất (CREATE / UPDATE)
+	Previous   *Schedule `json:"previous,omitempty"`    // Lịch lúc đề xuất (UPDATE / DELETE)
+	Reason     string    `json:"reason,omitempty"`
+	Emergency  bool      `json:"emergency"`
+
+	OverrideID uint              `json:"override_id,omitempty"` // CREATE_OVERRIDE: gán khi được duyệt
+	Override   *ScheduleOverride `json:"override,omitempty"`    // Thay đổi một lần đề xuất (CREATE_OVERRIDE) hoặc lúc đề xuất (DELETE_OVERRIDE)
+
+	Status      string `json:"status"` // PENDING / APPROVED / REJECTED
+	RequestedBy string `json:"requested_by"`
+	RequestedAt int64  `json:"requested_at"`
+
+	ReviewedBy    string `json:"reviewed_by,omitempty"`
+	ReviewedAt    *int64 `json:"reviewed_at,omitempty"`
+	ReviewComment string `json:"review_comment,omitempty"`
+}
+
+// ScheduleTemplate – mẫu lịch trực dùng chung (ví dụ 4 ca 6 giờ), áp dụng
+// cho một hoặc nhiều trạm thay vì nhập lại từng khung giờ.
+type ScheduleTemplate struct {
+	ID          uint                   `json:"id"`
+	Name        string                 `json:"name"` // Duy nhất, không phân biệt hoa thường
+	Description string                 `json:"description,omitempty"`
+	Slots       []ScheduleTemplateSlot `json:"slots"`
+	CreatedBy   string                 `json:"created_by"`
+	CreatedAt   int64                  `json:"created_at"`
+	UpdatedAt   int64                  `json:"updated_at"`
+}
+
+// ScheduleTemplateSlot – một khung giờ của mẫu; kíp trực được gán sau tại
+// từng trạm.
+type ScheduleTemplateSlot struct {
+	StartHHMM   string      `json:"start_hhmm"`
+	EndHHMM     string      `json:"end_hhmm"`
+	Recurrence  *Recurrence `json:"recurrence,omitempty"`
+	ExceptDates []string    `json:"except_dates,omitempty"`
+}
+
+//========================
+// Personnel – quân số trực, có thể gắn với tài khoản đăng nhập
+//========================
+
+type Personnel struct {
+	ID             uint     `json:"id"`
+	FullName       string   `json:"full_name"`
+	Rank           string   `json:"rank,omitempty"` // Cấp bậc, ví dụ "Trung úy"
+	Phone          string   `json:"phone,omitempty"`
+	Email          string   `json:"email,omitempty"`
+	Qualifications []string `json:"qualifications,omitempty"` // Chuyên môn, ví dụ "RADAR_OPERATOR", "WATCH_COMMANDER"
+	Username       string   `json:"username,omitempty"`       // Tài khoản đăng nhập (nếu có)
+	StationID      *uint    `json:"station_id,omitempty"`     // Trạm biên chế
+
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+//========================
+// Handover – bàn giao giữa hai ca trực liền kề
+//========================
+// Trực chỉ huy ca ra ghi các nội dung bàn giao; trực chỉ huy ca vào ký nhận.
+// Biên bản chưa ký nhận hiện trong danh sách theo dõi của HQ.
+
+// Handover item kinds
+const (
+	HandoverOpenCommand = "OPEN_COMMAND" // Lệnh HQ chưa hoàn thành
+	HandoverEquipment   = "EQUIPMENT"    // Sự cố thiết bị
+	HandoverContact     = "CONTACT"      // Mục tiêu / tàu cần chú ý
+	HandoverOther       = "OTHER"
+)
+
+type HandoverItem struct {
+	Kind        string `json:"kind"` // OPEN_COMMAND / EQUIPMENT / CONTACT / OTHER
+	Text        string `json:"text"`
+	CommandID   *uint  `json:"command_id,omitempty"`   // OPEN_COMMAND: lệnh liên quan
+	EquipmentID *uint  `json:"equipment_id,omitempty"` // EQUIPMENT: thiết bị của trạm
+	MMSI        string `json:"mmsi,omitempty"`         // CONTACT: tàu liên quan
+}
+
+// HandoverWatch – một ca trực cụ thể (khung giờ theo lịch hoặc ca bổ sung).
+type HandoverWatch struct {
+	ScheduleID  uint   `json:"schedule_id,omitempty"`
+	OverrideID  uint   `json:"override_id,omitempty"` // Ca bổ sung
+	Start       int64  `json:"start"`
+	End         int64  `json:"end"`
+	Commander   string `json:"commander,omitempty"`
+	CommanderID *uint  `json:"commander_id,omitempty"`
+}
+
+type Handover struct {
+	ID        uint           `json:"id"`
+	StationID uint           `json:"station_id"`
+	Outgoing  HandoverWatch  `json:"outgoing"` // Ca ra
+	Incoming  HandoverWatch  `json:"incoming"` // Ca vào
+	Items     []HandoverItem `json:"items"`
+	Note      string         `json:"note,omitempty"`
+	CreatedBy string         `json:"created_by"` // Username trực chỉ huy ca ra
+	CreatedAt int64          `json:"created_at"`
+
+	// Ký nhận của trực chỉ huy ca vào
+	SignedBy   string `json:"signed_by,omitempty"`
+	SignedAt   *int64 `json:"signed_at,omitempty"`
+	SignedNote string `json:"signed_note,omitempty"`
+}
+
 //========================
 // Command Flow (HQ → Station)
 //========================
@@ -113,15 +355,238 @@ type Document struct {
 
 type Vessel struct {
 	ID          uint   `json:"id"`
-	Name        string `json:"name"`        // Tên tàu
-	MMSI        string `json:"mmsi"`        // Maritime Mobile Service Identity
-	Kind        string `json:"kind"`        // Loại tàu : "TC (Tàu chiến), DS (Dân sự) .... "
-	Size        string `json:"size"`        // Kích cỡ tàu: "width x height x depth"
-	Weight      string `json:"weight"`      // Trọng tải tàu
-	Class       string `json:"class"`       // Lớp tàu: "Lớp A, Lớp B, Lớp C, Lớp D"
-	Specs       string `json:"specs"`       // Thông số kỹ thuật
-	MaxSpeed    string `json:"max_speed"`   // Tốc độ tối đa
-	Description string `json:"description"` // Mô tả thêm về tàu
+	Name        string `json:"name"`         // Tên tàu
+	MMSI        string `json:"mmsi"`         // Maritime Mobile Service Identity
+	MMSIType    string `json:"mmsi_type"`    // Loại MMSI: SHIP, GROUP, COAST_STATION, SAR_AIRCRAFT, ATON...
+	FlagCode    string `json:"flag_code"`    // Mã quốc gia treo cờ (ISO 3166-1), suy ra từ MID
+	FlagCountry string `json:"flag_country"` // Quốc gia treo cờ, suy ra từ MID
+	Kind        string `json:"kind"`         // Loại tàu : "TC (Tàu chiến), DS (Dân sự) .... "
+	Size        string `json:"size"`         // Kích cỡ tàu (cũ, dạng chữ): "width x height x depth"
+	Weight      string `json:"weight"`       // Trọng tải tàu (cũ, dạng chữ)
+	Class       string `json:"class"`        // Lớp tàu: "Lớp A, Lớp B, Lớp C, Lớp D"
+	Specs       string `json:"specs"`        // Thông số kỹ thuật
+	MaxSpeed    string `json:"max_speed"`    // Tốc độ tối đa (cũ, dạng chữ)
+	Description string `json:"description"`  // Mô tả thêm về tàu
+
+	// ====== Thông số dạng số (0 = chưa rõ) ======
+	Length        float64 `json:"length"`          // Chiều dài (m)
+	Beam          float64 `json:"beam"`            // Chiều rộng (m)
+	Draught       float64 `json:"draught"`         // Mớn nước (m)
+	GrossTonnage  float64 `json:"gross_tonnage"`   // Tổng dung tích (GT)
+	MaxSpeedKnots float64 `json:"max_speed_knots"` // Tốc độ tối đa (knots)
+	IMO           string  `json:"imo_number"`      // Số IMO (7 chữ số)
+	CallSign      string  `json:"call_sign"`       // Hô hiệu
+
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+//========================
+// Vessel Positions – vị trí tàu (AIS / radar / nhập tay)
+//========================
+// Course tính theo độ (0–360, theo chiều kim đồng hồ từ hướng Bắc),
+// Speed tính theo hải lý/giờ (knots).
+
+type VesselPosition struct {
+	VesselID  uint    `json:"vessel_id"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Course    float64 `json:"course"`           // Hướng đi thực (COG), độ
+	Speed     float64 `json:"speed"`            // Tốc độ (SOG), knots
+	Source    string  `json:"source,omitempty"` // "AIS" / "RADAR" / "MANUAL"
+	Timestamp int64   `json:"timestamp"`        // Thời điểm ghi nhận (unix)
+}
+
+// Encounter mô tả một cặp tàu có nguy cơ va chạm (CPA/TCPA).
+// Khoảng cách tính theo hải lý, thời gian theo phút.
+type Encounter struct {
+	VesselA   uint    `json:"vessel_a"`
+	VesselB   uint    `json:"vessel_b"`
+	StationID uint    `json:"station_id"` // Trạm gần nhất với cặp tàu
+	Range     float64 `json:"range"`      // Khoảng cách hiện tại (NM)
+	CPA       float64 `json:"cpa"`        // Closest Point of Approach (NM)
+	TCPA      float64 `json:"tcpa"`       // Time to CPA (phút)
+	Bearing   float64 `json:"bearing"`    // Phương vị từ A tới B (độ)
+}
+
+//========================
+// Watchlists – danh sách tàu cần theo dõi
+//========================
+// Mỗi mục theo dõi theo MMSI hoặc VesselID. Khi tàu xuất hiện (AIS, radar,
+// báo cáo thủ công) hệ thống tạo cảnh báo và gửi tới HQ theo thời gian thực.
+
+type Watchlist struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	CreatedBy   int    `json:"created_by"`
 	CreatedAt   int64  `json:"created_at"`
 	UpdatedAt   int64  `json:"updated_at"`
 }
+
+type WatchlistEntry struct {
+	ID          uint   `json:"id"`
+	WatchlistID uint   `json:"watchlist_id"`
+	MMSI        string `json:"mmsi,omitempty"`
+	VesselID    uint   `json:"vessel_id,omitempty"`
+	Reason      string `json:"reason"`
+	ExpiresAt   *int64 `json:"expires_at,omitempty"`    // Hết hạn theo dõi (unix), nil = vô thời hạn
+	LastAlertAt *int64 `json:"last_alert_at,omitempty"` // Lần cảnh báo gần nhất
+	AddedBy     int    `json:"added_by"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// Sighting sources
+const (
+	SightingAIS    = "AIS"
+	SightingRadar  = "RADAR"
+	SightingManual = "MANUAL"
+)
+
+type WatchlistAlert struct {
+	ID          uint    `json:"id"`
+	WatchlistID uint    `json:"watchlist_id"`
+	EntryID     uint    `json:"entry_id"`
+	VesselID    uint    `json:"vessel_id,omitempty"`
+	MMSI        string  `json:"mmsi,omitempty"`
+	Source      string  `json:"source"` // AIS / RADAR / MANUAL
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+	Note        string  `json:"note,omitempty"`
+	ReportedBy  int     `json:"reported_by,omitempty"` // Người báo cáo (nhập tay)
+	SightedAt   int64   `json:"sighted_at"`
+	CreatedAt   int64   `json:"created_at"`
+}
+
+//========================
+// Station Health – tín hiệu sống (heartbeat) từ agent tại trạm
+//========================
+// Agent gửi heartbeat định kỳ. Bộ giám sát đánh dấu trạm DEGRADED khi
+// heartbeat trễ hoặc báo lỗi, OFFLINE khi mất heartbeat hoặc máy phát tắt.
+// Trạng thái hiển thị của trạm = lịch trực + tình trạng thiết bị.
+
+// Station statuses
+const (
+	StationActive   = "ACTIVE"   // Đang trong lịch trực, thiết bị bình thường
+	StationInactive = "INACTIVE" // Ngoài lịch trực
+	StationDegraded = "DEGRADED" // Trong lịch trực nhưng thiết bị có lỗi / heartbeat trễ
+	StationOffline  = "OFFLINE"  // Trong lịch trực nhưng mất heartbeat / máy phát tắt
+
+	StationMaintenance  = "MAINTENANCE"    // Đặt tay: đang bảo dưỡng
+	StationOutOfService = "OUT_OF_SERVICE" // Đặt tay: ngừng hoạt động
+)
+
+// Health states
+const (
+	HealthUnknown  = "UNKNOWN" // Chưa từng nhận heartbeat (trạm chưa lắp agent)
+	HealthOK       = "OK"
+	HealthDegraded = "DEGRADED"
+	HealthOffline  = "OFFLINE"
+)
+
+type Heartbeat struct {
+	StationID     uint     `json:"station_id"`
+	TransmitterOn bool     `json:"transmitter_on"`        // Máy phát đang bật
+	Uptime        int64    `json:"uptime"`                // giây kể từ khi khởi động
+	Temperature   float64  `json:"temperature"`           // °C
+	ErrorCodes    []string `json:"error_codes,omitempty"` // Mã lỗi thiết bị
+	ReceivedAt    int64    `json:"received_at"`           // Thời điểm server nhận
+}
+
+type StationHealth struct {
+	StationID     uint             `json:"station_id"`
+	Health        string           `json:"health"` // UNKNOWN / OK / DEGRADED / OFFLINE
+	Reason        string           `json:"reason,omitempty"`
+	Status        string           `json:"status"` // Trạng thái tổng hợp (lịch + thiết bị)
+	LastHeartbeat *Heartbeat       `json:"last_heartbeat,omitempty"`
+	Override      *StationOverride `json:"override,omitempty"` // Trạng thái đặt tay đang có hiệu lực
+	Since         int64            `json:"since"`              // Thời điểm chuyển sang Status hiện tại
+}
+
+// Lịch sử thay đổi trạng thái tổng hợp của trạm
+type StationStatusChange struct {
+	ID        uint   `json:"id"`
+	StationID uint   `json:"station_id"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Reason    string `json:"reason,omitempty"`
+	Override  string `json:"override,omitempty"` // Trạng thái đặt tay có hiệu lực sau thay đổi
+	ChangedAt int64  `json:"changed_at"`
+}
+
+//========================
+// Station Override – trạng thái đặt tay
+//========================
+// Người dùng có thể đặt tay trạng thái trạm (bảo dưỡng, ngừng hoạt động,
+// buộc hoạt động). Trạng thái đặt tay được ưu tiên hơn lịch trực và
+// heartbeat cho đến khi hết hạn hoặc bị gỡ.
+
+// Override states
+const (
+	OverrideMaintenance  = "MAINTENANCE"    // Trạm hiển thị MAINTENANCE
+	OverrideOutOfService = "OUT_OF_SERVICE" // Trạm hiển thị OUT_OF_SERVICE
+	OverrideForcedActive = "FORCED_ACTIVE"  // Trạm hiển thị ACTIVE bất kể lịch trực
+)
+
+type StationOverride struct {
+	StationID uint   `json:"station_id"`
+	State     string `json:"state"`  // MAINTENANCE / OUT_OF_SERVICE / FORCED_ACTIVE
+	Reason    string `json:"reason"` // Bắt buộc
+	SetBy     string `json:"set_by"` // Username người đặt
+	SetAt     int64  `json:"set_at"`
+	ExpiresAt *int64 `json:"expires_at,omitempty"` // nil = đến khi gỡ
+}
+
+//========================
+// Equipment – thiết bị tại trạm và nhật ký bảo dưỡng
+//========================
+// Mỗi trạm có radar, máy phát điện, thiết bị thông tin, UPS...
+// Mỗi lần bảo dưỡng ghi một MaintenanceRecord; hạn bảo dưỡng kế tiếp của
+// thiết bị lấy theo bản ghi mới nhất.
+
+// Equipment types
+const (
+	EquipmentRadar     = "RADAR"
+	EquipmentGenerator = "GENERATOR" // Máy phát điện
+	EquipmentComms     = "COMMS"     // Thiết bị thông tin liên lạc
+	EquipmentUPS       = "UPS"
+	EquipmentOther     = "OTHER"
+)
+
+// Equipment statuses
+const (
+	EquipmentOperational = "OPERATIONAL" // Hoạt động bình thường
+	EquipmentFaulty      = "FAULTY"      // Hỏng, chờ sửa
+	EquipmentUnderRepair = "UNDER_REPAIR"
+	EquipmentRetired     = "RETIRED" // Đã thanh lý, không theo dõi bảo dưỡng
+)
+
+type Equipment struct {
+	ID          uint   `json:"id"`
+	StationID   uint   `json:"station_id"`
+	Type        string `json:"type"` // RADAR / GENERATOR / COMMS / UPS / OTHER
+	Model       string `json:"model"`
+	Serial      string `json:"serial,omitempty"`
+	InstalledAt int64  `json:"installed_at,omitempty"` // Ngày lắp đặt
+	Status      string `json:"status"`                 // OPERATIONAL / FAULTY / UNDER_REPAIR / RETIRED
+	Note        string `json:"note,omitempty"`
+
+	LastMaintainedAt *int64 `json:"last_maintained_at,omitempty"` // Lần bảo dưỡng gần nhất
+	NextDueAt        *int64 `json:"next_due_at,omitempty"`        // Hạn bảo dưỡng kế tiếp
+
+	CreatedAt int64 `json:"created_at"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+type MaintenanceRecord struct {
+	ID          uint     `json:"id"`
+	EquipmentID uint     `json:"equipment_id"`
+	StationID   uint     `json:"station_id"`
+	PerformedBy string   `json:"performed_by"` // Người thực hiện
+	PerformedAt int64    `json:"performed_at"` // Ngày thực hiện
+	WorkDone    string   `json:"work_done"`    // Nội dung công việc
+	Parts       []string `json:"parts,omitempty"`
+	NextDueAt   *int64   `json:"next_due_at,omitempty"` // Hạn bảo dưỡng kế tiếp
+	RecordedBy  string   `json:"recorded_by"`           // Username người nhập
+	CreatedAt   int64    `json:"created_at"`
+}
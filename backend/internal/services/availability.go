@@ -0,0 +1,222 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// StatusInterval is a period during which a station kept the same status.
+type StatusInterval struct {
+	From   int64  `json:"from"`
+	To     int64  `json:"to"`
+	Status string `json:"status"` // "" when no status had been recorded yet
+	Reason string `json:"reason,omitempty"`
+}
+
+// Availability compares the time a station actually operated with its
+// schedule over a period. Operating means ACTIVE or DEGRADED; INACTIVE (off
+// schedule) is neither operating nor an outage; any other status is an
+// outage. Operation outside the schedule (a forced or unplanned watch) does
+// not count towards the uptime; it is reported as off-schedule hours.
+type Availability struct {
+	StationID        uint             `json:"station_id"`
+	Name             string           `json:"name,omitempty"`
+	From             int64            `json:"from"`
+	To               int64            `json:"to"`
+	ScheduledHours   float64          `json:"scheduled_hours"`
+	OperatedHours    float64          `json:"operated_hours"`
+	OnScheduleHours  float64          `json:"on_schedule_hours"` // operated while on schedule
+	DegradedHours    float64          `json:"degraded_hours"`
+	OutageHours      float64          `json:"outage_hours"`
+	UntrackedHours   float64          `json:"untracked_hours"`          // before the first recorded status
+	UptimePercent    *float64         `json:"uptime_percent,omitempty"` // on schedule / scheduled; omitted when nothing was scheduled
+	DeviationHours   float64          `json:"deviation_hours"`          // operated - scheduled = off_schedule_hours - missed_hours
+	MissedHours      float64          `json:"missed_hours"`             // scheduled but not operated
+	OffScheduleHours float64          `json:"off_schedule_hours"`       // operated while off schedule
+	Outages          []StatusInterval `json:"outages"`
+}
+
+// FleetAvailability sums up the availability of all stations.
+type FleetAvailability struct {
+	From             int64          `json:"from"`
+	To               int64          `json:"to"`
+	ScheduledHours   float64        `json:"scheduled_hours"`
+	OperatedHours    float64        `json:"operated_hours"`
+	OnScheduleHours  float64        `json:"on_schedule_hours"`
+	OutageHours      float64        `json:"outage_hours"`
+	UptimePercent    *float64       `json:"uptime_percent,omitempty"`
+	DeviationHours   float64        `json:"deviation_hours"`
+	MissedHours      float64        `json:"missed_hours"`
+	OffScheduleHours float64        `json:"off_schedule_hours"`
+	Stations         []Availability `json:"stations"`
+}
+
+func isOperating(status string) bool {
+	return status == models.StationActive || status == models.StationDegraded
+}
+
+func isOutage(status string) bool {
+	return status != "" && status != models.StationInactive && !isOperating(status)
+}
+
+func hours(seconds int64) float64 { return float64(seconds) / 3600 }
+
+func uptimePercent(onSchedule, scheduled float64) *float64 {
+	if scheduled <= 0 {
+		return nil
+	}
+	pct := onSchedule / scheduled * 100
+	return &pct
+}
+
+// overlap returns the seconds of [from, to) that fall within the sorted,
+// non-overlapping spans.
+func overlap(from, to int64, spans [][2]int64) int64 {
+	var d int64
+	for _, sp := range spans {
+		if sp[0] >= to {
+			break
+		}
+		d += max(0, min(to, sp[1])-max(from, sp[0]))
+	}
+	return d
+}
+
+// changes returns the logged status changes of a station, oldest first.
+func (s *HealthService) changes(stationID uint) ([]models.StationStatusChange, error) {
+	var out []models.StationStatusChange
+	err := s.db.IteratePrefix(fmt.Sprintf("station_status_log:%d:", stationID), func(_ string, val []byte) error {
+		var ch models.StationStatusChange
+		if err := json.Unmarshal(val, &ch); err != nil {
+			return nil // Skip invalid records
+		}
+		out = append(out, ch)
+		return nil
+	})
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, err
+}
+
+// Timeline returns the status of a station over [from, to) as consecutive
+// intervals rebuilt from the status log. Time before the first logged change
+// has an empty status.
+func (s *HealthService) Timeline(stationID uint, from, to int64) ([]StatusInterval, error) {
+	if from >= to {
+		return nil, errors.New("from must be before to")
+	}
+	entries, err := s.changes(stationID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []StatusInterval{}
+	cur := StatusInterval{From: from}
+	for _, ch := range entries {
+		if ch.ChangedAt <= from {
+			cur.Status, cur.Reason = ch.To, ch.Reason
+			continue
+		}
+		if ch.ChangedAt >= to {
+			break
+		}
+		if ch.To == cur.Status {
+			continue
+		}
+		cur.To = ch.ChangedAt
+		if cur.To > cur.From {
+			out = append(out, cur)
+		}
+		cur = StatusInterval{From: ch.ChangedAt, Status: ch.To, Reason: ch.Reason}
+	}
+	cur.To = to
+	return append(out, cur), nil
+}
+
+// Availability reports how long a station operated over [from, to) compared
+// with its schedule.
+func (s *HealthService) Availability(stationID uint, from, to int64) (*Availability, error) {
+	if err := s.checkStation(stationID); err != nil {
+		return nil, err
+	}
+	timeline, err := s.Timeline(stationID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	scheduled, err := s.schedSvc.ScheduledIntervals(stationID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Availability{StationID: stationID, From: from, To: to, Outages: []StatusInterval{}}
+	for _, sp := range scheduled {
+		a.ScheduledHours += hours(sp[1] - sp[0])
+	}
+	for _, iv := range timeline {
+		d := hours(iv.To - iv.From)
+		switch {
+		case iv.Status == "":
+			a.UntrackedHours += d
+		case isOperating(iv.Status):
+			a.OperatedHours += d
+			a.OnScheduleHours += hours(overlap(iv.From, iv.To, scheduled))
+			if iv.Status == models.StationDegraded {
+				a.DegradedHours += d
+			}
+		case isOutage(iv.Status):
+			a.OutageHours += d
+			a.Outages = append(a.Outages, iv)
+		}
+	}
+	a.UptimePercent = uptimePercent(a.OnScheduleHours, a.ScheduledHours)
+	a.MissedHours = a.ScheduledHours - a.OnScheduleHours
+	a.OffScheduleHours = a.OperatedHours - a.OnScheduleHours
+	a.DeviationHours = a.OperatedHours - a.ScheduledHours
+
+	var st models.Station
+	if err := s.db.GetJSON(fmt.Sprintf("station:%d", stationID), &st); err == nil {
+		a.Name = st.Name
+	}
+	return a, nil
+}
+
+// FleetAvailability reports the availability of every station in scope (all
+// stations when scope is nil) over [from, to) together with fleet-wide
+// totals.
+func (s *HealthService) FleetAvailability(from, to int64, scope map[uint]bool) (*FleetAvailability, error) {
+	if from >= to {
+		return nil, errors.New("from must be before to")
+	}
+	var ids []uint
+	if err := s.db.IteratePrefix("station:", func(_ string, val []byte) error {
+		var st models.Station
+		if err := json.Unmarshal(val, &st); err == nil && (scope == nil || scope[st.ID]) {
+			ids = append(ids, st.ID)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	fleet := &FleetAvailability{From: from, To: to, Stations: []Availability{}}
+	for _, id := range ids {
+		a, err := s.Availability(id, from, to)
+		if err != nil {
+			return nil, err
+		}
+		fleet.ScheduledHours += a.ScheduledHours
+		fleet.OperatedHours += a.OperatedHours
+		fleet.OnScheduleHours += a.OnScheduleHours
+		fleet.OutageHours += a.OutageHours
+		fleet.MissedHours += a.MissedHours
+		fleet.OffScheduleHours += a.OffScheduleHours
+		fleet.Stations = append(fleet.Stations, *a)
+	}
+	fleet.UptimePercent = uptimePercent(fleet.OnScheduleHours, fleet.ScheduledHours)
+	fleet.DeviationHours = fleet.OperatedHours - fleet.ScheduledHours
+	return fleet, nil
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+func TestAvailabilityOffSchedule(t *testing.T) {
+	day := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	at := func(h int) int64 { return day.Add(time.Duration(h) * time.Hour).Unix() }
+	type change struct {
+		hour   int
+		status string
+	}
+	tests := []struct {
+		name                                      string
+		log                                       []change
+		operated, onSchedule, missed, off, uptime float64
+	}{
+		{"forced past the schedule",
+			[]change{{6, models.StationActive}, {18, models.StationInactive}},
+			12, 8, 0, 4, 100},
+		{"outage and an unplanned watch",
+			[]change{{10, models.StationActive}, {12, models.StationDegraded}, {13, models.StationOffline}, {20, models.StationActive}, {22, models.StationInactive}},
+			5, 3, 5, 2, 37.5},
+		{"never operated",
+			[]change{{0, models.StationInactive}},
+			0, 0, 8, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			if err := db.PutJSON("station:1", models.Station{ID: 1, Name: "S1", TimeZone: "UTC"}); err != nil {
+				t.Fatal(err)
+			}
+			sched := NewScheduleService(db, nil)
+			if err := sched.Create(&models.Schedule{StationID: 1, StartHHMM: "0800", EndHHMM: "1600"}); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			for i, ch := range tt.log {
+				rec := models.StationStatusChange{ID: uint(i + 1), StationID: 1, To: ch.status, ChangedAt: at(ch.hour)}
+				if err := db.PutJSON(fmt.Sprintf("station_status_log:1:%010d", rec.ID), rec); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			a, err := NewHealthService(db, sched, NewNotifier()).Availability(1, at(0), at(24))
+			if err != nil {
+				t.Fatalf("Availability: %v", err)
+			}
+			if a.ScheduledHours != 8 || a.OperatedHours != tt.operated || a.OnScheduleHours != tt.onSchedule ||
+				a.MissedHours != tt.missed || a.OffScheduleHours != tt.off {
+				t.Errorf("scheduled %v, operated %v, on schedule %v, missed %v, off schedule %v; want 8, %v, %v, %v, %v",
+					a.ScheduledHours, a.OperatedHours, a.OnScheduleHours, a.MissedHours, a.OffScheduleHours, tt.operated, tt.onSchedule, tt.missed, tt.off)
+			}
+			if a.UptimePercent == nil || math.Abs(*a.UptimePercent-tt.uptime) > 1e-9 {
+				t.Errorf("uptime = %v, want %v", a.UptimePercent, tt.uptime)
+			}
+			if a.DeviationHours != a.OffScheduleHours-a.MissedHours {
+				t.Errorf("deviation %v != off schedule %v - missed %v", a.DeviationHours, a.OffScheduleHours, a.MissedHours)
+			}
+		})
+	}
+}
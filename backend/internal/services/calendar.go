@@ -0,0 +1,93 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+const icsTime = "20060102T150405Z"
+
+// icsText escapes a TEXT property value (RFC 5545 section 3.3.11).
+func icsText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", "").Replace(s)
+}
+
+// icsWriter writes content lines ending in CRLF, folded at 75 octets without
+// splitting UTF-8 characters.
+type icsWriter struct {
+	w *bufio.Writer
+}
+
+func (iw icsWriter) line(name, value string) {
+	l, limit := name+":"+value, 75
+	for len(l) > limit {
+		cut := limit
+		for !utf8.RuneStart(l[cut]) {
+			cut--
+		}
+		iw.w.WriteString(l[:cut] + "\r\n ")
+		l, limit = l[cut:], 74 // the leading space counts
+	}
+	iw.w.WriteString(l + "\r\n")
+}
+
+// WriteCalendar renders watches as an iCalendar (RFC 5545) feed named name,
+// one VEVENT per watch. Stations gives the names and positions of the
+// stations the watches belong to.
+func WriteCalendar(w io.Writer, name string, watches []Watch, stations map[uint]models.Station, stamp time.Time) error {
+	bw := bufio.NewWriter(w)
+	iw := icsWriter{w: bw}
+	iw.line("BEGIN", "VCALENDAR")
+	iw.line("VERSION", "2.0")
+	iw.line("PRODID", "-//Radar Hub Manager//Duty Schedules//EN")
+	iw.line("CALSCALE", "GREGORIAN")
+	iw.line("METHOD", "PUBLISH")
+	iw.line("NAME", icsText(name))
+	iw.line("X-WR-CALNAME", icsText(name))
+	iw.line("REFRESH-INTERVAL;VALUE=DURATION", "PT1H")
+	iw.line("X-PUBLISHED-TTL", "PT1H")
+
+	dtstamp := stamp.UTC().Format(icsTime)
+	for _, wt := range watches {
+		st := stations[wt.StationID]
+		stName := st.Name
+		if stName == "" {
+			stName = fmt.Sprintf("Station %d", wt.StationID)
+		}
+
+		summary, uid := "Duty watch – "+stName, fmt.Sprintf("watch-%d-s%d-%d@radar-hub-manager", wt.StationID, wt.ScheduleID, wt.Start)
+		if wt.OverrideID != 0 {
+			summary, uid = "Extra watch – "+stName, fmt.Sprintf("watch-%d-o%d-%d@radar-hub-manager", wt.StationID, wt.OverrideID, wt.Start)
+		}
+		var desc []string
+		for _, f := range [][2]string{{"Commander", wt.Commander}, {"Crew", wt.Crew}, {"Phone", wt.Phone}, {"Reason", wt.Reason}} {
+			if f[1] != "" {
+				desc = append(desc, f[0]+": "+f[1])
+			}
+		}
+
+		iw.line("BEGIN", "VEVENT")
+		iw.line("UID", uid)
+		iw.line("DTSTAMP", dtstamp)
+		iw.line("DTSTART", time.Unix(wt.Start, 0).UTC().Format(icsTime))
+		iw.line("DTEND", time.Unix(wt.End, 0).UTC().Format(icsTime))
+		iw.line("SUMMARY", icsText(summary))
+		if len(desc) > 0 {
+			iw.line("DESCRIPTION", icsText(strings.Join(desc, "\n")))
+		}
+		iw.line("LOCATION", icsText(stName))
+		if st.ID != 0 {
+			iw.line("GEO", fmt.Sprintf("%.6f;%.6f", st.Latitude, st.Longitude))
+		}
+		iw.line("TRANSP", "OPAQUE")
+		iw.line("END", "VEVENT")
+	}
+	iw.line("END", "VCALENDAR")
+	return bw.Flush()
+}
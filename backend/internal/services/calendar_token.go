@@ -0,0 +1,61 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// Calendar clients cannot send a JWT header, so each user gets a long-lived
+// token for calendar feeds, kept at "calendar_token:{token}" (→ username) and
+// "user_calendar_token:{username}" (→ token).
+
+// CalendarToken returns the user's calendar feed token, creating one on
+// first use.
+func (s *UserService) CalendarToken(username string) (string, error) {
+	var token string
+	if err := s.db.GetJSON("user_calendar_token:"+username, &token); err == nil {
+		return token, nil
+	}
+	return s.RotateCalendarToken(username)
+}
+
+// RotateCalendarToken replaces the user's calendar feed token; the old one
+// stops working.
+func (s *UserService) RotateCalendarToken(username string) (string, error) {
+	if _, err := s.GetByUsername(username); err != nil {
+		return "", err
+	}
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	s.revokeCalendarToken(username)
+	if err := s.db.PutJSON("calendar_token:"+token, username); err != nil {
+		return "", err
+	}
+	if err := s.db.PutJSON("user_calendar_token:"+username, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *UserService) revokeCalendarToken(username string) {
+	var old string
+	if err := s.db.GetJSON("user_calendar_token:"+username, &old); err == nil {
+		s.db.Delete("calendar_token:" + old) // Ignore error
+		s.db.Delete("user_calendar_token:" + username)
+	}
+}
+
+// GetUserFromCalendarToken returns the user a calendar feed token belongs to.
+func (s *UserService) GetUserFromCalendarToken(token string) (*models.User, error) {
+	var username string
+	if token == "" || s.db.GetJSON("calendar_token:"+token, &username) != nil {
+		return nil, errors.New("invalid calendar token")
+	}
+	return s.GetByUsername(username)
+}
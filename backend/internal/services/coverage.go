@@ -0,0 +1,229 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// ErrInvalidRadar is returned (wrapped) for out-of-range radar parameters.
+var ErrInvalidRadar = errors.New("invalid radar parameters")
+
+const (
+	// DefaultTargetHeight is the assumed height of a surface target (m).
+	DefaultTargetHeight = 10.0
+
+	maxRadarRangeKm  = 500.0
+	maxAntennaHeight = 2000.0
+
+	// horizonFactor gives the radar horizon in km as
+	// horizonFactor * (sqrt(h_antenna) + sqrt(h_target)) with heights in
+	// metres, using the standard 4/3 effective Earth radius.
+	horizonFactor = 4.12
+
+	coverageStepDeg = 2.0
+)
+
+// StationCoverage describes how a station sees a point.
+type StationCoverage struct {
+	models.Station
+	DistanceKm       float64 `json:"distance_km"`
+	Bearing          float64 `json:"bearing"` // from the station to the point, degrees
+	EffectiveRangeKm float64 `json:"effective_range_km"`
+}
+
+func validateRadar(r *models.RadarParams) error {
+	if r == nil {
+		return nil
+	}
+	if r.RangeKm <= 0 || r.RangeKm > maxRadarRangeKm {
+		return fmt.Errorf("%w: range_km must be between 0 and %g", ErrInvalidRadar, maxRadarRangeKm)
+	}
+	if r.AntennaHeight < 0 || r.AntennaHeight > maxAntennaHeight {
+		return fmt.Errorf("%w: antenna_height must be between 0 and %g", ErrInvalidRadar, maxAntennaHeight)
+	}
+	validArc := func(a models.Arc) bool {
+		return a.From >= 0 && a.From < 360 && a.To >= 0 && a.To < 360 && a.From != a.To
+	}
+	if r.Sector != nil && !validArc(*r.Sector) {
+		return fmt.Errorf("%w: sector angles must be distinct and in [0, 360)", ErrInvalidRadar)
+	}
+	for _, a := range r.BlindArcs {
+		if !validArc(a) {
+			return fmt.Errorf("%w: blind arc angles must be distinct and in [0, 360)", ErrInvalidRadar)
+		}
+	}
+	return nil
+}
+
+// radioHorizonKm returns the distance to the radar horizon for an antenna
+// and a target at the given heights above sea level (m).
+func radioHorizonKm(antennaHeight, targetHeight float64) float64 {
+	return horizonFactor * (math.Sqrt(math.Max(antennaHeight, 0)) + math.Sqrt(math.Max(targetHeight, 0)))
+}
+
+// effectiveRangeKm is the instrumented range of the station's radar limited
+// by the radio horizon.
+func effectiveRangeKm(st *models.Station, targetHeight float64) float64 {
+	return math.Min(st.Radar.RangeKm, radioHorizonKm(st.Elevation+st.Radar.AntennaHeight, targetHeight))
+}
+
+// inArc reports whether bearing lies on the clockwise arc from a.From to a.To.
+func inArc(bearing float64, a models.Arc) bool {
+	if a.From <= a.To {
+		return bearing >= a.From && bearing <= a.To
+	}
+	return bearing >= a.From || bearing <= a.To
+}
+
+// radarSees reports whether the radar sweeps the given bearing.
+func radarSees(r *models.RadarParams, bearing float64) bool {
+	if r.Sector != nil && !inArc(bearing, *r.Sector) {
+		return false
+	}
+	for _, a := range r.BlindArcs {
+		if inArc(bearing, a) {
+			return false
+		}
+	}
+	return true
+}
+
+// coveredArcs returns the swept bearings as clockwise arcs. To may exceed
+// 360 for an arc through north; a full sweep is the single arc 0→360.
+func coveredArcs(r *models.RadarParams) []models.Arc {
+	edges := []float64{0, 360}
+	if r.Sector != nil {
+		edges = append(edges, r.Sector.From, r.Sector.To)
+	}
+	for _, a := range r.BlindArcs {
+		edges = append(edges, a.From, a.To)
+	}
+	sort.Float64s(edges)
+
+	var arcs []models.Arc
+	for i := 1; i < len(edges); i++ {
+		from, to := edges[i-1], edges[i]
+		if to == from || !radarSees(r, (from+to)/2) {
+			continue
+		}
+		if n := len(arcs); n > 0 && arcs[n-1].To == from {
+			arcs[n-1].To = to
+		} else {
+			arcs = append(arcs, models.Arc{From: from, To: to})
+		}
+	}
+	// Join the arcs meeting at north.
+	if n := len(arcs); n > 1 && arcs[0].From == 0 && arcs[n-1].To == 360 {
+		arcs[0] = models.Arc{From: arcs[n-1].From, To: arcs[0].To + 360}
+		arcs = arcs[:n-1]
+	}
+	return arcs
+}
+
+// coveragePolygon returns the coverage area of a station as a GeoJSON
+// polygon: a circle of the effective range with the unswept bearings cut
+// out back to the station.
+func coveragePolygon(st *models.Station, targetHeight float64) *GeoJSONGeometry {
+	rangeKm := effectiveRangeKm(st, targetHeight)
+	arcs := coveredArcs(st.Radar)
+	center := []float64{st.Longitude, st.Latitude}
+	point := func(bearing float64) []float64 {
+		lat, lon := destinationPoint(st.Latitude, st.Longitude, math.Mod(bearing, 360), rangeKm)
+		return []float64{lon, lat}
+	}
+
+	var ring [][]float64
+	full := len(arcs) == 1 && arcs[0].From == 0 && arcs[0].To == 360
+	for _, a := range arcs {
+		if !full {
+			ring = append(ring, center)
+		}
+		for b := a.From; b < a.To; b += coverageStepDeg {
+			ring = append(ring, point(b))
+		}
+		ring = append(ring, point(a.To))
+	}
+	if len(ring) == 0 {
+		ring = append(ring, center)
+	}
+	if !full {
+		ring = append(ring, center)
+	}
+	// Bearings run clockwise; RFC 7946 wants counter-clockwise exterior rings.
+	for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+		ring[i], ring[j] = ring[j], ring[i]
+	}
+	return &GeoJSONGeometry{Type: "Polygon", Coordinates: [][][]float64{ring}}
+}
+
+func (s *StationService) coverageFeature(st *models.Station, targetHeight float64) *GeoJSONFeature {
+	return newFeature(coveragePolygon(st, targetHeight), map[string]any{
+		"station_id":         st.ID,
+		"name":               st.Name,
+		"status":             st.Status,
+		"range_km":           st.Radar.RangeKm,
+		"effective_range_km": effectiveRangeKm(st, targetHeight),
+		"target_height":      targetHeight,
+	})
+}
+
+// Coverage returns the coverage area of one station as a GeoJSON feature,
+// whether or not the station is on schedule.
+func (s *StationService) Coverage(id uint, targetHeight float64) (*GeoJSONFeature, error) {
+	st, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if st.Radar == nil {
+		return nil, errors.New("station has no radar parameters")
+	}
+	s.withStatus(st)
+	return s.coverageFeature(st, targetHeight), nil
+}
+
+// CoverageMap returns the coverage areas of all stations with radar
+// parameters. With activeOnly, only stations ACTIVE right now (on schedule
+// and healthy) are included.
+func (s *StationService) CoverageMap(targetHeight float64, activeOnly bool) (*GeoJSONFeatureCollection, error) {
+	stations, err := s.ListWithStatus()
+	if err != nil {
+		return nil, err
+	}
+	fc := newFeatureCollection()
+	for i := range stations {
+		st := &stations[i]
+		if st.Radar == nil || (activeOnly && st.Status != models.StationActive) {
+			continue
+		}
+		fc.Features = append(fc.Features, s.coverageFeature(st, targetHeight))
+	}
+	return fc, nil
+}
+
+// CoveringStations returns the stations whose radar covers the point for a
+// target of the given height, nearest first. With activeOnly, only stations
+// ACTIVE right now count.
+func (s *StationService) CoveringStations(lat, lon, targetHeight float64, activeOnly bool) ([]StationCoverage, error) {
+	nearby, err := s.WithinRadius(lat, lon, maxRadarRangeKm, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	out := []StationCoverage{}
+	for _, sd := range nearby {
+		st := sd.Station
+		if st.Radar == nil {
+			continue
+		}
+		rangeKm := effectiveRangeKm(&st, targetHeight)
+		bearing := initialBearing(st.Latitude, st.Longitude, lat, lon)
+		if sd.DistanceKm > rangeKm || (sd.DistanceKm > 0 && !radarSees(st.Radar, bearing)) {
+			continue
+		}
+		out = append(out, StationCoverage{Station: st, DistanceKm: sd.DistanceKm, Bearing: bearing, EffectiveRangeKm: rangeKm})
+	}
+	return out, nil
+}
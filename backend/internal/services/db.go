@@ -5,6 +5,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/opt"
@@ -109,3 +110,40 @@ func (d *DB) Exists(key string) (bool, error) {
 	}
 	return err == nil, err
 }
+
+// counterMu serialises NextID so concurrent creators never share an ID.
+var counterMu sync.Mutex
+
+// NextID increments the persisted counter stored at counterKey (e.g.
+// "watchlist_counter") and returns the new value, starting at 1.
+func (d *DB) NextID(counterKey string) (uint, error) {
+	counterMu.Lock()
+	defer counterMu.Unlock()
+
+	var counter uint
+	if err := d.GetJSON(counterKey, &counter); err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+		return 0, err
+	}
+	counter++
+	if err := d.PutJSON(counterKey, counter); err != nil {
+		return 0, err
+	}
+	return counter, nil
+}
+
+// WriteJSON stores puts (key → value marshalled as JSON) and removes deletes
+// in one atomic batch: either all changes are applied or none.
+func (d *DB) WriteJSON(puts map[string]any, deletes []string) error {
+	batch := new(leveldb.Batch)
+	for _, key := range deletes {
+		batch.Delete([]byte(key))
+	}
+	for key, v := range puts {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		batch.Put([]byte(key), data)
+	}
+	return d.DB.Write(batch, nil)
+}
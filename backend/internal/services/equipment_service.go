@@ -0,0 +1,256 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// ErrInvalidEquipment is returned (wrapped) for malformed equipment or
+// maintenance records.
+var ErrInvalidEquipment = errors.New("invalid equipment")
+
+// MaintenanceDue is an equipment item due or overdue for maintenance.
+type MaintenanceDue struct {
+	models.Equipment
+	StationName string `json:"station_name,omitempty"`
+	Overdue     bool   `json:"overdue"`
+	DaysUntil   int    `json:"days_until"` // negative when overdue
+}
+
+// EquipmentService keeps equipment at "equipment:{id}" and its maintenance
+// records at "maintenance:{equipmentID}:{recordID}".
+type EquipmentService struct {
+	db  *DB
+	now func() time.Time
+}
+
+func NewEquipmentService(db *DB) *EquipmentService {
+	return &EquipmentService{db: db, now: time.Now}
+}
+
+func equipmentKey(id uint) string { return fmt.Sprintf("equipment:%d", id) }
+
+func (s *EquipmentService) validate(eq *models.Equipment) error {
+	switch eq.Type {
+	case models.EquipmentRadar, models.EquipmentGenerator, models.EquipmentComms, models.EquipmentUPS, models.EquipmentOther:
+	default:
+		return fmt.Errorf("%w: type must be RADAR, GENERATOR, COMMS, UPS or OTHER", ErrInvalidEquipment)
+	}
+	switch eq.Status {
+	case models.EquipmentOperational, models.EquipmentFaulty, models.EquipmentUnderRepair, models.EquipmentRetired:
+	default:
+		return fmt.Errorf("%w: status must be OPERATIONAL, FAULTY, UNDER_REPAIR or RETIRED", ErrInvalidEquipment)
+	}
+	eq.Model = strings.TrimSpace(eq.Model)
+	if eq.Model == "" {
+		return fmt.Errorf("%w: model is required", ErrInvalidEquipment)
+	}
+	eq.Serial = strings.TrimSpace(eq.Serial)
+	if eq.Serial == "" {
+		return nil
+	}
+	// Serial numbers identify the physical unit, so they must be unique.
+	var dup bool
+	err := s.db.IteratePrefix("equipment:", func(_ string, val []byte) error {
+		var other models.Equipment
+		if err := json.Unmarshal(val, &other); err == nil && other.ID != eq.ID && strings.EqualFold(other.Serial, eq.Serial) {
+			dup = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if dup {
+		return fmt.Errorf("%w: serial %s is already registered", ErrInvalidEquipment, eq.Serial)
+	}
+	return nil
+}
+
+// Create registers equipment at a station. Status defaults to OPERATIONAL.
+func (s *EquipmentService) Create(eq *models.Equipment) error {
+	if ok, err := s.db.Exists(fmt.Sprintf("station:%d", eq.StationID)); err != nil {
+		return err
+	} else if !ok {
+		return errors.New("station not found")
+	}
+	if eq.Status == "" {
+		eq.Status = models.EquipmentOperational
+	}
+	if err := s.validate(eq); err != nil {
+		return err
+	}
+	id, err := s.db.NextID("equipment_counter")
+	if err != nil {
+		return fmt.Errorf("failed to generate ID: %w", err)
+	}
+	eq.ID = id
+	eq.CreatedAt = s.now().Unix()
+	eq.UpdatedAt = eq.CreatedAt
+	return s.db.PutJSON(equipmentKey(eq.ID), eq)
+}
+
+// GetByID retrieves equipment by ID
+func (s *EquipmentService) GetByID(id uint) (*models.Equipment, error) {
+	var eq models.Equipment
+	if err := s.db.GetJSON(equipmentKey(id), &eq); err != nil {
+		return nil, errors.New("equipment not found")
+	}
+	return &eq, nil
+}
+
+// Update saves changed equipment. The station, maintenance dates and
+// creation time are kept from the stored record.
+func (s *EquipmentService) Update(eq *models.Equipment) error {
+	existing, err := s.GetByID(eq.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validate(eq); err != nil {
+		return err
+	}
+	eq.StationID = existing.StationID
+	eq.LastMaintainedAt = existing.LastMaintainedAt
+	eq.CreatedAt = existing.CreatedAt
+	eq.UpdatedAt = s.now().Unix()
+	return s.db.PutJSON(equipmentKey(eq.ID), eq)
+}
+
+// Delete removes equipment together with its maintenance records.
+func (s *EquipmentService) Delete(id uint) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	var keys []string
+	s.db.IteratePrefix(fmt.Sprintf("maintenance:%d:", id), func(key string, _ []byte) error {
+		keys = append(keys, key)
+		return nil
+	})
+	for _, k := range keys {
+		s.db.Delete(k) // Ignore error
+	}
+	return s.db.Delete(equipmentKey(id))
+}
+
+// List returns all equipment, or that of one station when stationID > 0,
+// ordered by ID.
+func (s *EquipmentService) List(stationID uint) ([]models.Equipment, error) {
+	out := []models.Equipment{}
+	err := s.db.IteratePrefix("equipment:", func(_ string, val []byte) error {
+		var eq models.Equipment
+		if err := json.Unmarshal(val, &eq); err != nil {
+			return nil // Skip invalid records
+		}
+		if stationID == 0 || eq.StationID == stationID {
+			out = append(out, eq)
+		}
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, err
+}
+
+// AddMaintenance logs maintenance work on equipment. When it is the latest
+// record, the equipment's last maintenance and next due dates follow it.
+func (s *EquipmentService) AddMaintenance(rec *models.MaintenanceRecord) error {
+	eq, err := s.GetByID(rec.EquipmentID)
+	if err != nil {
+		return err
+	}
+	rec.PerformedBy = strings.TrimSpace(rec.PerformedBy)
+	rec.WorkDone = strings.TrimSpace(rec.WorkDone)
+	if rec.PerformedBy == "" || rec.WorkDone == "" {
+		return fmt.Errorf("%w: performed_by and work_done are required", ErrInvalidEquipment)
+	}
+	now := s.now().Unix()
+	if rec.PerformedAt == 0 {
+		rec.PerformedAt = now
+	}
+	if rec.PerformedAt > now {
+		return fmt.Errorf("%w: performed_at must not be in the future", ErrInvalidEquipment)
+	}
+	if rec.NextDueAt != nil && *rec.NextDueAt <= rec.PerformedAt {
+		return fmt.Errorf("%w: next_due_at must be after performed_at", ErrInvalidEquipment)
+	}
+
+	id, err := s.db.NextID("maintenance_counter")
+	if err != nil {
+		return fmt.Errorf("failed to generate ID: %w", err)
+	}
+	rec.ID = id
+	rec.StationID = eq.StationID
+	rec.CreatedAt = now
+	if err := s.db.PutJSON(fmt.Sprintf("maintenance:%d:%010d", eq.ID, rec.ID), rec); err != nil {
+		return err
+	}
+
+	if eq.LastMaintainedAt == nil || rec.PerformedAt >= *eq.LastMaintainedAt {
+		eq.LastMaintainedAt = &rec.PerformedAt
+		eq.NextDueAt = rec.NextDueAt
+		eq.UpdatedAt = now
+		return s.db.PutJSON(equipmentKey(eq.ID), eq)
+	}
+	return nil
+}
+
+// MaintenanceLog returns the maintenance records of equipment, most recent
+// first.
+func (s *EquipmentService) MaintenanceLog(equipmentID uint) ([]models.MaintenanceRecord, error) {
+	if _, err := s.GetByID(equipmentID); err != nil {
+		return nil, err
+	}
+	out := []models.MaintenanceRecord{}
+	err := s.db.IteratePrefix(fmt.Sprintf("maintenance:%d:", equipmentID), func(_ string, val []byte) error {
+		var rec models.MaintenanceRecord
+		if err := json.Unmarshal(val, &rec); err != nil {
+			return nil // Skip invalid records
+		}
+		out = append(out, rec)
+		return nil
+	})
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].PerformedAt != out[j].PerformedAt {
+			return out[i].PerformedAt > out[j].PerformedAt
+		}
+		return out[i].ID > out[j].ID
+	})
+	return out, err
+}
+
+// DueForMaintenance returns the equipment in service whose next maintenance
+// is overdue or due within the given number of days, most urgent first.
+// stationID > 0 limits the report to one station.
+func (s *EquipmentService) DueForMaintenance(withinDays int, stationID uint) ([]MaintenanceDue, error) {
+	all, err := s.List(stationID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().Unix()
+	limit := now + int64(withinDays)*86400
+	names := make(map[uint]string)
+
+	out := []MaintenanceDue{}
+	for _, eq := range all {
+		if eq.Status == models.EquipmentRetired || eq.NextDueAt == nil || *eq.NextDueAt > limit {
+			continue
+		}
+		name, ok := names[eq.StationID]
+		if !ok {
+			var st models.Station
+			if err := s.db.GetJSON(fmt.Sprintf("station:%d", eq.StationID), &st); err == nil {
+				name = st.Name
+			}
+			names[eq.StationID] = name
+		}
+		days := (*eq.NextDueAt - now) / 86400
+		out = append(out, MaintenanceDue{Equipment: eq, StationName: name, Overdue: *eq.NextDueAt < now, DaysUntil: int(days)})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return *out[i].NextDueAt < *out[j].NextDueAt })
+	return out, nil
+}
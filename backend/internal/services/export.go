@@ -0,0 +1,245 @@
+package services
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// ExportService turns stations, coverage zones and vessel positions into
+// GeoJSON feature collections; WriteKML renders the same collections as KML
+// so both formats always carry the same data.
+type ExportService struct {
+	stationSvc  *StationService
+	schedSvc    *ScheduleService
+	vesselSvc   *VesselService
+	positionSvc *PositionService
+}
+
+func NewExportService(stationSvc *StationService, schedSvc *ScheduleService, vesselSvc *VesselService, positionSvc *PositionService) *ExportService {
+	return &ExportService{stationSvc: stationSvc, schedSvc: schedSvc, vesselSvc: vesselSvc, positionSvc: positionSvc}
+}
+
+// Stations returns every station as a Point feature with its current status,
+// note and daily schedules.
+func (s *ExportService) Stations() (*GeoJSONFeatureCollection, error) {
+	stations, err := s.stationSvc.ListWithStatus()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(stations, func(i, j int) bool { return stations[i].ID < stations[j].ID })
+
+	fc := newFeatureCollection()
+	for _, st := range stations {
+		schedules, err := s.schedSvc.ListByStation(st.ID)
+		if err != nil {
+			return nil, err
+		}
+		if schedules == nil {
+			schedules = []models.Schedule{}
+		}
+		props := map[string]any{
+			"id":                st.ID,
+			"name":              st.Name,
+			"status":            st.Status,
+			"note":              st.Note,
+			"elevation":         st.Elevation,
+			"distance_to_coast": st.DistanceToCoast,
+			"schedules":         schedules,
+		}
+		if st.Radar != nil {
+			props["radar"] = st.Radar
+		}
+		fc.Features = append(fc.Features, newFeature(pointGeometry(st.Latitude, st.Longitude), props))
+	}
+	return fc, nil
+}
+
+// Coverage returns the radar coverage zones, see StationService.CoverageMap.
+func (s *ExportService) Coverage(targetHeight float64, activeOnly bool) (*GeoJSONFeatureCollection, error) {
+	return s.stationSvc.CoverageMap(targetHeight, activeOnly)
+}
+
+func (s *ExportService) vesselProps(vesselID uint) map[string]any {
+	props := map[string]any{"vessel_id": vesselID}
+	if v, err := s.vesselSvc.GetByID(vesselID); err == nil {
+		props["name"] = v.Name
+		props["mmsi"] = v.MMSI
+		props["call_sign"] = v.CallSign
+		props["flag_country"] = v.FlagCountry
+	}
+	return props
+}
+
+// VesselPositions returns the latest known position of every vessel as a
+// Point feature.
+func (s *ExportService) VesselPositions() (*GeoJSONFeatureCollection, error) {
+	positions, err := s.positionSvc.ListLatest()
+	if err != nil {
+		return nil, err
+	}
+	fc := newFeatureCollection()
+	for _, p := range positions {
+		props := s.vesselProps(p.VesselID)
+		props["course"] = p.Course
+		props["speed"] = p.Speed
+		props["source"] = p.Source
+		props["timestamp"] = p.Timestamp
+		fc.Features = append(fc.Features, newFeature(pointGeometry(p.Latitude, p.Longitude), props))
+	}
+	return fc, nil
+}
+
+// VesselTrack returns the track of a vessel between from and to (Unix
+// seconds, 0 = open) as a single LineString feature; the report times are in
+// the "timestamps" property, one per vertex.
+func (s *ExportService) VesselTrack(vesselID uint, from, to int64) (*GeoJSONFeatureCollection, error) {
+	if _, err := s.vesselSvc.GetByID(vesselID); err != nil {
+		return nil, err
+	}
+	track, err := s.positionSvc.Track(vesselID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	fc := newFeatureCollection()
+	coords := make([][]float64, len(track))
+	times := make([]int64, len(track))
+	for i, p := range track {
+		coords[i] = []float64{p.Longitude, p.Latitude}
+		times[i] = p.Timestamp
+	}
+	props := s.vesselProps(vesselID)
+	props["timestamps"] = times
+	switch len(track) {
+	case 0:
+		return fc, nil
+	case 1:
+		// A LineString needs two positions.
+		props["timestamp"] = track[0].Timestamp
+		fc.Features = append(fc.Features, newFeature(pointGeometry(track[0].Latitude, track[0].Longitude), props))
+	default:
+		fc.Features = append(fc.Features, newFeature(&GeoJSONGeometry{Type: "LineString", Coordinates: coords}, props))
+	}
+	return fc, nil
+}
+
+//========================
+// KML 2.2
+//========================
+
+type kmlDoc struct {
+	XMLName  xml.Name `xml:"kml"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	Document kmlDocument
+}
+
+type kmlDocument struct {
+	XMLName    xml.Name `xml:"Document"`
+	Name       string   `xml:"name"`
+	Placemarks []kmlPlacemark
+}
+
+type kmlPlacemark struct {
+	XMLName      xml.Name      `xml:"Placemark"`
+	Name         string        `xml:"name,omitempty"`
+	TimeStamp    *kmlTimeStamp `xml:"TimeStamp,omitempty"`
+	ExtendedData []kmlData     `xml:"ExtendedData>Data"`
+	Point        *kmlPoint     `xml:"Point,omitempty"`
+	LineString   *kmlPoint     `xml:"LineString,omitempty"`
+	Polygon      *kmlPolygon   `xml:"Polygon,omitempty"`
+}
+
+type kmlTimeStamp struct {
+	When string `xml:"when"`
+}
+
+type kmlData struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlPolygon struct {
+	Outer string `xml:"outerBoundaryIs>LinearRing>coordinates"`
+}
+
+func kmlCoords(coords [][]float64) string {
+	parts := make([]string, len(coords))
+	for i, c := range coords {
+		parts[i] = strconv.FormatFloat(c[0], 'f', -1, 64) + "," + strconv.FormatFloat(c[1], 'f', -1, 64)
+	}
+	return strings.Join(parts, " ")
+}
+
+// kmlValue renders a property value; structured values are JSON-encoded.
+func kmlValue(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case nil:
+		return ""
+	case fmt.Stringer:
+		return x.String()
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// WriteKML renders a feature collection as a KML 2.2 document. Each feature
+// becomes a Placemark named after its "name" property, with all properties
+// as ExtendedData and, when a "timestamp" property is present, a TimeStamp.
+func WriteKML(w io.Writer, name string, fc *GeoJSONFeatureCollection) error {
+	doc := kmlDoc{XMLNS: "http://www.opengis.net/kml/2.2", Document: kmlDocument{Name: name}}
+	for _, f := range fc.Features {
+		pm := kmlPlacemark{Name: kmlValue(f.Properties["name"])}
+		keys := make([]string, 0, len(f.Properties))
+		for k := range f.Properties {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			pm.ExtendedData = append(pm.ExtendedData, kmlData{Name: k, Value: kmlValue(f.Properties[k])})
+		}
+		if ts, ok := f.Properties["timestamp"].(int64); ok && ts > 0 {
+			pm.TimeStamp = &kmlTimeStamp{When: time.Unix(ts, 0).UTC().Format(time.RFC3339)}
+		}
+
+		switch c := f.Geometry.Coordinates.(type) {
+		case []float64:
+			pm.Point = &kmlPoint{Coordinates: kmlCoords([][]float64{c})}
+		case [][]float64:
+			pm.LineString = &kmlPoint{Coordinates: kmlCoords(c)}
+		case [][][]float64:
+			if len(c) > 0 {
+				pm.Polygon = &kmlPolygon{Outer: kmlCoords(c[0])}
+			}
+		default:
+			return fmt.Errorf("unsupported geometry %s", f.Geometry.Type)
+		}
+		doc.Document.Placemarks = append(doc.Document.Placemarks, pm)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
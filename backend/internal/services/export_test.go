@@ -0,0 +1,316 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+const kmlNS = "http://www.opengis.net/kml/2.2"
+
+// Longitudes above 90 cannot be latitudes, so a swapped pair shows up.
+const (
+	testLat = 10.35
+	testLon = 107.08
+)
+
+func newTestExport(t *testing.T) (*ExportService, uint) {
+	t.Helper()
+	db := newTestDB(t)
+	sched := NewScheduleService(db, nil)
+	station := NewStationService(db, sched, NewHealthService(db, sched, NewNotifier()))
+	vessels := NewVesselService(db)
+	positions := NewPositionService(db, vessels)
+
+	st := models.Station{ID: 1, Name: "Vung Tau", Latitude: testLat, Longitude: testLon, Elevation: 50,
+		Radar: &models.RadarParams{AntennaHeight: 20, RangeKm: 40, Sector: &models.Arc{From: 300, To: 120}}}
+	if err := db.PutJSON("station:1", st); err != nil {
+		t.Fatal(err)
+	}
+	v := &models.Vessel{Name: "Hai Au", MMSI: "574123456"}
+	if err := vessels.Create(v); err != nil {
+		t.Fatalf("Create vessel: %v", err)
+	}
+	for i, ts := range []int64{1760000000, 1760000600, 1760001200} {
+		p := &models.VesselPosition{VesselID: v.ID, Latitude: testLat - 0.1*float64(i), Longitude: testLon + 0.1, Timestamp: ts}
+		if err := positions.Report(p); err != nil {
+			t.Fatalf("Report: %v", err)
+		}
+	}
+	return NewExportService(station, sched, vessels, positions), v.ID
+}
+
+// exportCollections returns every export as name → collection.
+func exportCollections(t *testing.T) map[string]*GeoJSONFeatureCollection {
+	t.Helper()
+	svc, vesselID := newTestExport(t)
+	fcs := make(map[string]*GeoJSONFeatureCollection)
+	var err error
+	if fcs["stations"], err = svc.Stations(); err != nil {
+		t.Fatalf("Stations: %v", err)
+	}
+	if fcs["coverage"], err = svc.Coverage(DefaultTargetHeight, false); err != nil {
+		t.Fatalf("Coverage: %v", err)
+	}
+	if fcs["positions"], err = svc.VesselPositions(); err != nil {
+		t.Fatalf("VesselPositions: %v", err)
+	}
+	if fcs["track"], err = svc.VesselTrack(vesselID, 0, 0); err != nil {
+		t.Fatalf("VesselTrack: %v", err)
+	}
+	return fcs
+}
+
+// checkPosition checks an RFC 7946 position: [lon, lat] near the test site.
+func checkPosition(t *testing.T, where string, p []float64) {
+	t.Helper()
+	if len(p) != 2 {
+		t.Errorf("%s: position %v has %d elements, want 2", where, p, len(p))
+		return
+	}
+	if p[0] < testLon-1 || p[0] > testLon+1 || p[1] < testLat-1 || p[1] > testLat+1 {
+		t.Errorf("%s: position %v is not [lon, lat] of the test site", where, p)
+	}
+}
+
+func TestExportGeoJSON(t *testing.T) {
+	for name, fc := range exportCollections(t) {
+		b, err := json.Marshal(fc)
+		if err != nil {
+			t.Fatalf("%s: Marshal: %v", name, err)
+		}
+		var doc struct {
+			Type     string `json:"type"`
+			Features []struct {
+				Type     string `json:"type"`
+				Geometry struct {
+					Type        string          `json:"type"`
+					Coordinates json.RawMessage `json:"coordinates"`
+				} `json:"geometry"`
+				Properties map[string]any `json:"properties"`
+			} `json:"features"`
+		}
+		if err := json.Unmarshal(b, &doc); err != nil {
+			t.Fatalf("%s: Unmarshal: %v", name, err)
+		}
+		if doc.Type != "FeatureCollection" {
+			t.Errorf("%s: type = %q, want FeatureCollection", name, doc.Type)
+		}
+		if len(doc.Features) == 0 {
+			t.Errorf("%s: no features", name)
+		}
+		for i, f := range doc.Features {
+			where := name + " feature " + strconv.Itoa(i)
+			if f.Type != "Feature" {
+				t.Errorf("%s: type = %q, want Feature", where, f.Type)
+			}
+			if f.Properties == nil {
+				t.Errorf("%s: properties is not an object", where)
+			}
+			switch f.Geometry.Type {
+			case "Point":
+				var p []float64
+				if err := json.Unmarshal(f.Geometry.Coordinates, &p); err != nil {
+					t.Fatalf("%s: Point coordinates: %v", where, err)
+				}
+				checkPosition(t, where, p)
+			case "LineString":
+				var line [][]float64
+				if err := json.Unmarshal(f.Geometry.Coordinates, &line); err != nil {
+					t.Fatalf("%s: LineString coordinates: %v", where, err)
+				}
+				if len(line) < 2 {
+					t.Errorf("%s: LineString has %d positions, want at least 2", where, len(line))
+				}
+				for _, p := range line {
+					checkPosition(t, where, p)
+				}
+			case "Polygon":
+				var rings [][][]float64
+				if err := json.Unmarshal(f.Geometry.Coordinates, &rings); err != nil {
+					t.Fatalf("%s: Polygon coordinates: %v", where, err)
+				}
+				if len(rings) == 0 {
+					t.Fatalf("%s: Polygon has no rings", where)
+				}
+				checkRing(t, where, rings[0])
+			default:
+				t.Errorf("%s: unexpected geometry type %q", where, f.Geometry.Type)
+			}
+		}
+	}
+}
+
+// checkRing checks an exterior linear ring: at least four positions, closed,
+// counter-clockwise.
+func checkRing(t *testing.T, where string, ring [][]float64) {
+	t.Helper()
+	if len(ring) < 4 {
+		t.Fatalf("%s: ring has %d positions, want at least 4", where, len(ring))
+	}
+	first, last := ring[0], ring[len(ring)-1]
+	if first[0] != last[0] || first[1] != last[1] {
+		t.Errorf("%s: ring is not closed: %v ... %v", where, first, last)
+	}
+	var area float64 // shoelace, positive when counter-clockwise
+	for i, p := range ring {
+		checkPosition(t, where, p)
+		if i > 0 {
+			q := ring[i-1]
+			area += q[0]*p[1] - p[0]*q[1]
+		}
+	}
+	if area <= 0 {
+		t.Errorf("%s: exterior ring is clockwise", where)
+	}
+}
+
+func TestCoveragePolygonRings(t *testing.T) {
+	radars := map[string]*models.RadarParams{
+		"full sweep":          {AntennaHeight: 20, RangeKm: 40},
+		"sector through N":    {AntennaHeight: 20, RangeKm: 40, Sector: &models.Arc{From: 300, To: 60}},
+		"blind arc":           {AntennaHeight: 20, RangeKm: 40, BlindArcs: []models.Arc{{From: 90, To: 135}}},
+		"sector with blind":   {AntennaHeight: 20, RangeKm: 40, Sector: &models.Arc{From: 0, To: 180}, BlindArcs: []models.Arc{{From: 60, To: 80}}},
+		"blind arc through N": {AntennaHeight: 20, RangeKm: 40, BlindArcs: []models.Arc{{From: 350, To: 10}}},
+	}
+	for name, r := range radars {
+		st := &models.Station{Latitude: testLat, Longitude: testLon, Radar: r}
+		g := coveragePolygon(st, DefaultTargetHeight)
+		if g.Type != "Polygon" {
+			t.Errorf("%s: type = %q, want Polygon", name, g.Type)
+			continue
+		}
+		rings, ok := g.Coordinates.([][][]float64)
+		if !ok || len(rings) != 1 {
+			t.Errorf("%s: coordinates = %T, want one ring", name, g.Coordinates)
+			continue
+		}
+		checkRing(t, name, rings[0])
+	}
+}
+
+// kmlPlacemarkOrder is the order of the Placemark children the export uses,
+// as the KML 2.2 schema requires.
+var kmlPlacemarkOrder = map[string]int{"name": 0, "TimeStamp": 1, "ExtendedData": 2, "Point": 3, "LineString": 3, "Polygon": 3}
+
+func TestWriteKML(t *testing.T) {
+	for name, fc := range exportCollections(t) {
+		var buf bytes.Buffer
+		if err := WriteKML(&buf, name, fc); err != nil {
+			t.Fatalf("%s: WriteKML: %v", name, err)
+		}
+		if !strings.HasPrefix(buf.String(), xml.Header) {
+			t.Errorf("%s: missing XML declaration", name)
+		}
+
+		// Walk the elements: every one in the KML namespace, Document the
+		// only child of kml, and the Placemark children in schema order.
+		dec := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+		var path []string
+		placemarks, last := 0, -1
+		for {
+			tok, err := dec.Token()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: invalid XML: %v", name, err)
+			}
+			switch el := tok.(type) {
+			case xml.StartElement:
+				if el.Name.Space != kmlNS {
+					t.Errorf("%s: <%s> in namespace %q, want %q", name, el.Name.Local, el.Name.Space, kmlNS)
+				}
+				parent := strings.Join(path, "/")
+				switch parent {
+				case "":
+					if el.Name.Local != "kml" {
+						t.Errorf("%s: root is <%s>, want <kml>", name, el.Name.Local)
+					}
+				case "kml":
+					if el.Name.Local != "Document" {
+						t.Errorf("%s: <kml> child <%s>, want <Document>", name, el.Name.Local)
+					}
+				case "kml/Document":
+					if el.Name.Local == "Placemark" {
+						placemarks++
+						last = -1
+					} else if el.Name.Local != "name" || placemarks > 0 {
+						t.Errorf("%s: unexpected <Document> child <%s>", name, el.Name.Local)
+					}
+				case "kml/Document/Placemark":
+					pos, ok := kmlPlacemarkOrder[el.Name.Local]
+					if !ok {
+						t.Errorf("%s: unexpected <Placemark> child <%s>", name, el.Name.Local)
+					} else if pos <= last {
+						t.Errorf("%s: <%s> out of order in <Placemark>", name, el.Name.Local)
+					}
+					last = pos
+				}
+				path = append(path, el.Name.Local)
+			case xml.EndElement:
+				path = path[:len(path)-1]
+			}
+		}
+		if placemarks != len(fc.Features) {
+			t.Errorf("%s: %d placemarks, want %d", name, placemarks, len(fc.Features))
+		}
+
+		var doc struct {
+			XMLName  xml.Name `xml:"http://www.opengis.net/kml/2.2 kml"`
+			Document struct {
+				Name       string `xml:"name"`
+				Placemarks []struct {
+					Name        string   `xml:"name"`
+					When        string   `xml:"TimeStamp>when"`
+					Data        []string `xml:"ExtendedData>Data>value"`
+					Point       string   `xml:"Point>coordinates"`
+					LineString  string   `xml:"LineString>coordinates"`
+					PolygonRing string   `xml:"Polygon>outerBoundaryIs>LinearRing>coordinates"`
+				} `xml:"Placemark"`
+			} `xml:"Document"`
+		}
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: Unmarshal: %v", name, err)
+		}
+		if doc.Document.Name != name {
+			t.Errorf("%s: document name = %q", name, doc.Document.Name)
+		}
+		for i, pm := range doc.Document.Placemarks {
+			where := name + " placemark " + strconv.Itoa(i)
+			if len(pm.Data) != len(fc.Features[i].Properties) {
+				t.Errorf("%s: %d ExtendedData values, want %d", where, len(pm.Data), len(fc.Features[i].Properties))
+			}
+			if _, ok := fc.Features[i].Properties["timestamp"]; ok && pm.When == "" {
+				t.Errorf("%s: missing TimeStamp", where)
+			}
+			coords := strings.Fields(pm.Point + pm.LineString + pm.PolygonRing)
+			if len(coords) == 0 {
+				t.Errorf("%s: no coordinates", where)
+			}
+			for _, c := range coords {
+				lonLat := strings.Split(c, ",")
+				if len(lonLat) != 2 {
+					t.Errorf("%s: coordinate %q is not lon,lat", where, c)
+					continue
+				}
+				lon, err1 := strconv.ParseFloat(lonLat[0], 64)
+				lat, err2 := strconv.ParseFloat(lonLat[1], 64)
+				if err1 != nil || err2 != nil {
+					t.Errorf("%s: coordinate %q is not numeric", where, c)
+					continue
+				}
+				checkPosition(t, where, []float64{lon, lat})
+			}
+			if pm.PolygonRing != "" && coords[0] != coords[len(coords)-1] {
+				t.Errorf("%s: LinearRing is not closed", where)
+			}
+		}
+	}
+}
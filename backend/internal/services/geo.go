@@ -0,0 +1,45 @@
+package services
+
+import "math"
+
+const (
+	earthRadiusKm = 6371.0088 // mean Earth radius (IUGG)
+	kmPerNM       = 1.852
+)
+
+func toRad(deg float64) float64 { return deg * math.Pi / 180 }
+func toDeg(rad float64) float64 { return rad * 180 / math.Pi }
+
+// haversineKm returns the great-circle distance between two points in km.
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// initialBearing returns the initial great-circle bearing from point 1 to
+// point 2 in degrees [0, 360).
+func initialBearing(lat1, lon1, lat2, lon2 float64) float64 {
+	p1, p2 := toRad(lat1), toRad(lat2)
+	dLon := toRad(lon2 - lon1)
+	y := math.Sin(dLon) * math.Cos(p2)
+	x := math.Cos(p1)*math.Sin(p2) - math.Sin(p1)*math.Cos(p2)*math.Cos(dLon)
+	return math.Mod(toDeg(math.Atan2(y, x))+360, 360)
+}
+
+// destinationPoint moves distKm from (lat, lon) along the given bearing and
+// returns the resulting coordinates.
+func destinationPoint(lat, lon, bearing, distKm float64) (float64, float64) {
+	ang := distKm / earthRadiusKm
+	brg := toRad(bearing)
+	lat1, lon1 := toRad(lat), toRad(lon)
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(ang) + math.Cos(lat1)*math.Sin(ang)*math.Cos(brg))
+	lon2 := lon1 + math.Atan2(math.Sin(brg)*math.Sin(ang)*math.Cos(lat1), math.Cos(ang)-math.Sin(lat1)*math.Sin(lat2))
+	return toDeg(lat2), math.Mod(toDeg(lon2)+540, 360) - 180
+}
+
+func validLatLon(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
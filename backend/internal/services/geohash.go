@@ -0,0 +1,130 @@
+package services
+
+import (
+	"math"
+	"strings"
+)
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// geohashIndexPrecision is the precision of stored geohashes (~5 m cells).
+const geohashIndexPrecision = 9
+
+// encodeGeohash returns the geohash of (lat, lon) with the given number of
+// characters.
+func encodeGeohash(lat, lon float64, precision int) string {
+	latLo, latHi := -90.0, 90.0
+	lonLo, lonHi := -180.0, 180.0
+	var b strings.Builder
+	bit, ch, even := 0, 0, true
+	for b.Len() < precision {
+		if even {
+			mid := (lonLo + lonHi) / 2
+			if lon >= mid {
+				ch |= 1 << (4 - bit)
+				lonLo = mid
+			} else {
+				lonHi = mid
+			}
+		} else {
+			mid := (latLo + latHi) / 2
+			if lat >= mid {
+				ch |= 1 << (4 - bit)
+				latLo = mid
+			} else {
+				latHi = mid
+			}
+		}
+		even = !even
+		if bit++; bit == 5 {
+			b.WriteByte(geohashBase32[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return b.String()
+}
+
+// geohashCellSize returns the height and width in degrees of a geohash cell
+// of the given precision.
+func geohashCellSize(precision int) (float64, float64) {
+	bits := 5 * precision
+	lonBits := (bits + 1) / 2
+	latBits := bits / 2
+	return 180 / math.Exp2(float64(latBits)), 360 / math.Exp2(float64(lonBits))
+}
+
+// geohashCover returns geohash prefixes whose cells together cover the box,
+// using the finest precision that needs at most maxCells cells. A box with
+// minLon > maxLon crosses the antimeridian.
+func geohashCover(minLat, minLon, maxLat, maxLon float64, maxCells int) []string {
+	minLat, maxLat = math.Max(minLat, -90), math.Min(maxLat, 90)
+	lonSpans := [][2]float64{{minLon, maxLon}}
+	if minLon > maxLon {
+		lonSpans = [][2]float64{{minLon, 180}, {-180, maxLon}}
+	}
+
+	cells := func(precision int) int {
+		h, w := geohashCellSize(precision)
+		rows := math.Floor(maxLat/h) - math.Floor(minLat/h) + 1
+		n := 0.0
+		for _, sp := range lonSpans {
+			n += rows * (math.Floor(sp[1]/w) - math.Floor(sp[0]/w) + 1)
+		}
+		return int(math.Min(n, math.MaxInt32))
+	}
+	precision := 1
+	for precision < geohashIndexPrecision && cells(precision+1) <= maxCells {
+		precision++
+	}
+
+	h, w := geohashCellSize(precision)
+	seen := make(map[string]bool)
+	var out []string
+	for lat := (math.Floor(minLat/h) + 0.5) * h; lat-h/2 <= maxLat; lat += h {
+		for _, sp := range lonSpans {
+			for lon := (math.Floor(sp[0]/w) + 0.5) * w; lon-w/2 <= sp[1]; lon += w {
+				gh := encodeGeohash(math.Min(lat, 90), math.Min(lon, 180), precision)
+				if !seen[gh] {
+					seen[gh] = true
+					out = append(out, gh)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// radiusBox returns the lat/lon box enclosing the circle of radiusKm around
+// (lat, lon). The longitude span is the whole globe when the circle reaches a
+// pole; minLon > maxLon when it crosses the antimeridian.
+func radiusBox(lat, lon, radiusKm float64) (minLat, minLon, maxLat, maxLon float64) {
+	dLat := toDeg(radiusKm / earthRadiusKm)
+	minLat, maxLat = lat-dLat, lat+dLat
+	if minLat <= -90 || maxLat >= 90 {
+		return math.Max(minLat, -90), -180, math.Min(maxLat, 90), 180
+	}
+	dLon := toDeg(math.Asin(math.Min(1, math.Sin(radiusKm/earthRadiusKm)/math.Cos(toRad(lat)))))
+	if dLon >= 180 {
+		return minLat, -180, maxLat, 180
+	}
+	minLon, maxLon = lon-dLon, lon+dLon
+	if minLon < -180 {
+		minLon += 360
+	}
+	if maxLon > 180 {
+		maxLon -= 360
+	}
+	return minLat, minLon, maxLat, maxLon
+}
+
+// inBox reports whether (lat, lon) lies in the box; see geohashCover for
+// boxes crossing the antimeridian.
+func inBox(lat, lon, minLat, minLon, maxLat, maxLon float64) bool {
+	if lat < minLat || lat > maxLat {
+		return false
+	}
+	if minLon <= maxLon {
+		return lon >= minLon && lon <= maxLon
+	}
+	return lon >= minLon || lon <= maxLon
+}
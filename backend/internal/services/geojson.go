@@ -0,0 +1,31 @@
+package services
+
+// GeoJSON (RFC 7946) types. Coordinates are [longitude, latitude].
+
+type GeoJSONGeometry struct {
+	Type        string `json:"type"` // "Point", "LineString", "Polygon"
+	Coordinates any    `json:"coordinates"`
+}
+
+type GeoJSONFeature struct {
+	Type       string           `json:"type"` // always "Feature"
+	Geometry   *GeoJSONGeometry `json:"geometry"`
+	Properties map[string]any   `json:"properties"`
+}
+
+type GeoJSONFeatureCollection struct {
+	Type     string            `json:"type"` // always "FeatureCollection"
+	Features []*GeoJSONFeature `json:"features"`
+}
+
+func newFeature(geometry *GeoJSONGeometry, properties map[string]any) *GeoJSONFeature {
+	return &GeoJSONFeature{Type: "Feature", Geometry: geometry, Properties: properties}
+}
+
+func newFeatureCollection() *GeoJSONFeatureCollection {
+	return &GeoJSONFeatureCollection{Type: "FeatureCollection", Features: []*GeoJSONFeature{}}
+}
+
+func pointGeometry(lat, lon float64) *GeoJSONGeometry {
+	return &GeoJSONGeometry{Type: "Point", Coordinates: []float64{lon, lat}}
+}
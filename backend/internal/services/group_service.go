@@ -0,0 +1,211 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// ErrInvalidGroup is returned (wrapped) for malformed station groups.
+var ErrInvalidGroup = errors.New("invalid station group")
+
+// GroupService keeps the region → sector hierarchy at "station_group:{id}".
+// Stations and users point to a group through their GroupID.
+type GroupService struct {
+	db *DB
+}
+
+func NewGroupService(db *DB) *GroupService {
+	return &GroupService{db: db}
+}
+
+func groupKey(id uint) string { return fmt.Sprintf("station_group:%d", id) }
+
+// checkGroup verifies that an assigned group exists; nil means unassigned.
+func checkGroup(db *DB, id *uint) error {
+	if id == nil {
+		return nil
+	}
+	if ok, err := db.Exists(groupKey(*id)); err != nil {
+		return err
+	} else if !ok {
+		return errors.New("group not found")
+	}
+	return nil
+}
+
+func (s *GroupService) validate(g *models.StationGroup) error {
+	g.Name = strings.TrimSpace(g.Name)
+	if g.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidGroup)
+	}
+	switch g.Kind {
+	case models.GroupRegion:
+		if g.ParentID != nil {
+			return fmt.Errorf("%w: a region cannot have a parent", ErrInvalidGroup)
+		}
+	case models.GroupSector:
+		if g.ParentID == nil {
+			return fmt.Errorf("%w: a sector must belong to a region", ErrInvalidGroup)
+		}
+		parent, err := s.GetByID(*g.ParentID)
+		if err != nil || parent.Kind != models.GroupRegion {
+			return fmt.Errorf("%w: parent_id must be a region", ErrInvalidGroup)
+		}
+	default:
+		return fmt.Errorf("%w: kind must be REGION or SECTOR", ErrInvalidGroup)
+	}
+	return nil
+}
+
+// Create adds a region or a sector.
+func (s *GroupService) Create(g *models.StationGroup) error {
+	if err := s.validate(g); err != nil {
+		return err
+	}
+	id, err := s.db.NextID("station_group_counter")
+	if err != nil {
+		return fmt.Errorf("failed to generate ID: %w", err)
+	}
+	g.ID = id
+	g.CreatedAt = time.Now().Unix()
+	g.UpdatedAt = g.CreatedAt
+	return s.db.PutJSON(groupKey(g.ID), g)
+}
+
+// GetByID retrieves a group by ID
+func (s *GroupService) GetByID(id uint) (*models.StationGroup, error) {
+	var g models.StationGroup
+	if err := s.db.GetJSON(groupKey(id), &g); err != nil {
+		return nil, errors.New("group not found")
+	}
+	return &g, nil
+}
+
+// List returns all groups ordered by ID.
+func (s *GroupService) List() ([]models.StationGroup, error) {
+	out := []models.StationGroup{}
+	err := s.db.IteratePrefix("station_group:", func(_ string, val []byte) error {
+		var g models.StationGroup
+		if err := json.Unmarshal(val, &g); err != nil {
+			return nil // Skip invalid records
+		}
+		out = append(out, g)
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, err
+}
+
+func (s *GroupService) children(id uint) ([]models.StationGroup, error) {
+	all, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var out []models.StationGroup
+	for _, g := range all {
+		if g.ParentID != nil && *g.ParentID == id {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
+// Update saves a changed group. A region that still has sectors cannot be
+// turned into a sector.
+func (s *GroupService) Update(g *models.StationGroup) error {
+	existing, err := s.GetByID(g.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validate(g); err != nil {
+		return err
+	}
+	if g.Kind == models.GroupSector && existing.Kind == models.GroupRegion {
+		if kids, err := s.children(g.ID); err != nil {
+			return err
+		} else if len(kids) > 0 {
+			return fmt.Errorf("%w: region still has sectors", ErrInvalidGroup)
+		}
+	}
+	g.CreatedAt = existing.CreatedAt
+	g.UpdatedAt = time.Now().Unix()
+	return s.db.PutJSON(groupKey(g.ID), g)
+}
+
+// Delete removes a group that has no sectors, stations or users left.
+func (s *GroupService) Delete(id uint) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	kids, err := s.children(id)
+	if err != nil {
+		return err
+	}
+	inUse := len(kids) > 0
+	check := func(_ string, val []byte) error {
+		var ref struct {
+			GroupID *uint `json:"group_id"`
+		}
+		if err := json.Unmarshal(val, &ref); err == nil && ref.GroupID != nil && *ref.GroupID == id {
+			inUse = true
+		}
+		return nil
+	}
+	if err := s.db.IteratePrefix("station:", check); err != nil {
+		return err
+	}
+	if err := s.db.IteratePrefix("user:", check); err != nil {
+		return err
+	}
+	if inUse {
+		return errors.New("group is not empty")
+	}
+	return s.db.Delete(groupKey(id))
+}
+
+// Subtree returns the IDs of a group and all groups below it.
+func (s *GroupService) Subtree(id uint) (map[uint]bool, error) {
+	if _, err := s.GetByID(id); err != nil {
+		return nil, err
+	}
+	all, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	ids := map[uint]bool{id: true}
+	// The hierarchy is at most two levels deep, but walk until stable anyway.
+	for changed := true; changed; {
+		changed = false
+		for _, g := range all {
+			if g.ParentID != nil && ids[*g.ParentID] && !ids[g.ID] {
+				ids[g.ID] = true
+				changed = true
+			}
+		}
+	}
+	return ids, nil
+}
+
+// StationIDs returns the IDs of the stations in a group or any group below
+// it.
+func (s *GroupService) StationIDs(id uint) (map[uint]bool, error) {
+	groups, err := s.Subtree(id)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[uint]bool)
+	err = s.db.IteratePrefix("station:", func(_ string, val []byte) error {
+		var st models.Station
+		if err := json.Unmarshal(val, &st); err == nil && st.GroupID != nil && groups[*st.GroupID] {
+			out[st.ID] = true
+		}
+		return nil
+	})
+	return out, err
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+var (
+	// ErrInvalidHandover is returned (wrapped) for malformed handovers.
+	ErrInvalidHandover = errors.New("invalid handover")
+	// ErrNotWatchCommander is returned (wrapped) when someone other than the
+	// commander of a watch records or signs its handover.
+	ErrNotWatchCommander = errors.New("not the watch commander")
+)
+
+const (
+	// handoverLookback is how long after a watch ends its handover can still
+	// be recorded.
+	handoverLookback = 24 * time.Hour
+	// handoverLookahead is how far after the outgoing watch the next watch is
+	// searched for.
+	handoverLookahead = 7 * 24 * time.Hour
+)
+
+// HandoverService keeps the handover log at "handover:{station}:{id}". The
+// outgoing and incoming watches are taken from the station's schedules.
+type HandoverService struct {
+	db       *DB
+	schedSvc *ScheduleService
+	now      func() time.Time
+}
+
+func NewHandoverService(db *DB, schedSvc *ScheduleService) *HandoverService {
+	return &HandoverService{db: db, schedSvc: schedSvc, now: schedSvc.now}
+}
+
+func handoverKey(stationID, id uint) string {
+	return fmt.Sprintf("handover:%d:%010d", stationID, id)
+}
+
+func handoverWatch(w Watch) models.HandoverWatch {
+	return models.HandoverWatch{ScheduleID: w.ScheduleID, OverrideID: w.OverrideID, Start: w.Start, End: w.End,
+		Commander: w.Commander, CommanderID: w.CommanderID}
+}
+
+// consecutiveWatches returns the watch of a station in progress at at (or
+// the last one that ended within handoverLookback) and the watch after it.
+func (s *HandoverService) consecutiveWatches(stationID uint, at int64) (Watch, Watch, error) {
+	recent, err := s.schedSvc.Watches(stationID, at-int64(handoverLookback/time.Second), at+1)
+	if err != nil {
+		return Watch{}, Watch{}, err
+	}
+	var outgoing *Watch
+	for i := range recent {
+		if recent[i].Start <= at {
+			outgoing = &recent[i]
+		}
+	}
+	if outgoing == nil {
+		return Watch{}, Watch{}, fmt.Errorf("%w: no watch to hand over", ErrInvalidHandover)
+	}
+
+	next, err := s.schedSvc.Watches(stationID, outgoing.End, outgoing.End+int64(handoverLookahead/time.Second))
+	if err != nil {
+		return Watch{}, Watch{}, err
+	}
+	for _, w := range next {
+		if w.Start >= outgoing.End {
+			return *outgoing, w, nil
+		}
+	}
+	return Watch{}, Watch{}, fmt.Errorf("%w: no watch follows the current one", ErrInvalidHandover)
+}
+
+// commanderAccount returns the user account linked to the commander of a
+// watch, or "" when anyone at the station may act for it.
+func (s *HandoverService) commanderAccount(w models.HandoverWatch) string {
+	if w.CommanderID == nil {
+		return ""
+	}
+	var p models.Personnel
+	if err := s.db.GetJSON(personnelKey(*w.CommanderID), &p); err != nil {
+		return ""
+	}
+	return p.Username
+}
+
+func (s *HandoverService) validateItems(h *models.Handover) error {
+	for i := range h.Items {
+		it := &h.Items[i]
+		it.Text = strings.TrimSpace(it.Text)
+		if it.Text == "" {
+			return fmt.Errorf("%w: item %d: text is required", ErrInvalidHandover, i+1)
+		}
+		switch it.Kind {
+		case models.HandoverOpenCommand, models.HandoverEquipment, models.HandoverContact, models.HandoverOther:
+		default:
+			return fmt.Errorf("%w: item %d: kind must be OPEN_COMMAND, EQUIPMENT, CONTACT or OTHER", ErrInvalidHandover, i+1)
+		}
+		if it.CommandID != nil {
+			var cmd models.Command
+			if err := s.db.GetJSON(fmt.Sprintf("command:%d", *it.CommandID), &cmd); err != nil || cmd.ToStationID != h.StationID {
+				return fmt.Errorf("%w: item %d: command %d not found at this station", ErrInvalidHandover, i+1, *it.CommandID)
+			}
+		}
+		if it.EquipmentID != nil {
+			var eq models.Equipment
+			if err := s.db.GetJSON(equipmentKey(*it.EquipmentID), &eq); err != nil || eq.StationID != h.StationID {
+				return fmt.Errorf("%w: item %d: equipment %d not found at this station", ErrInvalidHandover, i+1, *it.EquipmentID)
+			}
+		}
+		it.MMSI = strings.TrimSpace(it.MMSI)
+	}
+	return nil
+}
+
+// Create records the handover of the watch in progress at at (Unix seconds)
+// to the next watch of the station. When the outgoing commander is linked to
+// a user account, only that user may record it.
+func (s *HandoverService) Create(h *models.Handover, at int64, username string) error {
+	if ok, err := s.db.Exists(fmt.Sprintf("station:%d", h.StationID)); err != nil {
+		return err
+	} else if !ok {
+		return errors.New("station not found")
+	}
+	if h.Items == nil {
+		h.Items = []models.HandoverItem{}
+	}
+	if err := s.validateItems(h); err != nil {
+		return err
+	}
+	h.Note = strings.TrimSpace(h.Note)
+
+	outgoing, incoming, err := s.consecutiveWatches(h.StationID, at)
+	if err != nil {
+		return err
+	}
+	h.Outgoing, h.Incoming = handoverWatch(outgoing), handoverWatch(incoming)
+	if acct := s.commanderAccount(h.Outgoing); acct != "" && acct != username {
+		return fmt.Errorf("%w: only %s can hand over this watch", ErrNotWatchCommander, acct)
+	}
+
+	existing, err := s.List(h.StationID, false)
+	if err != nil {
+		return err
+	}
+	for _, e := range existing {
+		if e.Outgoing.Start == h.Outgoing.Start && e.Outgoing.ScheduleID == h.Outgoing.ScheduleID && e.Outgoing.OverrideID == h.Outgoing.OverrideID {
+			return errors.New("handover already recorded")
+		}
+	}
+
+	id, err := s.db.NextID("handover_counter")
+	if err != nil {
+		return fmt.Errorf("failed to generate ID: %w", err)
+	}
+	h.ID = id
+	h.CreatedBy = username
+	h.CreatedAt = s.now().Unix()
+	h.SignedBy, h.SignedAt, h.SignedNote = "", nil, ""
+	return s.db.PutJSON(handoverKey(h.StationID, h.ID), h)
+}
+
+// GetByID retrieves a handover of a station.
+func (s *HandoverService) GetByID(stationID, id uint) (*models.Handover, error) {
+	var h models.Handover
+	if err := s.db.GetJSON(handoverKey(stationID, id), &h); err != nil {
+		return nil, errors.New("handover not found")
+	}
+	return &h, nil
+}
+
+// Sign records the incoming commander's acceptance of a handover. The person
+// who recorded it cannot sign it, and when the incoming commander is linked to
+// a user account only that user may sign.
+func (s *HandoverService) Sign(stationID, id uint, username, note string) (*models.Handover, error) {
+	h, err := s.GetByID(stationID, id)
+	if err != nil {
+		return nil, err
+	}
+	if h.SignedAt != nil {
+		return nil, errors.New("handover already signed")
+	}
+	if username == h.CreatedBy {
+		return nil, fmt.Errorf("%w: the outgoing commander cannot sign their own handover", ErrNotWatchCommander)
+	}
+	if acct := s.commanderAccount(h.Incoming); acct != "" && acct != username {
+		return nil, fmt.Errorf("%w: only %s can sign this handover", ErrNotWatchCommander, acct)
+	}
+	now := s.now().Unix()
+	h.SignedBy, h.SignedAt, h.SignedNote = username, &now, strings.TrimSpace(note)
+	if err := s.db.PutJSON(handoverKey(stationID, id), h); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// List returns the handovers of a station (or of all stations when
+// stationID is 0), newest watch first. unsignedOnly keeps those still
+// waiting for sign-off, oldest first.
+func (s *HandoverService) List(stationID uint, unsignedOnly bool) ([]models.Handover, error) {
+	prefix := "handover:"
+	if stationID != 0 {
+		prefix = fmt.Sprintf("handover:%d:", stationID)
+	}
+	out := []models.Handover{}
+	err := s.db.IteratePrefix(prefix, func(_ string, val []byte) error {
+		var h models.Handover
+		if err := json.Unmarshal(val, &h); err != nil {
+			return nil // Skip invalid records
+		}
+		if !unsignedOnly || h.SignedAt == nil {
+			out = append(out, h)
+		}
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool {
+		if unsignedOnly {
+			return out[i].Incoming.Start < out[j].Incoming.Start
+		}
+		return out[i].Outgoing.Start > out[j].Outgoing.Start
+	})
+	return out, err
+}
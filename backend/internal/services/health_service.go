@@ -0,0 +1,254 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+const (
+	heartbeatDegradedAfter = 90  // seconds without heartbeat before DEGRADED
+	heartbeatOfflineAfter  = 300 // seconds without heartbeat before OFFLINE
+	maxEquipmentTemp       = 70.0
+
+	// HealthCheckInterval is how often the monitor re-evaluates all stations.
+	HealthCheckInterval = 15 * time.Second
+)
+
+// EventStationStatus is the notifier event type for station status changes.
+const EventStationStatus = "station_status"
+
+// HealthService keeps the latest heartbeat of each station at
+// "station_heartbeat:{id}", the last evaluated status at "station_state:{id}",
+// the manual override at "station_override:{id}" and the log of status
+// changes at "station_status_log:{id}:{changeID}".
+type HealthService struct {
+	db       *DB
+	schedSvc *ScheduleService
+	notifier *Notifier
+	mu       sync.Mutex // serialises evaluations
+	now      func() time.Time
+}
+
+func NewHealthService(db *DB, schedSvc *ScheduleService, notifier *Notifier) *HealthService {
+	return &HealthService{db: db, schedSvc: schedSvc, notifier: notifier, now: schedSvc.now}
+}
+
+// Record stores a heartbeat and re-evaluates the station immediately.
+func (s *HealthService) Record(hb *models.Heartbeat) (*models.StationHealth, error) {
+	if err := s.checkStation(hb.StationID); err != nil {
+		return nil, err
+	}
+	if hb.Uptime < 0 {
+		return nil, errors.New("uptime must not be negative")
+	}
+	hb.ReceivedAt = s.now().Unix()
+	if err := s.db.PutJSON(fmt.Sprintf("station_heartbeat:%d", hb.StationID), hb); err != nil {
+		return nil, err
+	}
+	return s.Evaluate(hb.StationID)
+}
+
+func (s *HealthService) checkStation(stationID uint) error {
+	if ok, err := s.db.Exists(fmt.Sprintf("station:%d", stationID)); err != nil {
+		return err
+	} else if !ok {
+		return errors.New("station not found")
+	}
+	return nil
+}
+
+// LastHeartbeat returns the latest heartbeat of a station, or nil if it has
+// never sent one.
+func (s *HealthService) LastHeartbeat(stationID uint) *models.Heartbeat {
+	var hb models.Heartbeat
+	if err := s.db.GetJSON(fmt.Sprintf("station_heartbeat:%d", stationID), &hb); err != nil {
+		return nil
+	}
+	return &hb
+}
+
+// assess derives the equipment health from the latest heartbeat.
+func assess(hb *models.Heartbeat, now int64) (string, string) {
+	if hb == nil {
+		return models.HealthUnknown, ""
+	}
+	age := now - hb.ReceivedAt
+	switch {
+	case age > heartbeatOfflineAfter:
+		return models.HealthOffline, fmt.Sprintf("no heartbeat for %ds", age)
+	case !hb.TransmitterOn:
+		return models.HealthOffline, "transmitter off"
+	case age > heartbeatDegradedAfter:
+		return models.HealthDegraded, fmt.Sprintf("heartbeat late (%ds)", age)
+	case len(hb.ErrorCodes) > 0:
+		return models.HealthDegraded, "errors: " + strings.Join(hb.ErrorCodes, ", ")
+	case hb.Temperature > maxEquipmentTemp:
+		return models.HealthDegraded, fmt.Sprintf("temperature %.1f°C", hb.Temperature)
+	}
+	return models.HealthOK, ""
+}
+
+// Status combines the schedule with the equipment health: stations off
+// schedule are INACTIVE; on schedule they are ACTIVE unless their health is
+// DEGRADED or OFFLINE. Stations that never sent a heartbeat are judged by
+// their schedule alone. An active override takes precedence over both.
+func (s *HealthService) Status(stationID uint) (status, health, reason string) {
+	health, reason = assess(s.LastHeartbeat(stationID), s.now().Unix())
+	if ov := s.ActiveOverride(stationID); ov != nil {
+		return overrideStatus(ov.State), health, fmt.Sprintf("%s by %s: %s", ov.State, ov.SetBy, ov.Reason)
+	}
+	if active, _ := s.schedSvc.ActiveAt(stationID, s.now()); !active {
+		return models.StationInactive, health, "off schedule"
+	}
+	switch health {
+	case models.HealthDegraded:
+		return models.StationDegraded, health, reason
+	case models.HealthOffline:
+		return models.StationOffline, health, reason
+	}
+	return models.StationActive, health, reason
+}
+
+// Evaluate computes the current status of a station and, when it or the
+// override in effect differs from the last evaluated one, logs the change
+// and notifies HQ.
+func (s *HealthService) Evaluate(stationID uint) (*models.StationHealth, error) {
+	return s.evaluate(stationID, "")
+}
+
+// evaluate is Evaluate with an optional note that replaces the reason of a
+// logged change.
+func (s *HealthService) evaluate(stationID uint, note string) (*models.StationHealth, error) {
+	if err := s.checkStation(stationID); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().Unix()
+	status, health, reason := s.Status(stationID)
+	ov := s.ActiveOverride(stationID)
+	current := models.StationHealth{StationID: stationID, Health: health, Reason: reason, Status: status, Override: ov, Since: now}
+
+	stateKey := fmt.Sprintf("station_state:%d", stationID)
+	var prev models.StationHealth
+	err := s.db.GetJSON(stateKey, &prev)
+	if err == nil && prev.Status == status && sameOverride(prev.Override, ov) {
+		current.Since = prev.Since
+	} else {
+		if note == "" && prev.Override != nil && ov == nil {
+			note = "override expired"
+		}
+		if note != "" {
+			reason = note
+		}
+		change := models.StationStatusChange{StationID: stationID, From: prev.Status, To: status, Reason: reason, ChangedAt: now}
+		if ov != nil {
+			change.Override = ov.State
+		}
+		id, err := s.db.NextID("station_status_log_counter")
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate ID: %w", err)
+		}
+		change.ID = id
+		if err := s.db.PutJSON(fmt.Sprintf("station_status_log:%d:%010d", stationID, id), &change); err != nil {
+			return nil, err
+		}
+		if prev.Status != "" {
+			s.notifier.Publish(EventStationStatus, change)
+		}
+		if prev.Status == status {
+			current.Since = prev.Since
+		}
+	}
+	if ov == nil && s.storedOverride(stationID) != nil {
+		s.db.Delete(overrideKey(stationID)) // Expired; ignore error
+	}
+	if err := s.db.PutJSON(stateKey, &current); err != nil {
+		return nil, err
+	}
+	current.LastHeartbeat = s.LastHeartbeat(stationID)
+	return &current, nil
+}
+
+func sameOverride(a, b *models.StationOverride) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.State == b.State && a.SetAt == b.SetAt
+}
+
+// CheckAll evaluates every station.
+func (s *HealthService) CheckAll() {
+	var ids []uint
+	s.db.IteratePrefix("station:", func(_ string, val []byte) error {
+		var st models.Station
+		if err := json.Unmarshal(val, &st); err == nil {
+			ids = append(ids, st.ID)
+		}
+		return nil
+	})
+	for _, id := range ids {
+		if _, err := s.Evaluate(id); err != nil {
+			log.Println("Health check failed for station", id, ":", err)
+		}
+	}
+}
+
+// Run evaluates all stations every interval until stop is closed. It is
+// meant to be started in its own goroutine.
+func (s *HealthService) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	s.CheckAll()
+	for {
+		select {
+		case <-ticker.C:
+			s.CheckAll()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// History returns the status changes of a station within [from, to]
+// (Unix seconds, 0 = open), newest first, at most limit (0 = all).
+func (s *HealthService) History(stationID uint, from, to int64, limit int) ([]models.StationStatusChange, error) {
+	out := []models.StationStatusChange{}
+	err := s.db.IteratePrefix(fmt.Sprintf("station_status_log:%d:", stationID), func(_ string, val []byte) error {
+		var ch models.StationStatusChange
+		if err := json.Unmarshal(val, &ch); err != nil {
+			return nil // Skip invalid records
+		}
+		if (from == 0 || ch.ChangedAt >= from) && (to == 0 || ch.ChangedAt <= to) {
+			out = append(out, ch)
+		}
+		return nil
+	})
+	sort.SliceStable(out, func(i, j int) bool { return out[i].ID > out[j].ID })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, err
+}
+
+// DeleteByStation removes the heartbeat, state, override and status log of a
+// station.
+func (s *HealthService) DeleteByStation(stationID uint) {
+	keys := []string{fmt.Sprintf("station_heartbeat:%d", stationID), fmt.Sprintf("station_state:%d", stationID), overrideKey(stationID)}
+	s.db.IteratePrefix(fmt.Sprintf("station_status_log:%d:", stationID), func(key string, _ []byte) error {
+		keys = append(keys, key)
+		return nil
+	})
+	for _, k := range keys {
+		s.db.Delete(k) // Ignore error
+	}
+}
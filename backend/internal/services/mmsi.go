@@ -0,0 +1,120 @@
+package services
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"strings"
+	"sync"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// MMSI types following ITU-R M.585.
+const (
+	MMSIShip         = "SHIP"            // MIDXXXXXX
+	MMSIGroup        = "GROUP"           // 0MIDXXXXX
+	MMSICoast        = "COAST_STATION"   // 00MIDXXXX
+	MMSISARAircraft  = "SAR_AIRCRAFT"    // 111MIDXXX
+	MMSIAtoN         = "ATON"            // 99MIDXXXX
+	MMSIAuxiliary    = "AUXILIARY_CRAFT" // 98MIDXXXX
+	MMSIHandheld     = "HANDHELD_VHF"    // 8MIDXXXXX
+	MMSISafetyDevice = "SAFETY_DEVICE"   // 970/972/974XXXXXX (AIS-SART, MOB, EPIRB)
+)
+
+// MID is one entry of the Maritime Identification Digits table.
+type MID struct {
+	Code    string `json:"code"`    // ISO 3166-1 alpha-2
+	Country string `json:"country"` // Country or territory name
+}
+
+// MMSIInfo is the result of classifying an MMSI.
+type MMSIInfo struct {
+	Type string
+	MID  string // empty for safety devices
+	Flag *MID   // nil when the MID is not allocated in the table
+}
+
+//go:embed mid_table.csv
+var midTableCSV string
+
+var (
+	midOnce  sync.Once
+	midTable map[string]MID
+)
+
+// lookupMID returns the flag state allocated to a three-digit MID.
+func lookupMID(mid string) (MID, bool) {
+	midOnce.Do(func() {
+		midTable = make(map[string]MID)
+		records, err := csv.NewReader(strings.NewReader(midTableCSV)).ReadAll()
+		if err != nil {
+			panic("invalid embedded MID table: " + err.Error())
+		}
+		for _, r := range records[1:] {
+			midTable[r[0]] = MID{Code: r[1], Country: r[2]}
+		}
+	})
+	m, ok := midTable[mid]
+	return m, ok
+}
+
+// ClassifyMMSI validates an MMSI and determines its type and, where the
+// type carries a MID, the flag state. The returned error wraps
+// ErrInvalidVessel.
+func ClassifyMMSI(mmsi string) (MMSIInfo, error) {
+	if len(mmsi) != 9 {
+		return MMSIInfo{}, invalidVessel("MMSI must have 9 digits")
+	}
+	for _, r := range mmsi {
+		if r < '0' || r > '9' {
+			return MMSIInfo{}, invalidVessel("MMSI must have 9 digits")
+		}
+	}
+
+	var info MMSIInfo
+	switch {
+	case strings.HasPrefix(mmsi, "00"):
+		info = MMSIInfo{Type: MMSICoast, MID: mmsi[2:5]}
+	case mmsi[0] == '0':
+		info = MMSIInfo{Type: MMSIGroup, MID: mmsi[1:4]}
+	case strings.HasPrefix(mmsi, "111"):
+		info = MMSIInfo{Type: MMSISARAircraft, MID: mmsi[3:6]}
+	case strings.HasPrefix(mmsi, "970"), strings.HasPrefix(mmsi, "972"), strings.HasPrefix(mmsi, "974"):
+		info = MMSIInfo{Type: MMSISafetyDevice}
+	case strings.HasPrefix(mmsi, "98"):
+		info = MMSIInfo{Type: MMSIAuxiliary, MID: mmsi[2:5]}
+	case strings.HasPrefix(mmsi, "99"):
+		info = MMSIInfo{Type: MMSIAtoN, MID: mmsi[2:5]}
+	case mmsi[0] == '8':
+		info = MMSIInfo{Type: MMSIHandheld, MID: mmsi[1:4]}
+	case mmsi[0] >= '2' && mmsi[0] <= '7':
+		info = MMSIInfo{Type: MMSIShip, MID: mmsi[0:3]}
+	default:
+		return MMSIInfo{}, invalidVessel("MMSI does not match any known pattern")
+	}
+
+	if info.MID != "" {
+		if info.MID[0] < '2' || info.MID[0] > '7' {
+			return MMSIInfo{}, invalidVessel("MMSI contains an invalid MID")
+		}
+		if m, ok := lookupMID(info.MID); ok {
+			info.Flag = &m
+		}
+	}
+	return info, nil
+}
+
+// applyMMSIInfo validates the vessel MMSI and fills MMSIType and the flag
+// fields from it.
+func applyMMSIInfo(v *models.Vessel) error {
+	info, err := ClassifyMMSI(v.MMSI)
+	if err != nil {
+		return err
+	}
+	v.MMSIType = info.Type
+	v.FlagCode, v.FlagCountry = "", ""
+	if info.Flag != nil {
+		v.FlagCode, v.FlagCountry = info.Flag.Code, info.Flag.Country
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+func TestClassifyMMSI(t *testing.T) {
+	tests := []struct {
+		mmsi, typ, mid, flag string // flag "" = MID not in the table
+		wantErr              bool
+	}{
+		{"574123456", MMSIShip, "574", "VN", false},
+		{"111232456", MMSISARAircraft, "232", "GB", false},
+		{"995741234", MMSIAtoN, "574", "VN", false},
+		{"003669999", MMSICoast, "366", "US", false},
+		{"057412345", MMSIGroup, "574", "VN", false},
+		{"200123456", MMSIShip, "200", "", false},
+		{"970123456", MMSISafetyDevice, "", "", false},
+		{"57412345", "", "", "", true},
+		{"5741234567", "", "", "", true},
+		{"57412345A", "", "", "", true},
+		{"111123456", "", "", "", true}, // MID must start with 2-7
+		{"123456789", "", "", "", true},
+	}
+	for _, tt := range tests {
+		info, err := ClassifyMMSI(tt.mmsi)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidVessel) {
+				t.Errorf("ClassifyMMSI(%s) error = %v, want ErrInvalidVessel", tt.mmsi, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ClassifyMMSI(%s): %v", tt.mmsi, err)
+			continue
+		}
+		flag := ""
+		if info.Flag != nil {
+			flag = info.Flag.Code
+		}
+		if info.Type != tt.typ || info.MID != tt.mid || flag != tt.flag {
+			t.Errorf("ClassifyMMSI(%s) = %s, MID %q, flag %q; want %s, %q, %q", tt.mmsi, info.Type, info.MID, flag, tt.typ, tt.mid, tt.flag)
+		}
+	}
+}
+
+func TestApplyMMSIInfo(t *testing.T) {
+	v := &models.Vessel{MMSI: "574123456", FlagCode: "XX", FlagCountry: "Stale"}
+	if err := applyMMSIInfo(v); err != nil {
+		t.Fatalf("applyMMSIInfo: %v", err)
+	}
+	if v.MMSIType != MMSIShip || v.FlagCode != "VN" || v.FlagCountry != "Viet Nam" {
+		t.Errorf("got %s, %s, %s; want SHIP, VN, Viet Nam", v.MMSIType, v.FlagCode, v.FlagCountry)
+	}
+
+	// An unallocated MID clears the flag derived from the previous MMSI.
+	v.MMSI = "200123456"
+	if err := applyMMSIInfo(v); err != nil {
+		t.Fatalf("applyMMSIInfo: %v", err)
+	}
+	if v.FlagCode != "" || v.FlagCountry != "" {
+		t.Errorf("flag = %q, %q; want none", v.FlagCode, v.FlagCountry)
+	}
+}
+
+func TestNormalizeIMO(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"9074729", "9074729", false},
+		{"IMO 9074729", "9074729", false},
+		{" imo9074729 ", "9074729", false},
+		{"9074728", "", true},
+		{"907472", "", true},
+		{"90747290", "", true},
+		{"90747A9", "", true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeIMO(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidVessel) {
+				t.Errorf("normalizeIMO(%q) error = %v, want ErrInvalidVessel", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("normalizeIMO(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
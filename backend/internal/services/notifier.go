@@ -0,0 +1,57 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is a real-time notification pushed to connected clients.
+type Event struct {
+	Type string `json:"type"` // e.g. "watchlist_alert"
+	Data any    `json:"data"`
+	Time int64  `json:"time"`
+}
+
+// Notifier fans out events to every subscriber. It is in-memory only: clients
+// that are not connected when an event is published do not receive it and
+// should fetch the persisted history instead.
+type Notifier struct {
+	mu   sync.RWMutex
+	subs map[chan Event]struct{}
+}
+
+func NewNotifier() *Notifier {
+	return &Notifier{subs: make(map[chan Event]struct{})}
+}
+
+// Subscribe registers a new listener. The returned cancel func must be called
+// when the listener goes away.
+func (n *Notifier) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, 32)
+	n.mu.Lock()
+	n.subs[ch] = struct{}{}
+	n.mu.Unlock()
+
+	return ch, func() {
+		n.mu.Lock()
+		if _, ok := n.subs[ch]; ok {
+			delete(n.subs, ch)
+			close(ch)
+		}
+		n.mu.Unlock()
+	}
+}
+
+// Publish delivers an event to all subscribers without blocking; a
+// subscriber whose buffer is full misses the event.
+func (n *Notifier) Publish(eventType string, data any) {
+	ev := Event{Type: eventType, Data: data, Time: time.Now().Unix()}
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	for ch := range n.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// ErrInvalidOverride is returned (wrapped) for malformed status overrides.
+var ErrInvalidOverride = errors.New("invalid status override")
+
+func overrideKey(stationID uint) string {
+	return fmt.Sprintf("station_override:%d", stationID)
+}
+
+// storedOverride returns the override saved for a station, expired or not.
+func (s *HealthService) storedOverride(stationID uint) *models.StationOverride {
+	var ov models.StationOverride
+	if err := s.db.GetJSON(overrideKey(stationID), &ov); err != nil {
+		return nil
+	}
+	return &ov
+}
+
+func overrideExpired(ov *models.StationOverride, now int64) bool {
+	return ov.ExpiresAt != nil && *ov.ExpiresAt <= now
+}
+
+// ActiveOverride returns the override in effect for a station, or nil.
+func (s *HealthService) ActiveOverride(stationID uint) *models.StationOverride {
+	ov := s.storedOverride(stationID)
+	if ov == nil || overrideExpired(ov, s.now().Unix()) {
+		return nil
+	}
+	return ov
+}
+
+// overrideStatus maps an override state to the station status it shows.
+func overrideStatus(state string) string {
+	if state == models.OverrideForcedActive {
+		return models.StationActive
+	}
+	return state
+}
+
+// SetOverride puts a station in the given override state, replacing any
+// previous override, and re-evaluates it immediately.
+func (s *HealthService) SetOverride(ov *models.StationOverride) (*models.StationHealth, error) {
+	if err := s.checkStation(ov.StationID); err != nil {
+		return nil, err
+	}
+	switch ov.State {
+	case models.OverrideMaintenance, models.OverrideOutOfService, models.OverrideForcedActive:
+	default:
+		return nil, fmt.Errorf("%w: state must be MAINTENANCE, OUT_OF_SERVICE or FORCED_ACTIVE", ErrInvalidOverride)
+	}
+	ov.Reason = strings.TrimSpace(ov.Reason)
+	if ov.Reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidOverride)
+	}
+	ov.SetAt = s.now().Unix()
+	if ov.ExpiresAt != nil && *ov.ExpiresAt <= ov.SetAt {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidOverride)
+	}
+	if err := s.db.PutJSON(overrideKey(ov.StationID), ov); err != nil {
+		return nil, err
+	}
+	return s.evaluate(ov.StationID, "")
+}
+
+// ClearOverride removes the override of a station and re-evaluates it.
+func (s *HealthService) ClearOverride(stationID uint, username string) (*models.StationHealth, error) {
+	if err := s.checkStation(stationID); err != nil {
+		return nil, err
+	}
+	if s.ActiveOverride(stationID) == nil {
+		return nil, errors.New("no active override")
+	}
+	if err := s.db.Delete(overrideKey(stationID)); err != nil {
+		return nil, err
+	}
+	return s.evaluate(stationID, "override cleared by "+username)
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// ErrInvalidPersonnel is returned (wrapped) for malformed personnel records.
+var ErrInvalidPersonnel = errors.New("invalid personnel")
+
+const (
+	DutyCommander = "COMMANDER"
+	DutyCrew      = "CREW"
+)
+
+// Duty is a watch a person is assigned to.
+type Duty struct {
+	Watch
+	StationName string `json:"station_name,omitempty"`
+	Role        string `json:"role"` // COMMANDER | CREW
+}
+
+// PersonnelService keeps the duty roster at "personnel:{id}". Schedules and
+// extra watches refer to people through CommanderID and CrewIDs.
+type PersonnelService struct {
+	db       *DB
+	schedSvc *ScheduleService
+	now      func() time.Time
+}
+
+func NewPersonnelService(db *DB, schedSvc *ScheduleService) *PersonnelService {
+	return &PersonnelService{db: db, schedSvc: schedSvc, now: schedSvc.now}
+}
+
+func personnelKey(id uint) string { return fmt.Sprintf("personnel:%d", id) }
+
+func (s *PersonnelService) validate(p *models.Personnel) error {
+	p.FullName = strings.TrimSpace(p.FullName)
+	if p.FullName == "" {
+		return fmt.Errorf("%w: full_name is required", ErrInvalidPersonnel)
+	}
+	p.Rank = strings.TrimSpace(p.Rank)
+	quals := p.Qualifications[:0]
+	for _, q := range p.Qualifications {
+		if q = strings.TrimSpace(q); q != "" {
+			quals = append(quals, q)
+		}
+	}
+	p.Qualifications = quals
+	if p.StationID != nil {
+		if ok, err := s.db.Exists(fmt.Sprintf("station:%d", *p.StationID)); err != nil {
+			return err
+		} else if !ok {
+			return errors.New("station not found")
+		}
+	}
+	p.Username = strings.TrimSpace(p.Username)
+	if p.Username == "" {
+		return nil
+	}
+	if ok, err := s.db.Exists("user:" + p.Username); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("%w: user %s not found", ErrInvalidPersonnel, p.Username)
+	}
+	// A user account belongs to one person.
+	if other, err := s.GetByUsername(p.Username); err == nil && other.ID != p.ID {
+		return fmt.Errorf("%w: user %s is already linked to personnel %d", ErrInvalidPersonnel, p.Username, other.ID)
+	}
+	return nil
+}
+
+// Create adds a person to the roster.
+func (s *PersonnelService) Create(p *models.Personnel) error {
+	p.ID = 0
+	if err := s.validate(p); err != nil {
+		return err
+	}
+	id, err := s.db.NextID("personnel_counter")
+	if err != nil {
+		return fmt.Errorf("failed to generate ID: %w", err)
+	}
+	p.ID = id
+	p.CreatedAt = s.now().Unix()
+	p.UpdatedAt = p.CreatedAt
+	return s.db.PutJSON(personnelKey(p.ID), p)
+}
+
+// GetByID retrieves a person by ID
+func (s *PersonnelService) GetByID(id uint) (*models.Personnel, error) {
+	var p models.Personnel
+	if err := s.db.GetJSON(personnelKey(id), &p); err != nil {
+		return nil, errors.New("personnel not found")
+	}
+	return &p, nil
+}
+
+// GetByUsername returns the person linked to a user account.
+func (s *PersonnelService) GetByUsername(username string) (*models.Personnel, error) {
+	all, err := s.List(0)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range all {
+		if p.Username == username {
+			return &p, nil
+		}
+	}
+	return nil, errors.New("personnel not found")
+}
+
+// Update saves a changed person. Names on existing schedules are refreshed
+// the next time those schedules are saved.
+func (s *PersonnelService) Update(p *models.Personnel) error {
+	existing, err := s.GetByID(p.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validate(p); err != nil {
+		return err
+	}
+	p.CreatedAt = existing.CreatedAt
+	p.UpdatedAt = s.now().Unix()
+	return s.db.PutJSON(personnelKey(p.ID), p)
+}
+
+// Delete removes a person who is not assigned to any schedule or extra
+// watch.
+func (s *PersonnelService) Delete(id uint) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	inUse := false
+	check := func(_ string, val []byte) error {
+		var ref struct {
+			CommanderID *uint  `json:"commander_id"`
+			CrewIDs     []uint `json:"crew_ids"`
+		}
+		if err := json.Unmarshal(val, &ref); err != nil {
+			return nil
+		}
+		w := Watch{CommanderID: ref.CommanderID, CrewIDs: ref.CrewIDs}
+		for _, p := range w.people() {
+			if p == id {
+				inUse = true
+			}
+		}
+		return nil
+	}
+	if err := s.db.IteratePrefix("schedule:", check); err != nil {
+		return err
+	}
+	if err := s.db.IteratePrefix("schedule_override:", check); err != nil {
+		return err
+	}
+	if inUse {
+		return errors.New("personnel is assigned to schedules")
+	}
+	return s.db.Delete(personnelKey(id))
+}
+
+// List returns the roster, or the people of one station when stationID > 0,
+// ordered by ID.
+func (s *PersonnelService) List(stationID uint) ([]models.Personnel, error) {
+	out := []models.Personnel{}
+	err := s.db.IteratePrefix("personnel:", func(_ string, val []byte) error {
+		var p models.Personnel
+		if err := json.Unmarshal(val, &p); err != nil {
+			return nil // Skip invalid records
+		}
+		if stationID == 0 || (p.StationID != nil && *p.StationID == stationID) {
+			out = append(out, p)
+		}
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, err
+}
+
+func (s *PersonnelService) stationNames() map[uint]string {
+	names := make(map[uint]string)
+	s.db.IteratePrefix("station:", func(_ string, val []byte) error {
+		var st models.Station
+		if err := json.Unmarshal(val, &st); err == nil {
+			names[st.ID] = st.Name
+		}
+		return nil
+	})
+	return names
+}
+
+// Duties returns the watches of a person overlapping [from, to), in order.
+func (s *PersonnelService) Duties(id uint, from, to int64) ([]Duty, error) {
+	if _, err := s.GetByID(id); err != nil {
+		return nil, err
+	}
+	watches, err := s.schedSvc.AllWatches(from, to)
+	if err != nil {
+		return nil, err
+	}
+	names := s.stationNames()
+	out := []Duty{}
+	for _, w := range watches {
+		for i, p := range w.people() {
+			if p != id {
+				continue
+			}
+			role := DutyCrew
+			if i == 0 && w.CommanderID != nil {
+				role = DutyCommander
+			}
+			out = append(out, Duty{Watch: w, StationName: names[w.StationID], Role: role})
+		}
+	}
+	return out, nil
+}
+
+// Conflicts returns every person on two overlapping watches within
+// [from, to), in order of time.
+func (s *PersonnelService) Conflicts(from, to int64) ([]DutyConflict, error) {
+	watches, err := s.schedSvc.AllWatches(from, to)
+	if err != nil {
+		return nil, err
+	}
+	byPerson := make(map[uint][]Watch)
+	for _, w := range watches {
+		for _, p := range w.people() {
+			byPerson[p] = append(byPerson[p], w)
+		}
+	}
+
+	out := []DutyConflict{}
+	for id, ws := range byPerson {
+		var p models.Personnel
+		s.db.GetJSON(personnelKey(id), &p) // Name stays empty for removed records
+		for i := range ws {
+			for j := i + 1; j < len(ws) && ws[j].Start < ws[i].End; j++ {
+				out = append(out, DutyConflict{PersonnelID: id, Name: personnelName(&p), Watch: ws[i], Other: ws[j]})
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Watch.Start != out[j].Watch.Start {
+			return out[i].Watch.Start < out[j].Watch.Start
+		}
+		return out[i].PersonnelID < out[j].PersonnelID
+	})
+	return out, nil
+}
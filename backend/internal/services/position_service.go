@@ -0,0 +1,134 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// ErrInvalidPosition is wrapped by every position report validation error.
+var ErrInvalidPosition = errors.New("invalid position")
+
+// PositionService keeps the latest reported position of each vessel at
+// "vessel_position:{vesselID}" and the full track at
+// "vessel_track:{vesselID}:{timestamp}" (zero-padded so keys sort by time).
+type PositionService struct {
+	db        *DB
+	vesselSvc *VesselService
+	listeners []func(models.VesselPosition)
+}
+
+func NewPositionService(db *DB, vesselSvc *VesselService) *PositionService {
+	return &PositionService{db: db, vesselSvc: vesselSvc}
+}
+
+// OnReport registers fn to be called after every stored position report.
+// Listeners must be registered before the service starts serving requests.
+func (s *PositionService) OnReport(fn func(models.VesselPosition)) {
+	s.listeners = append(s.listeners, fn)
+}
+
+// Report validates and stores a position report. The latest position is
+// only replaced when the report is not older than the one already stored.
+func (s *PositionService) Report(pos *models.VesselPosition) error {
+	if !validLatLon(pos.Latitude, pos.Longitude) {
+		return fmt.Errorf("%w: invalid coordinates", ErrInvalidPosition)
+	}
+	if pos.Course < 0 || pos.Course >= 360 {
+		return fmt.Errorf("%w: course must be in [0, 360)", ErrInvalidPosition)
+	}
+	if pos.Speed < 0 {
+		return fmt.Errorf("%w: speed must not be negative", ErrInvalidPosition)
+	}
+	if _, err := s.vesselSvc.GetByID(pos.VesselID); err != nil {
+		return err
+	}
+	if pos.Timestamp == 0 {
+		pos.Timestamp = time.Now().Unix()
+	}
+
+	trackKey := fmt.Sprintf("vessel_track:%d:%020d", pos.VesselID, pos.Timestamp)
+	if err := s.db.PutJSON(trackKey, pos); err != nil {
+		return fmt.Errorf("failed to store track point: %w", err)
+	}
+
+	latest, err := s.Latest(pos.VesselID)
+	if err != nil || latest.Timestamp <= pos.Timestamp {
+		key := fmt.Sprintf("vessel_position:%d", pos.VesselID)
+		if err := s.db.PutJSON(key, pos); err != nil {
+			return err
+		}
+	}
+
+	for _, fn := range s.listeners {
+		fn(*pos)
+	}
+	return nil
+}
+
+// Latest returns the most recent position of a vessel.
+func (s *PositionService) Latest(vesselID uint) (*models.VesselPosition, error) {
+	var pos models.VesselPosition
+	key := fmt.Sprintf("vessel_position:%d", vesselID)
+	if err := s.db.GetJSON(key, &pos); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, errors.New("position not found")
+		}
+		return nil, err
+	}
+	return &pos, nil
+}
+
+// ListLatest returns the latest position of every vessel that has reported.
+func (s *PositionService) ListLatest() ([]models.VesselPosition, error) {
+	var out []models.VesselPosition
+	err := s.db.IteratePrefix("vessel_position:", func(_ string, val []byte) error {
+		var pos models.VesselPosition
+		if err := json.Unmarshal(val, &pos); err != nil {
+			return nil // Skip invalid records
+		}
+		out = append(out, pos)
+		return nil
+	})
+	return out, err
+}
+
+// Track returns the positions of a vessel within [from, to] in time order.
+// A zero bound is treated as open.
+func (s *PositionService) Track(vesselID uint, from, to int64) ([]models.VesselPosition, error) {
+	prefix := fmt.Sprintf("vessel_track:%d:", vesselID)
+	var out []models.VesselPosition
+	err := s.db.IteratePrefix(prefix, func(_ string, val []byte) error {
+		var pos models.VesselPosition
+		if err := json.Unmarshal(val, &pos); err != nil {
+			return nil // Skip invalid records
+		}
+		if (from == 0 || pos.Timestamp >= from) && (to == 0 || pos.Timestamp <= to) {
+			out = append(out, pos)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// DeleteByVessel removes the latest position and the track of a vessel.
+func (s *PositionService) DeleteByVessel(vesselID uint) error {
+	s.db.Delete(fmt.Sprintf("vessel_position:%d", vesselID)) // Ignore error
+	prefix := fmt.Sprintf("vessel_track:%d:", vesselID)
+	var keys []string
+	if err := s.db.IteratePrefix(prefix, func(key string, _ []byte) error {
+		keys = append(keys, key)
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := s.db.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// EncounterOptions controls which vessel pairs are reported as encounters.
+// Distances are in nautical miles, times in minutes.
+type EncounterOptions struct {
+	CPAThreshold  float64 // report pairs whose CPA is at most this (NM)
+	TCPAThreshold float64 // ... and whose CPA happens within this many minutes
+	SearchRadius  float64 // only pair vessels currently closer than this (NM)
+	CoverageRange float64 // only consider vessels this close to a station (NM)
+	StationID     uint    // restrict to one station's coverage (0 = all)
+	MaxAge        int64   // ignore positions older than this (seconds, 0 = no limit)
+}
+
+// DefaultEncounterOptions returns the thresholds used when the caller does
+// not override them.
+func DefaultEncounterOptions() EncounterOptions {
+	return EncounterOptions{
+		CPAThreshold:  0.5,
+		TCPAThreshold: 30,
+		SearchRadius:  12,
+		CoverageRange: 48,
+		MaxAge:        900,
+	}
+}
+
+// RiskService computes collision risk (CPA/TCPA) between tracked vessels.
+type RiskService struct {
+	positionSvc *PositionService
+	stationSvc  *StationService
+	now         func() time.Time
+}
+
+func NewRiskService(positionSvc *PositionService, stationSvc *StationService) *RiskService {
+	return &RiskService{positionSvc: positionSvc, stationSvc: stationSvc, now: stationSvc.now}
+}
+
+// track is a vessel position dead-reckoned to a common instant and projected
+// onto a local flat plane (x east, y north, in NM).
+type track struct {
+	vesselID   uint
+	lat, lon   float64
+	x, y       float64
+	vx, vy     float64 // knots
+	stationID  uint
+	stationDst float64
+}
+
+// Encounters evaluates every pair of vessels within the search radius of each
+// other and returns the pairs whose predicted CPA and TCPA fall inside the
+// thresholds, ordered by TCPA.
+func (s *RiskService) Encounters(opts EncounterOptions) ([]models.Encounter, error) {
+	positions, err := s.positionSvc.ListLatest()
+	if err != nil {
+		return nil, err
+	}
+	stations, err := s.stationSvc.List()
+	if err != nil {
+		return nil, err
+	}
+	if opts.StationID != 0 {
+		var filtered []*models.Station
+		for _, st := range stations {
+			if st.ID == opts.StationID {
+				filtered = append(filtered, st)
+			}
+		}
+		stations = filtered
+	}
+
+	now := s.now().Unix()
+	var tracks []track
+	for _, p := range positions {
+		if opts.MaxAge > 0 && now-p.Timestamp > opts.MaxAge {
+			continue
+		}
+		// Dead-reckon to now so every vessel is compared at the same instant.
+		lat, lon := p.Latitude, p.Longitude
+		if dt := float64(now - p.Timestamp); dt > 0 && p.Speed > 0 {
+			lat, lon = destinationPoint(lat, lon, p.Course, p.Speed*dt/3600*kmPerNM)
+		}
+
+		t := track{vesselID: p.VesselID, lat: lat, lon: lon, stationDst: math.Inf(1)}
+		for _, st := range stations {
+			d := haversineKm(lat, lon, st.Latitude, st.Longitude) / kmPerNM
+			if d <= opts.CoverageRange && d < t.stationDst {
+				t.stationID, t.stationDst = st.ID, d
+			}
+		}
+		if t.stationID == 0 {
+			continue
+		}
+		rad := toRad(p.Course)
+		t.vx, t.vy = p.Speed*math.Sin(rad), p.Speed*math.Cos(rad)
+		tracks = append(tracks, t)
+	}
+	if len(tracks) < 2 {
+		return []models.Encounter{}, nil
+	}
+
+	// Project onto an equirectangular plane around the mean latitude; good
+	// enough for pairs a few dozen miles apart.
+	var meanLat float64
+	for _, t := range tracks {
+		meanLat += t.lat
+	}
+	cosLat := math.Cos(toRad(meanLat / float64(len(tracks))))
+	for i := range tracks {
+		tracks[i].x = tracks[i].lon * 60 * cosLat
+		tracks[i].y = tracks[i].lat * 60
+	}
+
+	out := []models.Encounter{}
+	for _, pair := range gridPairs(tracks, opts.SearchRadius) {
+		a, b := tracks[pair[0]], tracks[pair[1]]
+		rng := haversineKm(a.lat, a.lon, b.lat, b.lon) / kmPerNM
+		if rng > opts.SearchRadius {
+			continue
+		}
+		cpa, tcpa := cpaTCPA(b.x-a.x, b.y-a.y, b.vx-a.vx, b.vy-a.vy)
+		tcpaMin := tcpa * 60
+		if cpa > opts.CPAThreshold || tcpaMin < 0 || tcpaMin > opts.TCPAThreshold {
+			continue
+		}
+		stationID := a.stationID
+		if b.stationDst < a.stationDst {
+			stationID = b.stationID
+		}
+		if a.vesselID > b.vesselID {
+			a, b = b, a
+		}
+		out = append(out, models.Encounter{
+			VesselA:   a.vesselID,
+			VesselB:   b.vesselID,
+			StationID: stationID,
+			Range:     rng,
+			CPA:       cpa,
+			TCPA:      tcpaMin,
+			Bearing:   initialBearing(a.lat, a.lon, b.lat, b.lon),
+		})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].TCPA != out[j].TCPA {
+			return out[i].TCPA < out[j].TCPA
+		}
+		return out[i].CPA < out[j].CPA
+	})
+	return out, nil
+}
+
+// cpaTCPA returns the closest point of approach (same unit as the position)
+// and the time to reach it (position unit / velocity unit) for a target at
+// relative position (rx, ry) moving with relative velocity (vx, vy).
+// A negative TCPA means the vessels are already opening.
+func cpaTCPA(rx, ry, vx, vy float64) (cpa, tcpa float64) {
+	v2 := vx*vx + vy*vy
+	if v2 < 1e-9 {
+		// No relative motion: the range never changes.
+		return math.Hypot(rx, ry), 0
+	}
+	tcpa = -(rx*vx + ry*vy) / v2
+	return math.Hypot(rx+vx*tcpa, ry+vy*tcpa), tcpa
+}
+
+// gridPairs buckets tracks into square cells of the given size and returns
+// index pairs (i < j) of tracks in the same or adjacent cells. Each vessel is
+// only compared to its neighbours, so the cost grows with local density
+// rather than with the square of the fleet size.
+func gridPairs(tracks []track, cell float64) [][2]int {
+	if cell <= 0 {
+		cell = 1
+	}
+	type cellKey struct{ cx, cy int64 }
+	grid := make(map[cellKey][]int)
+	keys := make([]cellKey, len(tracks))
+	for i, t := range tracks {
+		k := cellKey{int64(math.Floor(t.x / cell)), int64(math.Floor(t.y / cell))}
+		keys[i] = k
+		grid[k] = append(grid[k], i)
+	}
+
+	var pairs [][2]int
+	for i, k := range keys {
+		for dx := int64(-1); dx <= 1; dx++ {
+			for dy := int64(-1); dy <= 1; dy++ {
+				for _, j := range grid[cellKey{k.cx + dx, k.cy + dy}] {
+					if j > i {
+						pairs = append(pairs, [2]int{i, j})
+					}
+				}
+			}
+		}
+	}
+	return pairs
+}
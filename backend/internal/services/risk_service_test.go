@@ -0,0 +1,75 @@
+package services
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// vessel is a ship on the local plane: position in NM, course in degrees,
+// speed in knots.
+type vessel struct{ x, y, course, speed float64 }
+
+func (v vessel) velocity() (float64, float64) {
+	rad := toRad(v.course)
+	return v.speed * math.Sin(rad), v.speed * math.Cos(rad)
+}
+
+func TestCPATCPA(t *testing.T) {
+	tests := []struct {
+		name     string
+		own, tgt vessel
+		cpa      float64 // NM
+		tcpa     float64 // minutes
+	}{
+		{"head-on", vessel{0, 0, 0, 12}, vessel{0, 6, 180, 12}, 0, 15},
+		{"head-on passing starboard", vessel{0, 0, 0, 12}, vessel{0.5, 6, 180, 12}, 0.5, 15},
+		{"crossing collision", vessel{0, 0, 0, 10}, vessel{5, 5, 270, 10}, 0, 30},
+		{"crossing ahead", vessel{0, 0, 0, 5}, vessel{5, 5, 270, 10}, math.Sqrt(5), 36},
+		{"overtaking", vessel{0, 0, 0, 15}, vessel{0, 2, 0, 10}, 0, 24},
+		{"overtaking abeam", vessel{0, 0, 0, 15}, vessel{0.3, 2, 0, 10}, 0.3, 24},
+		{"diverging", vessel{0, 0, 180, 10}, vessel{1, 2, 0, 10}, 1, -6},
+		{"parallel same speed", vessel{0, 0, 0, 10}, vessel{1, 0, 0, 10}, 1, 0},
+		{"both stopped", vessel{0, 0, 0, 0}, vessel{3, 4, 90, 0}, 5, 0},
+	}
+	for _, tt := range tests {
+		ovx, ovy := tt.own.velocity()
+		tvx, tvy := tt.tgt.velocity()
+		cpa, tcpa := cpaTCPA(tt.tgt.x-tt.own.x, tt.tgt.y-tt.own.y, tvx-ovx, tvy-ovy)
+		if math.Abs(cpa-tt.cpa) > 1e-6 || math.Abs(tcpa*60-tt.tcpa) > 1e-6 {
+			t.Errorf("%s: CPA %.4f NM, TCPA %.4f min; want %.4f NM, %.4f min", tt.name, cpa, tcpa*60, tt.cpa, tt.tcpa)
+		}
+	}
+}
+
+func TestGridPairsMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, radius := range []float64{0.5, 3, 12} {
+		tracks := make([]track, 500)
+		for i := range tracks {
+			tracks[i].x = rng.Float64()*200 - 100
+			tracks[i].y = rng.Float64()*200 - 100
+		}
+
+		got := make(map[[2]int]bool)
+		for _, p := range gridPairs(tracks, radius) {
+			if p[0] >= p[1] || got[p] {
+				t.Fatalf("radius %v: pair %v out of order or repeated", radius, p)
+			}
+			got[p] = true
+		}
+		// Pairs within the radius must all be found; farther ones from
+		// adjacent cells may be returned too.
+		for i := range tracks {
+			for j := i + 1; j < len(tracks); j++ {
+				d := math.Hypot(tracks[i].x-tracks[j].x, tracks[i].y-tracks[j].y)
+				if d <= radius && !got[[2]int{i, j}] {
+					t.Errorf("radius %v: pair (%d, %d) %.3f NM apart missing", radius, i, j, d)
+				}
+				if got[[2]int{i, j}] && d > 2*math.Sqrt2*radius {
+					t.Errorf("radius %v: pair (%d, %d) %.3f NM apart is not in adjacent cells", radius, i, j, d)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// dutyCheckDays is how far ahead new assignments are checked for people on
+// two watches at once.
+const dutyCheckDays = 60
+
+// DutyConflict is a person assigned to two watches that overlap in time.
+type DutyConflict struct {
+	PersonnelID uint   `json:"personnel_id"`
+	Name        string `json:"name"`
+	Watch       Watch  `json:"watch"`
+	Other       Watch  `json:"conflicts_with"`
+}
+
+// DutyConflictError lists the people a schedule would double-book.
+type DutyConflictError struct {
+	Conflicts []DutyConflict
+}
+
+func (e *DutyConflictError) Error() string {
+	names := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		names[i] = fmt.Sprintf("%s (station %d)", c.Name, c.Other.StationID)
+	}
+	return "personnel already on duty: " + strings.Join(names, ", ")
+}
+
+// people returns the personnel IDs assigned to a watch, commander first.
+func (w *Watch) people() []uint {
+	var ids []uint
+	if w.CommanderID != nil {
+		ids = append(ids, *w.CommanderID)
+	}
+	return append(ids, w.CrewIDs...)
+}
+
+func personnelName(p *models.Personnel) string {
+	return strings.TrimSpace(p.Rank + " " + p.FullName)
+}
+
+// assign looks up the assigned personnel and returns the commander, crew and
+// phone texts derived from their records. A nil commander and empty crew
+// leave the texts unchanged; PreviewUpdate clears them when the people are
+// removed.
+func (s *ScheduleService) assign(commanderID *uint, crewIDs []uint, commander, crew, phone string) (string, string, string, error) {
+	seen := make(map[uint]bool)
+	get := func(id uint) (*models.Personnel, error) {
+		if seen[id] {
+			return nil, fmt.Errorf("%w: personnel %d is assigned twice", ErrInvalidSchedule, id)
+		}
+		seen[id] = true
+		var p models.Personnel
+		if err := s.db.GetJSON(personnelKey(id), &p); err != nil {
+			return nil, fmt.Errorf("%w: personnel %d not found", ErrInvalidSchedule, id)
+		}
+		return &p, nil
+	}
+	if commanderID != nil {
+		p, err := get(*commanderID)
+		if err != nil {
+			return "", "", "", err
+		}
+		commander = personnelName(p)
+		if p.Phone != "" {
+			phone = p.Phone
+		}
+	}
+	if len(crewIDs) > 0 {
+		names := make([]string, 0, len(crewIDs))
+		for _, id := range crewIDs {
+			p, err := get(id)
+			if err != nil {
+				return "", "", "", err
+			}
+			names = append(names, personnelName(p))
+		}
+		crew = strings.Join(names, ", ")
+	}
+	return commander, crew, phone, nil
+}
+
+// stationIDs lists the IDs of all stations.
+func (s *ScheduleService) stationIDs() ([]uint, error) {
+	var ids []uint
+	err := s.db.IteratePrefix("station:", func(_ string, val []byte) error {
+		var st struct {
+			ID uint `json:"id"`
+		}
+		if err := json.Unmarshal(val, &st); err == nil {
+			ids = append(ids, st.ID)
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// AllWatches returns the watches of every station overlapping [from, to).
+func (s *ScheduleService) AllWatches(from, to int64) ([]Watch, error) {
+	ids, err := s.stationIDs()
+	if err != nil {
+		return nil, err
+	}
+	var out []Watch
+	for _, id := range ids {
+		ws, err := s.Watches(id, from, to)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ws...)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Start < out[j].Start })
+	return out, nil
+}
+
+// dutyConflicts checks the people of candidate watches against all existing
+// watches, except those skip matches (the schedule being replaced).
+func (s *ScheduleService) dutyConflicts(candidates []Watch, skip func(*Watch) bool) error {
+	if len(candidates) == 0 {
+		return nil
+	}
+	from, to := candidates[0].Start, candidates[0].End
+	for _, c := range candidates {
+		from, to = min(from, c.Start), max(to, c.End)
+	}
+	existing, err := s.AllWatches(from, to)
+	if err != nil {
+		return err
+	}
+
+	var conflicts []DutyConflict
+	reported := make(map[[2]uint]bool) // person, station: one conflict each
+	for _, c := range candidates {
+		for _, id := range c.people() {
+			for i := range existing {
+				w := &existing[i]
+				if skip(w) || w.Start >= c.End || c.Start >= w.End || reported[[2]uint{id, w.StationID}] {
+					continue
+				}
+				for _, other := range w.people() {
+					if other != id {
+						continue
+					}
+					var p models.Personnel
+					s.db.GetJSON(personnelKey(id), &p) // Checked by assign
+					conflicts = append(conflicts, DutyConflict{PersonnelID: id, Name: personnelName(&p), Watch: c, Other: *w})
+					reported[[2]uint{id, w.StationID}] = true
+				}
+			}
+		}
+	}
+	if len(conflicts) > 0 {
+		return &DutyConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// checkAssignments fills in the commander, crew and phone of a schedule from
+// its assigned personnel and checks that none of them is on another watch at
+// the same time within the next dutyCheckDays days.
+func (s *ScheduleService) checkAssignments(sc *models.Schedule) error {
+	var err error
+	sc.Commander, sc.Crew, sc.Phone, err = s.assign(sc.CommanderID, sc.CrewIDs, sc.Commander, sc.Crew, sc.Phone)
+	if err != nil || (sc.CommanderID == nil && len(sc.CrewIDs) == 0) {
+		return err
+	}
+	now := s.now()
+	candidates := scheduleWatches(sc, s.zone(sc.StationID), now.Unix(), now.AddDate(0, 0, dutyCheckDays).Unix())
+	return s.dutyConflicts(candidates, func(w *Watch) bool {
+		return sc.ID != 0 && w.StationID == sc.StationID && w.ScheduleID == sc.ID
+	})
+}
+
+// checkOverrideAssignments is checkAssignments for an extra watch.
+func (s *ScheduleService) checkOverrideAssignments(o *models.ScheduleOverride) error {
+	var err error
+	o.Commander, o.Crew, o.Phone, err = s.assign(o.CommanderID, o.CrewIDs, o.Commander, o.Crew, o.Phone)
+	if err != nil || o.Kind != models.ScheduleOverrideExtra || (o.CommanderID == nil && len(o.CrewIDs) == 0) {
+		return err
+	}
+	var candidates []Watch
+	loc := s.zone(o.StationID)
+	if t, err := time.ParseInLocation("2006-01-02", o.Date, loc); err == nil {
+		if sp, ok := watchSpan(t, o.StartHHMM, o.EndHHMM); ok {
+			candidates = append(candidates, Watch{StationID: o.StationID, Start: sp[0], End: sp[1], CommanderID: o.CommanderID, CrewIDs: o.CrewIDs})
+		}
+	}
+	return s.dutyConflicts(candidates, func(*Watch) bool { return false })
+}
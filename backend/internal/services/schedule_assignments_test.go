@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+func TestUnassignClearsRosterTexts(t *testing.T) {
+	zero := uint(0)
+	tests := []struct {
+		name                   string
+		updates                map[string]interface{}
+		commander, crew, phone string
+	}{
+		{"remove commander", map[string]interface{}{"commander_id": &zero}, "", "Le Van B, Tran Thi C", ""},
+		{"remove crew", map[string]interface{}{"crew_ids": []uint{}}, "Dai uy Nguyen Van A", "", "0901000001"},
+		{"remove both", map[string]interface{}{"commander_id": &zero, "crew_ids": []uint{}}, "", "", ""},
+		{"remove with new texts", map[string]interface{}{"commander_id": &zero, "commander": "Duty officer", "phone": "0909", "crew_ids": []uint{}, "crew": "Reserve team"}, "Duty officer", "Reserve team", "0909"},
+		{"unrelated edit", map[string]interface{}{"end_hhmm": "1700"}, "Dai uy Nguyen Van A", "Le Van B, Tran Thi C", "0901000001"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			for _, p := range []models.Personnel{
+				{ID: 1, FullName: "Nguyen Van A", Rank: "Dai uy", Phone: "0901000001"},
+				{ID: 2, FullName: "Le Van B"},
+				{ID: 3, FullName: "Tran Thi C"},
+			} {
+				if err := db.PutJSON(personnelKey(p.ID), p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			svc := NewScheduleService(db, nil)
+			commander := uint(1)
+			sc := &models.Schedule{StationID: 1, StartHHMM: "0800", EndHHMM: "1600", CommanderID: &commander, CrewIDs: []uint{2, 3}}
+			if err := svc.Create(sc); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			got, err := svc.UpdatePartial(1, sc.ID, tt.updates)
+			if err != nil {
+				t.Fatalf("UpdatePartial: %v", err)
+			}
+			if got.Commander != tt.commander || got.Crew != tt.crew || got.Phone != tt.phone {
+				t.Errorf("commander %q, crew %q, phone %q; want %q, %q, %q", got.Commander, got.Crew, got.Phone, tt.commander, tt.crew, tt.phone)
+			}
+		})
+	}
+}
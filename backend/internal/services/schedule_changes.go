@@ -0,0 +1,261 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+const EventScheduleChange = "schedule_change"
+
+// scheduleApprovalSetting holds whether schedule writes need HQ approval.
+const scheduleApprovalSetting = "setting:schedule_approval"
+
+// ScheduleChangeService keeps proposed schedule changes at
+// "schedule_change:{id}". When approval is required, schedule writes are
+// queued here and applied only once HQ approves them.
+type ScheduleChangeService struct {
+	db       *DB
+	schedSvc *ScheduleService
+	notifier *Notifier
+	now      func() time.Time
+	mu       sync.Mutex // one review at a time, so a change is never applied twice
+}
+
+func NewScheduleChangeService(db *DB, schedSvc *ScheduleService, notifier *Notifier) *ScheduleChangeService {
+	return &ScheduleChangeService{db: db, schedSvc: schedSvc, notifier: notifier, now: schedSvc.now}
+}
+
+func scheduleChangeKey(id uint) string { return fmt.Sprintf("schedule_change:%d", id) }
+
+// RequireApproval reports whether schedule writes need HQ approval. It is
+// off until switched on.
+func (s *ScheduleChangeService) RequireApproval() bool {
+	var on bool
+	s.db.GetJSON(scheduleApprovalSetting, &on) // Missing means off
+	return on
+}
+
+// SetRequireApproval switches the approval flow on or off. Pending changes
+// stay in the queue either way.
+func (s *ScheduleChangeService) SetRequireApproval(on bool) error {
+	return s.db.PutJSON(scheduleApprovalSetting, on)
+}
+
+func (s *ScheduleChangeService) checkStation(stationID uint) error {
+	if ok, err := s.db.Exists(fmt.Sprintf("station:%d", stationID)); err != nil {
+		return err
+	} else if !ok {
+		return errors.New("station not found")
+	}
+	return nil
+}
+
+// Submit checks a proposed change against the current schedules and queues
+// it for approval. UPDATE changes carry the full updated schedule, override
+// changes the one-off override. An emergency change is applied at once and
+// logged.
+func (s *ScheduleChangeService) Submit(ch *models.ScheduleChange) error {
+	ch.Reason = strings.TrimSpace(ch.Reason)
+	if ch.Emergency && ch.Reason == "" {
+		return fmt.Errorf("%w: a reason is required for an emergency change", ErrInvalidSchedule)
+	}
+	switch ch.Action {
+	case models.ScheduleChangeCreate, models.ScheduleChangeUpdate:
+		if ch.Schedule == nil {
+			return fmt.Errorf("%w: the proposed schedule is required", ErrInvalidSchedule)
+		}
+	case models.ScheduleChangeCreateOverride:
+		if ch.Override == nil {
+			return fmt.Errorf("%w: the proposed override is required", ErrInvalidSchedule)
+		}
+	}
+	switch ch.Action {
+	case models.ScheduleChangeCreate:
+		if err := s.checkStation(ch.StationID); err != nil {
+			return err
+		}
+		ch.ScheduleID, ch.Schedule.ID, ch.Schedule.StationID = 0, 0, ch.StationID
+		if err := s.schedSvc.validate(ch.Schedule); err != nil {
+			return err
+		}
+	case models.ScheduleChangeUpdate, models.ScheduleChangeDelete:
+		prev, err := s.schedSvc.GetByID(ch.StationID, ch.ScheduleID)
+		if err != nil {
+			return err
+		}
+		ch.Previous = prev
+	case models.ScheduleChangeCreateOverride:
+		if err := s.checkStation(ch.StationID); err != nil {
+			return err
+		}
+		ch.OverrideID, ch.Override.ID, ch.Override.StationID = 0, 0, ch.StationID
+		if err := s.schedSvc.validateOverride(ch.Override); err != nil {
+			return err
+		}
+	case models.ScheduleChangeDeleteOverride:
+		o, err := s.schedSvc.GetOverride(ch.StationID, ch.OverrideID)
+		if err != nil {
+			return err
+		}
+		ch.Override = o
+	default:
+		return fmt.Errorf("%w: action must be CREATE, UPDATE, DELETE, CREATE_OVERRIDE or DELETE_OVERRIDE", ErrInvalidSchedule)
+	}
+
+	id, err := s.db.NextID("schedule_change_counter")
+	if err != nil {
+		return fmt.Errorf("failed to generate ID: %w", err)
+	}
+	ch.ID = id
+	ch.Status = models.ScheduleChangePending
+	ch.RequestedAt = s.now().Unix()
+	ch.ReviewedBy, ch.ReviewedAt, ch.ReviewComment = "", nil, ""
+
+	if ch.Emergency {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if err := s.apply(ch); err != nil {
+			return err
+		}
+		ch.Status = models.ScheduleChangeApproved
+		log.Printf("Emergency schedule change %d (%s schedule %d override %d at station %d) by %s: %s",
+			ch.ID, ch.Action, ch.ScheduleID, ch.OverrideID, ch.StationID, ch.RequestedBy, ch.Reason)
+	}
+	if err := s.db.PutJSON(scheduleChangeKey(ch.ID), ch); err != nil {
+		return err
+	}
+	s.notifier.Publish(EventScheduleChange, ch)
+	return nil
+}
+
+// apply carries out a change. It fails when the schedule or override was
+// changed or removed after the proposal, or no longer fits the station's
+// schedules.
+func (s *ScheduleChangeService) apply(ch *models.ScheduleChange) error {
+	switch ch.Action {
+	case models.ScheduleChangeCreate:
+		sc := *ch.Schedule
+		if err := s.schedSvc.Create(&sc); err != nil {
+			return err
+		}
+		ch.ScheduleID, ch.Schedule = sc.ID, &sc
+		return nil
+	case models.ScheduleChangeCreateOverride:
+		o := *ch.Override
+		if err := s.schedSvc.CreateOverride(&o); err != nil {
+			return err
+		}
+		ch.OverrideID, ch.Override = o.ID, &o
+		return nil
+	case models.ScheduleChangeDeleteOverride:
+		current, err := s.schedSvc.GetOverride(ch.StationID, ch.OverrideID)
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(current, ch.Override) {
+			return errors.New("schedule changed since the proposal")
+		}
+		return s.schedSvc.DeleteOverride(ch.StationID, ch.OverrideID)
+	}
+
+	current, err := s.schedSvc.GetByID(ch.StationID, ch.ScheduleID)
+	if err != nil {
+		return err
+	}
+	if ch.Previous != nil && !reflect.DeepEqual(current, ch.Previous) {
+		return errors.New("schedule changed since the proposal")
+	}
+	if ch.Action == models.ScheduleChangeDelete {
+		return s.schedSvc.Delete(ch.StationID, ch.ScheduleID)
+	}
+	sc := *ch.Schedule
+	sc.ID, sc.StationID = ch.ScheduleID, ch.StationID
+	if err := s.schedSvc.Update(&sc); err != nil {
+		return err
+	}
+	ch.Schedule = &sc
+	return nil
+}
+
+// GetByID retrieves a schedule change.
+func (s *ScheduleChangeService) GetByID(id uint) (*models.ScheduleChange, error) {
+	var ch models.ScheduleChange
+	if err := s.db.GetJSON(scheduleChangeKey(id), &ch); err != nil {
+		return nil, errors.New("change not found")
+	}
+	return &ch, nil
+}
+
+func (s *ScheduleChangeService) review(id uint, reviewer, comment string, approve bool) (*models.ScheduleChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if ch.Status != models.ScheduleChangePending {
+		return nil, errors.New("change is not pending")
+	}
+	ch.Status = models.ScheduleChangeRejected
+	if approve {
+		if err := s.apply(ch); err != nil {
+			return nil, err
+		}
+		ch.Status = models.ScheduleChangeApproved
+	}
+	now := s.now().Unix()
+	ch.ReviewedBy, ch.ReviewedAt, ch.ReviewComment = reviewer, &now, comment
+	if err := s.db.PutJSON(scheduleChangeKey(ch.ID), ch); err != nil {
+		return nil, err
+	}
+	s.notifier.Publish(EventScheduleChange, ch)
+	return ch, nil
+}
+
+// Approve applies a pending change. If it no longer applies (see apply) the
+// change stays pending.
+func (s *ScheduleChangeService) Approve(id uint, reviewer, comment string) (*models.ScheduleChange, error) {
+	return s.review(id, reviewer, strings.TrimSpace(comment), true)
+}
+
+// Reject turns down a pending change; a comment is required.
+func (s *ScheduleChangeService) Reject(id uint, reviewer, comment string) (*models.ScheduleChange, error) {
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return nil, fmt.Errorf("%w: a comment is required to reject a change", ErrInvalidSchedule)
+	}
+	return s.review(id, reviewer, comment, false)
+}
+
+// List returns the changes of a station (all stations when stationID is 0)
+// with the given status ("" for any). Pending changes come oldest first,
+// others newest first.
+func (s *ScheduleChangeService) List(stationID uint, status string) ([]models.ScheduleChange, error) {
+	out := []models.ScheduleChange{}
+	err := s.db.IteratePrefix("schedule_change:", func(_ string, val []byte) error {
+		var ch models.ScheduleChange
+		if err := json.Unmarshal(val, &ch); err != nil {
+			return nil // Skip invalid records
+		}
+		if (stationID == 0 || ch.StationID == stationID) && (status == "" || ch.Status == status) {
+			out = append(out, ch)
+		}
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool {
+		if status == models.ScheduleChangePending {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].ID > out[j].ID
+	})
+	return out, err
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CoverageSpan is a period with the same set of stations on schedule.
+type CoverageSpan struct {
+	From      int64 `json:"from"`
+	To        int64 `json:"to"`
+	StationID uint  `json:"station_id,omitempty"` // the only station on schedule, for single points of failure
+}
+
+// HourlyCoverage counts the stations on schedule within one hour of the
+// report's time zone.
+type HourlyCoverage struct {
+	From       int64  `json:"from"`
+	To         int64  `json:"to"`
+	MinActive  int    `json:"min_active"` // fewest stations on schedule at any time in the hour
+	MaxActive  int    `json:"max_active"`
+	StationIDs []uint `json:"station_ids"` // stations on schedule at some time in the hour
+}
+
+// ScheduleCoverage shows when the schedules of a set of stations leave the
+// area unwatched (no station on schedule) or depend on a single station.
+type ScheduleCoverage struct {
+	From                  int64            `json:"from"`
+	To                    int64            `json:"to"`
+	StationIDs            []uint           `json:"station_ids"`
+	TimeZone              string           `json:"time_zone"` // of the hourly rows and the CSV times
+	UncoveredHours        float64          `json:"uncovered_hours"`
+	SingleStationHours    float64          `json:"single_station_hours"`
+	CoveredPercent        float64          `json:"covered_percent"`
+	Uncovered             []CoverageSpan   `json:"uncovered"`
+	SinglePointsOfFailure []CoverageSpan   `json:"single_points_of_failure"`
+	Hourly                []HourlyCoverage `json:"hourly"`
+
+	loc *time.Location
+}
+
+// coverageSegment is a piece of the period with a fixed set of stations on
+// schedule.
+type coverageSegment struct {
+	from, to int64
+	stations []uint
+}
+
+// hourStart returns the start of the hour in loc that contains t.
+func hourStart(t int64, loc *time.Location) int64 {
+	_, offset := time.Unix(t, 0).In(loc).Zone()
+	local := t + int64(offset)
+	return t - (local%3600+3600)%3600
+}
+
+// ScheduleCoverage merges the scheduled intervals of the given stations over
+// [from, to) (Unix seconds). Hours are aligned to whole hours of the IANA
+// time zone tz ("" = UTC), so zones with half-hour offsets get local hours.
+func (s *ScheduleService) ScheduleCoverage(stationIDs []uint, from, to int64, tz string) (*ScheduleCoverage, error) {
+	loc := time.UTC
+	if tz != "" {
+		var err error
+		if loc, err = loadZone(tz); err != nil {
+			return nil, err
+		}
+	}
+	type event struct {
+		at      int64
+		station uint
+		on      bool
+	}
+	var events []event
+	for _, id := range stationIDs {
+		spans, err := s.ScheduledIntervals(id, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, sp := range spans {
+			events = append(events, event{sp[0], id, true}, event{sp[1], id, false})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].at < events[j].at })
+
+	// Sweep the period into segments with a fixed set of active stations.
+	var segments []coverageSegment
+	active := make(map[uint]bool)
+	at, i := from, 0
+	for at < to {
+		for ; i < len(events) && events[i].at <= at; i++ {
+			if events[i].on {
+				active[events[i].station] = true
+			} else {
+				delete(active, events[i].station)
+			}
+		}
+		next := to
+		if i < len(events) && events[i].at < to {
+			next = events[i].at
+		}
+		ids := make([]uint, 0, len(active))
+		for id := range active {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(a, b int) bool { return ids[a] < ids[b] })
+		segments = append(segments, coverageSegment{at, next, ids})
+		at = next
+	}
+
+	cov := &ScheduleCoverage{From: from, To: to, StationIDs: stationIDs, TimeZone: loc.String(), loc: loc,
+		Uncovered: []CoverageSpan{}, SinglePointsOfFailure: []CoverageSpan{}, Hourly: []HourlyCoverage{}}
+	extend := func(list []CoverageSpan, seg coverageSegment, station uint) []CoverageSpan {
+		if n := len(list); n > 0 && list[n-1].To == seg.from && list[n-1].StationID == station {
+			list[n-1].To = seg.to
+			return list
+		}
+		return append(list, CoverageSpan{From: seg.from, To: seg.to, StationID: station})
+	}
+	var uncovered, single int64
+	for _, seg := range segments {
+		switch len(seg.stations) {
+		case 0:
+			cov.Uncovered = extend(cov.Uncovered, seg, 0)
+			uncovered += seg.to - seg.from
+		case 1:
+			cov.SinglePointsOfFailure = extend(cov.SinglePointsOfFailure, seg, seg.stations[0])
+			single += seg.to - seg.from
+		}
+	}
+	cov.UncoveredHours = float64(uncovered) / 3600
+	cov.SingleStationHours = float64(single) / 3600
+	if to > from {
+		cov.CoveredPercent = 100 * float64(to-from-uncovered) / float64(to-from)
+	}
+
+	k := 0
+	for h := hourStart(from, loc); h < to; {
+		next := hourStart(h+3600, loc)
+		if next <= h {
+			next = h + 3600
+		}
+		hc := HourlyCoverage{From: max(h, from), To: min(next, to), MinActive: -1, StationIDs: []uint{}}
+		seen := make(map[uint]bool)
+		for ; k < len(segments) && segments[k].to <= hc.From; k++ {
+		}
+		for j := k; j < len(segments) && segments[j].from < hc.To; j++ {
+			n := len(segments[j].stations)
+			if hc.MinActive < 0 || n < hc.MinActive {
+				hc.MinActive = n
+			}
+			hc.MaxActive = max(hc.MaxActive, n)
+			for _, id := range segments[j].stations {
+				if !seen[id] {
+					seen[id] = true
+					hc.StationIDs = append(hc.StationIDs, id)
+				}
+			}
+		}
+		hc.MinActive = max(hc.MinActive, 0)
+		sort.Slice(hc.StationIDs, func(a, b int) bool { return hc.StationIDs[a] < hc.StationIDs[b] })
+		cov.Hourly = append(cov.Hourly, hc)
+		h = next
+	}
+	return cov, nil
+}
+
+// WriteScheduleCoverageCSV writes a coverage report as CSV, one row per
+// uncovered span ("uncovered"), single point of failure ("single") and hour
+// ("hour"), with times in the time zone of the report.
+func WriteScheduleCoverageCSV(w io.Writer, cov *ScheduleCoverage) error {
+	loc := cov.loc
+	if loc == nil {
+		loc = time.UTC
+	}
+	stamp := func(t int64) string { return time.Unix(t, 0).In(loc).Format(time.RFC3339) }
+	ids := func(list []uint) string {
+		out := make([]string, len(list))
+		for i, id := range list {
+			out[i] = strconv.FormatUint(uint64(id), 10)
+		}
+		return strings.Join(out, " ")
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"type", "from", "to", "from_time", "to_time", "min_active", "max_active", "station_ids"})
+	for _, sp := range cov.Uncovered {
+		cw.Write([]string{"uncovered", fmt.Sprint(sp.From), fmt.Sprint(sp.To), stamp(sp.From), stamp(sp.To), "0", "0", ""})
+	}
+	for _, sp := range cov.SinglePointsOfFailure {
+		cw.Write([]string{"single", fmt.Sprint(sp.From), fmt.Sprint(sp.To), stamp(sp.From), stamp(sp.To), "1", "1", fmt.Sprint(sp.StationID)})
+	}
+	for _, h := range cov.Hourly {
+		cw.Write([]string{"hour", fmt.Sprint(h.From), fmt.Sprint(h.To), stamp(h.From), stamp(h.To),
+			strconv.Itoa(h.MinActive), strconv.Itoa(h.MaxActive), ids(h.StationIDs)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
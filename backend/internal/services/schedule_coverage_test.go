@@ -0,0 +1,77 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+func TestScheduleCoverageHours(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.PutJSON("station:1", models.Station{ID: 1, Name: "S1", TimeZone: "Asia/Ho_Chi_Minh"}); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewScheduleService(db, nil)
+	if err := svc.Create(&models.Schedule{StationID: 1, StartHHMM: "0800", EndHHMM: "1600"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	// 2026-10-18 00:10 UTC, not on an hour boundary anywhere.
+	from := time.Date(2026, 10, 18, 0, 10, 0, 0, time.UTC).Unix()
+	to := from + 24*3600
+
+	for _, tz := range []string{"", "UTC", "Asia/Ho_Chi_Minh", "Asia/Kolkata", "Asia/Kathmandu", "America/St_Johns"} {
+		cov, err := svc.ScheduleCoverage([]uint{1}, from, to, tz)
+		if err != nil {
+			t.Fatalf("%q: %v", tz, err)
+		}
+		loc := time.UTC
+		if tz != "" {
+			loc = mustZone(t, tz)
+		}
+		if cov.TimeZone != loc.String() {
+			t.Errorf("%q: time_zone = %q", tz, cov.TimeZone)
+		}
+		if n := len(cov.Hourly); n != 25 {
+			t.Errorf("%q: %d hourly rows, want 25", tz, n)
+		}
+		for i, h := range cov.Hourly {
+			if i > 0 && h.From != cov.Hourly[i-1].To {
+				t.Errorf("%q: hour %d starts at %d, previous ends at %d", tz, i, h.From, cov.Hourly[i-1].To)
+			}
+			if i > 0 {
+				if local := time.Unix(h.From, 0).In(loc); local.Minute() != 0 || local.Second() != 0 {
+					t.Errorf("%q: hour %d starts at %s, not on a local hour", tz, i, local.Format(time.RFC3339))
+				}
+			}
+		}
+		if first, last := cov.Hourly[0], cov.Hourly[len(cov.Hourly)-1]; first.From != from || last.To != to {
+			t.Errorf("%q: hours span [%d, %d), want [%d, %d)", tz, first.From, last.To, from, to)
+		}
+
+		var buf bytes.Buffer
+		if err := WriteScheduleCoverageCSV(&buf, cov); err != nil {
+			t.Fatalf("%q: WriteScheduleCoverageCSV: %v", tz, err)
+		}
+		rows, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("%q: invalid CSV: %v", tz, err)
+		}
+		_, offset := time.Unix(from, 0).In(loc).Zone()
+		suffix := time.Unix(from, 0).In(time.FixedZone("", offset)).Format("Z07:00")
+		for _, row := range rows[1:] {
+			if !strings.HasSuffix(row[3], suffix) {
+				t.Errorf("%q: CSV time %s not in the report zone (%s)", tz, row[3], suffix)
+				break
+			}
+		}
+	}
+
+	if _, err := svc.ScheduleCoverage([]uint{1}, from, to, "Mars/Olympus"); !errors.Is(err, ErrInvalidTimeZone) {
+		t.Errorf("unknown zone: error = %v, want ErrInvalidTimeZone", err)
+	}
+}
@@ -0,0 +1,386 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// weekdayCodes maps RFC 5545 BYDAY codes to time.Weekday.
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// civilDay numbers the calendar date of t in its own location, counting
+// days from 1970-01-01.
+func civilDay(t time.Time) int {
+	return int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+// parseDay parses a "YYYY-MM-DD" date into its civil day number.
+func parseDay(s string) (int, bool) {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return 0, false
+	}
+	return civilDay(t), true
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// dayWeekday returns the weekday of a civil day; day 0 was a Thursday.
+func dayWeekday(day int) time.Weekday {
+	return time.Weekday((day%7 + 7 + 4) % 7)
+}
+
+// dayWeek numbers the Monday-based week (WKST=MO) containing a civil day.
+func dayWeek(day int) int {
+	return floorDiv(day+3, 7)
+}
+
+// rule is a compiled schedule recurrence with its exception dates.
+type rule struct {
+	freq     string
+	interval int
+	days     [7]bool
+	anyDay   bool
+	start    int
+	hasStart bool
+	until    int
+	hasUntil bool
+	count    int
+	except   map[int]bool
+}
+
+// compileRule checks and compiles the recurrence of a schedule. A schedule
+// without one repeats every day.
+func compileRule(sc *models.Schedule) (*rule, error) {
+	r := &rule{freq: models.FreqDaily, interval: 1, anyDay: true, except: make(map[int]bool)}
+	for _, d := range sc.ExceptDates {
+		day, ok := parseDay(d)
+		if !ok {
+			return nil, fmt.Errorf("%w: except_dates must use YYYY-MM-DD format", ErrInvalidSchedule)
+		}
+		r.except[day] = true
+	}
+	rec := sc.Recurrence
+	if rec == nil {
+		return r, nil
+	}
+
+	switch rec.Freq {
+	case models.FreqDaily, models.FreqWeekly:
+		r.freq = rec.Freq
+	default:
+		return nil, fmt.Errorf("%w: recurrence freq must be DAILY or WEEKLY", ErrInvalidSchedule)
+	}
+	if rec.Interval < 0 || rec.Count < 0 {
+		return nil, fmt.Errorf("%w: recurrence interval and count must not be negative", ErrInvalidSchedule)
+	}
+	if rec.Interval > 0 {
+		r.interval = rec.Interval
+	}
+	r.count = rec.Count
+	for _, code := range rec.ByDay {
+		wd, ok := weekdayCodes[strings.ToUpper(code)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown weekday %q, use MO TU WE TH FR SA SU", ErrInvalidSchedule, code)
+		}
+		r.days[wd] = true
+		r.anyDay = false
+	}
+	if rec.StartDate != "" {
+		day, ok := parseDay(rec.StartDate)
+		if !ok {
+			return nil, fmt.Errorf("%w: recurrence start_date must use YYYY-MM-DD format", ErrInvalidSchedule)
+		}
+		r.start, r.hasStart = day, true
+	}
+	if rec.Until != "" {
+		day, ok := parseDay(rec.Until)
+		if !ok {
+			return nil, fmt.Errorf("%w: recurrence until must use YYYY-MM-DD format", ErrInvalidSchedule)
+		}
+		r.until, r.hasUntil = day, true
+	}
+
+	switch {
+	case r.hasUntil && r.count > 0:
+		return nil, fmt.Errorf("%w: recurrence cannot have both until and count", ErrInvalidSchedule)
+	case r.hasStart && r.hasUntil && r.until < r.start:
+		return nil, fmt.Errorf("%w: recurrence until is before start_date", ErrInvalidSchedule)
+	case !r.hasStart && (r.interval > 1 || r.count > 0):
+		return nil, fmt.Errorf("%w: recurrence start_date is required with interval or count", ErrInvalidSchedule)
+	case r.freq == models.FreqWeekly && r.anyDay:
+		if !r.hasStart {
+			return nil, fmt.Errorf("%w: weekly recurrence needs by_day or start_date", ErrInvalidSchedule)
+		}
+		// Like DTSTART, the start date gives the weekday.
+		r.days[dayWeekday(r.start)] = true
+		r.anyDay = false
+	}
+	return r, nil
+}
+
+// matches reports whether a day fits the rule, ignoring count and exceptions.
+func (r *rule) matches(day int) bool {
+	if (r.hasStart && day < r.start) || (r.hasUntil && day > r.until) {
+		return false
+	}
+	if !r.anyDay && !r.days[dayWeekday(day)] {
+		return false
+	}
+	if r.interval == 1 {
+		return true
+	}
+	if r.freq == models.FreqWeekly {
+		return (dayWeek(day)-dayWeek(r.start))%r.interval == 0
+	}
+	return (day-r.start)%r.interval == 0
+}
+
+// occurs reports whether the window runs on a day. As in RFC 5545, excluded
+// dates still count towards count.
+func (r *rule) occurs(day int) bool {
+	if r.except[day] || !r.matches(day) {
+		return false
+	}
+	if r.count > 0 {
+		n := 0
+		for d := r.start; d <= day; d++ {
+			if r.matches(d) {
+				if n++; n > r.count {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// windowCovers reports whether minute m of a day falls in a window that
+// starts on that day (today) or, overnight, on the day before (yesterday).
+// Equal start and end times cover a whole day.
+func windowCovers(start, end, m int, today, yesterday bool) bool {
+	if start < end {
+		return today && m >= start && m < end
+	}
+	return (today && m >= start) || (yesterday && m < end)
+}
+
+// ActiveAt reports whether the station is on schedule at t, read in the
+// station's time zone. One-off overrides win over the schedules: a CANCEL
+// window turns the station off, an EXTRA window turns it on.
+func (s *ScheduleService) ActiveAt(stID uint, t time.Time) (bool, error) {
+	local := t.In(s.zone(stID))
+	day := civilDay(local)
+	m := local.Hour()*60 + local.Minute()
+
+	overrides, err := s.ListOverrides(stID)
+	if err != nil {
+		return false, err
+	}
+	extra := false
+	for _, o := range overrides {
+		oDay, ok := parseDay(o.Date)
+		start, ok1 := hhmmMinutes(o.StartHHMM)
+		end, ok2 := hhmmMinutes(o.EndHHMM)
+		if !ok || !ok1 || !ok2 || !windowCovers(start, end, m, oDay == day, oDay == day-1) {
+			continue
+		}
+		if o.Kind == models.ScheduleOverrideCancel {
+			return false, nil
+		}
+		extra = true
+	}
+	if extra {
+		return true, nil
+	}
+
+	list, err := s.ListByStation(stID)
+	if err != nil {
+		return false, err
+	}
+	for _, sc := range list {
+		start, ok1 := hhmmMinutes(sc.StartHHMM)
+		end, ok2 := hhmmMinutes(sc.EndHHMM)
+		r, err := compileRule(&sc)
+		if !ok1 || !ok2 || start == end || err != nil {
+			continue // Legacy malformed schedule; never active
+		}
+		if windowCovers(start, end, m, r.occurs(day), r.occurs(day-1)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// occurrenceSpans returns the windows a schedule runs within civil days
+// [from, to) as minute ranges counted from day 0.
+func occurrenceSpans(sc *models.Schedule, r *rule, from, to int) [][2]int {
+	start, ok1 := hhmmMinutes(sc.StartHHMM)
+	end, ok2 := hhmmMinutes(sc.EndHHMM)
+	if !ok1 || !ok2 || start == end {
+		return nil
+	}
+	if end < start {
+		end += minutesPerDay
+	}
+	var out [][2]int
+	for day := from; day < to; day++ {
+		if r.occurs(day) {
+			out = append(out, [2]int{day*minutesPerDay + start, day*minutesPerDay + end})
+		}
+	}
+	return out
+}
+
+// ruleHorizon is how many days ahead two schedules are compared for
+// overlaps; it covers every weekly pattern of up to a year.
+const ruleHorizon = 2 * 366
+
+// rulesOverlap reports whether two schedules run at the same time on some
+// day from today on.
+func rulesOverlap(a, b *models.Schedule, ra, rb *rule, today int) bool {
+	from := today - 1
+	for _, r := range []*rule{ra, rb} {
+		if r.hasStart && r.start > from {
+			from = r.start
+		}
+	}
+	to := from + ruleHorizon
+	for _, r := range []*rule{ra, rb} {
+		if r.hasUntil && r.until+1 < to {
+			to = r.until + 1
+		}
+	}
+	if from >= to {
+		return false
+	}
+	// Windows of the previous day may reach into from.
+	spansB := occurrenceSpans(b, rb, from-1, to)
+	for _, x := range occurrenceSpans(a, ra, from-1, to) {
+		for _, y := range spansB {
+			if x[0] < y[1] && y[0] < x[1] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+//========================
+// One-off overrides
+//========================
+
+func scheduleOverrideKey(stationID, id uint) string {
+	return fmt.Sprintf("schedule_override:%d:%d", stationID, id)
+}
+
+// ListOverrides returns the one-off overrides of a station ordered by date
+// and start time.
+func (s *ScheduleService) ListOverrides(stID uint) ([]models.ScheduleOverride, error) {
+	out := []models.ScheduleOverride{}
+	err := s.db.IteratePrefix(fmt.Sprintf("schedule_override:%d:", stID), func(_ string, val []byte) error {
+		var o models.ScheduleOverride
+		if err := json.Unmarshal(val, &o); err != nil {
+			return nil // Skip invalid records
+		}
+		out = append(out, o)
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Date != out[j].Date {
+			return out[i].Date < out[j].Date
+		}
+		return out[i].StartHHMM < out[j].StartHHMM
+	})
+	return out, err
+}
+
+// GetOverride retrieves a one-off override.
+func (s *ScheduleService) GetOverride(stID, id uint) (*models.ScheduleOverride, error) {
+	var o models.ScheduleOverride
+	if err := s.db.GetJSON(scheduleOverrideKey(stID, id), &o); err != nil {
+		return nil, errors.New("override not found")
+	}
+	return &o, nil
+}
+
+// validateOverride checks the date, kind, window and assigned personnel of
+// an override.
+func (s *ScheduleService) validateOverride(o *models.ScheduleOverride) error {
+	if _, ok := parseDay(o.Date); !ok {
+		return fmt.Errorf("%w: date must use YYYY-MM-DD format", ErrInvalidSchedule)
+	}
+	if o.Kind != models.ScheduleOverrideExtra && o.Kind != models.ScheduleOverrideCancel {
+		return fmt.Errorf("%w: kind must be EXTRA or CANCEL", ErrInvalidSchedule)
+	}
+	if o.StartHHMM == o.EndHHMM {
+		// Equal start and end times cover a whole day from that time.
+		if _, ok := hhmmMinutes(o.StartHHMM); !ok || len(o.StartHHMM) != 4 {
+			return fmt.Errorf("%w: times must use HHMM format", ErrInvalidSchedule)
+		}
+	} else if _, err := windowSpans(o.StartHHMM, o.EndHHMM); err != nil {
+		return err
+	}
+	return s.checkOverrideAssignments(o)
+}
+
+// CreateOverride adds an extra watch or a cancelled window on one date.
+func (s *ScheduleService) CreateOverride(o *models.ScheduleOverride) error {
+	if err := s.validateOverride(o); err != nil {
+		return err
+	}
+	id, err := s.db.NextID("schedule_override_counter")
+	if err != nil {
+		return fmt.Errorf("failed to generate ID: %w", err)
+	}
+	o.ID = id
+	o.CreatedAt = s.now().Unix()
+	return s.db.PutJSON(scheduleOverrideKey(o.StationID, o.ID), o)
+}
+
+// DeleteOverride removes a one-off override.
+func (s *ScheduleService) DeleteOverride(stID, id uint) error {
+	key := scheduleOverrideKey(stID, id)
+	if ok, err := s.db.Exists(key); err != nil {
+		return err
+	} else if !ok {
+		return errors.New("override not found")
+	}
+	return s.db.Delete(key)
+}
+
+// subtractIntervals removes the cuts from sorted, non-overlapping spans.
+func subtractIntervals(spans, cuts [][2]int64) [][2]int64 {
+	for _, cut := range mergeIntervals(cuts) {
+		var out [][2]int64
+		for _, sp := range spans {
+			if cut[1] <= sp[0] || sp[1] <= cut[0] {
+				out = append(out, sp)
+				continue
+			}
+			if sp[0] < cut[0] {
+				out = append(out, [2]int64{sp[0], cut[0]})
+			}
+			if cut[1] < sp[1] {
+				out = append(out, [2]int64{cut[1], sp[1]})
+			}
+		}
+		spans = out
+	}
+	return spans
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"sync/atomic"
 	"time"
 
@@ -11,13 +12,28 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
+// Clock tells the current time. The schedule service and the services built
+// on it share one clock so that they agree on what "now" is; tests pass a
+// fixed one.
+type Clock func() time.Time
+
+// ScheduleService keeps the schedules of a station at
+// "schedule:{stationID}:{id}" and its one-off overrides at
+// "schedule_override:{stationID}:{id}". Times of day and dates are read in
+// the station's time zone.
 type ScheduleService struct {
 	db  *DB
 	seq uint64
+	now func() time.Time
 }
 
-func NewScheduleService(db *DB) *ScheduleService {
-	sv := &ScheduleService{db: db}
+// NewScheduleService creates the schedule service; a nil clock is the wall
+// clock.
+func NewScheduleService(db *DB, clock Clock) *ScheduleService {
+	if clock == nil {
+		clock = time.Now
+	}
+	sv := &ScheduleService{db: db, now: clock}
 	lastID, err := sv.LastIDFromDB()
 	if err == nil {
 		sv.seq = uint64(lastID)
@@ -30,19 +46,28 @@ func (s *ScheduleService) nextID() uint {
 }
 
 func (s *ScheduleService) CreateOrUpdate(sc *models.Schedule) error {
+	if err := s.validate(sc); err != nil {
+		return err
+	}
 	if sc.ID == 0 {
 		sc.ID = s.nextID()
-		sc.CreatedAt = time.Now().Unix()
+		sc.CreatedAt = s.now().Unix()
 	}
 	key := fmt.Sprintf("schedule:%d:%d", sc.StationID, sc.ID)
-	sc.UpdatedAt = time.Now().Unix()
+	sc.UpdatedAt = s.now().Unix()
 	return s.db.PutJSON(key, sc)
 }
 
-// Create creates a new schedule
+// Create creates a new schedule. It fails with ErrInvalidSchedule for a
+// zero-length or malformed window and with a *ScheduleConflictError when the
+// window overlaps another schedule of the station.
 func (s *ScheduleService) Create(sc *models.Schedule) error {
+	sc.ID = 0
+	if err := s.validate(sc); err != nil {
+		return err
+	}
 	sc.ID = s.nextID()
-	sc.CreatedAt = time.Now().Unix()
+	sc.CreatedAt = s.now().Unix()
 	sc.UpdatedAt = sc.CreatedAt
 	key := fmt.Sprintf("schedule:%d:%d", sc.StationID, sc.ID)
 	return s.db.PutJSON(key, sc)
@@ -66,9 +91,13 @@ func (s *ScheduleService) Update(sc *models.Schedule) error {
 		return fmt.Errorf("schedule not found")
 	}
 
+	if err := s.validate(sc); err != nil {
+		return err
+	}
+
 	// Preserve creation time
 	sc.CreatedAt = existing.CreatedAt
-	sc.UpdatedAt = time.Now().Unix()
+	sc.UpdatedAt = s.now().Unix()
 
 	key := fmt.Sprintf("schedule:%d:%d", sc.StationID, sc.ID)
 	return s.db.PutJSON(key, sc)
@@ -76,6 +105,26 @@ func (s *ScheduleService) Update(sc *models.Schedule) error {
 
 // UpdatePartial updates a schedule with partial data
 func (s *ScheduleService) UpdatePartial(stationID, scheduleID uint, updates map[string]interface{}) (*models.Schedule, error) {
+	schedule, err := s.PreviewUpdate(stationID, scheduleID, updates)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule.UpdatedAt = s.now().Unix()
+
+	// Save updated schedule
+	key := fmt.Sprintf("schedule:%d:%d", schedule.StationID, schedule.ID)
+	err = s.db.PutJSON(key, schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// PreviewUpdate returns a schedule with partial data applied and validated,
+// without saving it.
+func (s *ScheduleService) PreviewUpdate(stationID, scheduleID uint, updates map[string]interface{}) (*models.Schedule, error) {
 	// Get existing schedule
 	schedule, err := s.GetByID(stationID, scheduleID)
 	if err != nil {
@@ -98,16 +147,40 @@ func (s *ScheduleService) UpdatePartial(stationID, scheduleID uint, updates map[
 	if phone, ok := updates["phone"].(string); ok {
 		schedule.Phone = phone
 	}
-
-	schedule.UpdatedAt = time.Now().Unix()
-
-	// Save updated schedule
-	key := fmt.Sprintf("schedule:%d:%d", schedule.StationID, schedule.ID)
-	err = s.db.PutJSON(key, schedule)
-	if err != nil {
+	// Removing the assigned people also removes the texts filled from their
+	// records, unless new texts are given in the same update.
+	if commanderID, ok := updates["commander_id"].(*uint); ok {
+		// 0 removes the assigned commander
+		if *commanderID == 0 {
+			commanderID = nil
+		}
+		if commanderID == nil && schedule.CommanderID != nil {
+			if _, ok := updates["commander"]; !ok {
+				schedule.Commander = ""
+			}
+			if _, ok := updates["phone"]; !ok {
+				schedule.Phone = ""
+			}
+		}
+		schedule.CommanderID = commanderID
+	}
+	if crewIDs, ok := updates["crew_ids"].([]uint); ok {
+		if len(crewIDs) == 0 && len(schedule.CrewIDs) > 0 {
+			if _, ok := updates["crew"]; !ok {
+				schedule.Crew = ""
+			}
+		}
+		schedule.CrewIDs = crewIDs
+	}
+	if rec, ok := updates["recurrence"].(*models.Recurrence); ok {
+		schedule.Recurrence = rec
+	}
+	if dates, ok := updates["except_dates"].([]string); ok {
+		schedule.ExceptDates = dates
+	}
+	if err := s.validate(schedule); err != nil {
 		return nil, err
 	}
-
 	return schedule, nil
 }
 
@@ -130,27 +203,190 @@ func (s *ScheduleService) ListByStation(stID uint) ([]models.Schedule, error) {
 	return list, err
 }
 
-// IsStationActiveNow returns true if current local time UTC+7 within any interval.
+// ListAll lists the schedules of all stations.
+func (s *ScheduleService) ListAll() ([]models.Schedule, error) {
+	var list []models.Schedule
+	err := s.db.IteratePrefix("schedule:", func(_ string, val []byte) error {
+		var sc models.Schedule
+		if err := json.Unmarshal(val, &sc); err != nil {
+			return err
+		}
+		list = append(list, sc)
+		return nil
+	})
+	return list, err
+}
+
+// IsStationActiveNow returns true if the station is on schedule at the
+// current time in its time zone.
 func (s *ScheduleService) IsStationActiveNow(stID uint) (bool, error) {
+	return s.ActiveAt(stID, s.now())
+}
+
+// zone returns the time zone of a station's schedules.
+func (s *ScheduleService) zone(stID uint) *time.Location {
+	return stationZone(s.db, stID)
+}
+
+func betweenHHMM(t, start, end string) bool {
+	if start <= end {
+		return t >= start && t < end
+	}
+	return t >= start || t < end // overnight
+}
+
+// hhmmMinutes converts "HHMM" to minutes after midnight.
+func hhmmMinutes(hhmm string) (int, bool) {
+	t, err := time.Parse("1504", hhmm)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// Watch is one concrete duty window of a station: an occurrence of a
+// schedule or an extra watch, with cancelled parts cut out.
+type Watch struct {
+	StationID   uint   `json:"station_id"`
+	ScheduleID  uint   `json:"schedule_id,omitempty"`
+	OverrideID  uint   `json:"override_id,omitempty"` // set for extra watches
+	Start       int64  `json:"start"`
+	End         int64  `json:"end"`
+	Commander   string `json:"commander,omitempty"`
+	Crew        string `json:"crew,omitempty"`
+	Phone       string `json:"phone,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	CommanderID *uint  `json:"commander_id,omitempty"`
+	CrewIDs     []uint `json:"crew_ids,omitempty"`
+}
+
+// watchSpan returns the Unix [start, end) of a window on the day starting at
+// midnight day.
+func watchSpan(day time.Time, startHHMM, endHHMM string) ([2]int64, bool) {
+	start, ok1 := hhmmMinutes(startHHMM)
+	end, ok2 := hhmmMinutes(endHHMM)
+	if !ok1 || !ok2 {
+		return [2]int64{}, false
+	}
+	if end <= start {
+		end += minutesPerDay // overnight; a whole day for equal times
+	}
+	// time.Date keeps wall-clock times right across DST changes.
+	y, m, d := day.Date()
+	a := time.Date(y, m, d, 0, start, 0, 0, day.Location()).Unix()
+	b := time.Date(y, m, d, 0, end, 0, 0, day.Location()).Unix()
+	return [2]int64{a, b}, true
+}
+
+// forEachDay calls fn with the local midnight of every day in loc whose
+// windows may overlap [from, to), starting a day early so overnight windows
+// reaching into from are included.
+func forEachDay(loc *time.Location, from, to int64, fn func(day time.Time)) {
+	day := time.Unix(from, 0).In(loc).AddDate(0, 0, -1)
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	for ; day.Unix() < to; day = day.AddDate(0, 0, 1) {
+		fn(day)
+	}
+}
+
+// scheduleWatches expands one schedule of a station in time zone loc into
+// its occurrences overlapping [from, to), ignoring one-off overrides.
+func scheduleWatches(sc *models.Schedule, loc *time.Location, from, to int64) []Watch {
+	r, err := compileRule(sc)
+	if err != nil || sc.StartHHMM == sc.EndHHMM {
+		return nil // Never active
+	}
+	var out []Watch
+	forEachDay(loc, from, to, func(day time.Time) {
+		if !r.occurs(civilDay(day)) {
+			return
+		}
+		if sp, ok := watchSpan(day, sc.StartHHMM, sc.EndHHMM); ok && sp[0] < to && sp[1] > from {
+			out = append(out, Watch{StationID: sc.StationID, ScheduleID: sc.ID, Start: sp[0], End: sp[1],
+				Commander: sc.Commander, Crew: sc.Crew, Phone: sc.Phone, CommanderID: sc.CommanderID, CrewIDs: sc.CrewIDs})
+		}
+	})
+	return out
+}
+
+// Watches expands the schedules and one-off overrides of a station into the
+// watches overlapping [from, to) (Unix seconds), ordered by start. Watches
+// are not clipped to the period.
+func (s *ScheduleService) Watches(stID uint, from, to int64) ([]Watch, error) {
 	list, err := s.ListByStation(stID)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	overrides, err := s.ListOverrides(stID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := s.zone(stID)
+	var watches []Watch
+	for i := range list {
+		watches = append(watches, scheduleWatches(&list[i], loc, from, to)...)
 	}
-	now := time.Now().In(time.FixedZone("UTC+7", 7*3600))
-	hhmm := now.Format("1504")
-	for _, sc := range list {
-		if betweenHHMM(hhmm, sc.StartHHMM, sc.EndHHMM) {
-			return true, nil
+	var cuts [][2]int64
+	forEachDay(loc, from, to, func(day time.Time) {
+		n := civilDay(day)
+		for _, o := range overrides {
+			if d, ok := parseDay(o.Date); !ok || d != n {
+				continue
+			}
+			sp, ok := watchSpan(day, o.StartHHMM, o.EndHHMM)
+			switch {
+			case !ok:
+			case o.Kind == models.ScheduleOverrideCancel:
+				cuts = append(cuts, sp)
+			default:
+				watches = append(watches, Watch{StationID: stID, OverrideID: o.ID, Start: sp[0], End: sp[1],
+					Commander: o.Commander, Crew: o.Crew, Phone: o.Phone, Reason: o.Reason, CommanderID: o.CommanderID, CrewIDs: o.CrewIDs})
+			}
+		}
+	})
+
+	out := []Watch{}
+	for _, w := range watches {
+		for _, sp := range subtractIntervals([][2]int64{{w.Start, w.End}}, cuts) {
+			if sp[0] < to && sp[1] > from {
+				w.Start, w.End = sp[0], sp[1]
+				out = append(out, w)
+			}
 		}
 	}
-	return false, nil
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Start < out[j].Start })
+	return out, nil
 }
 
-func betweenHHMM(t, start, end string) bool {
-	if start <= end {
-		return t >= start && t < end
+// ScheduledIntervals returns the times within [from, to) (Unix seconds) at
+// which the station is on schedule, as sorted, non-overlapping [start, end)
+// pairs. Recurrence rules and one-off overrides are applied as in ActiveAt.
+func (s *ScheduleService) ScheduledIntervals(stID uint, from, to int64) ([][2]int64, error) {
+	watches, err := s.Watches(stID, from, to)
+	if err != nil {
+		return nil, err
 	}
-	return t >= start || t < end // overnight
+	spans := make([][2]int64, 0, len(watches))
+	for _, w := range watches {
+		spans = append(spans, [2]int64{max(w.Start, from), min(w.End, to)})
+	}
+	return mergeIntervals(spans), nil
+}
+
+// mergeIntervals sorts [start, end) pairs and merges overlapping or touching
+// ones.
+func mergeIntervals(spans [][2]int64) [][2]int64 {
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	var out [][2]int64
+	for _, sp := range spans {
+		if n := len(out); n > 0 && sp[0] <= out[n-1][1] {
+			out[n-1][1] = max(out[n-1][1], sp[1])
+			continue
+		}
+		out = append(out, sp)
+	}
+	return out
 }
 
 func (s *ScheduleService) LastIDFromDB() (uint, error) {
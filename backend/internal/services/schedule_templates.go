@@ -0,0 +1,300 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// How ApplyTemplate handles a template window that overlaps an existing
+// schedule of a station.
+const (
+	TemplateConflictFail    = "fail"    // apply nothing and report the conflicts
+	TemplateConflictSkip    = "skip"    // keep the existing schedule, drop the window
+	TemplateConflictReplace = "replace" // delete the existing schedule
+)
+
+// TemplateStationResult is what applying a template does to one station.
+type TemplateStationResult struct {
+	StationID uint                          `json:"station_id"`
+	Created   []models.Schedule             `json:"created"`
+	Replaced  []models.Schedule             `json:"replaced,omitempty"`  // existing schedules deleted
+	Skipped   []models.ScheduleTemplateSlot `json:"skipped,omitempty"`   // windows not created
+	Conflicts []models.Schedule             `json:"conflicts,omitempty"` // existing schedules in the way (fail)
+}
+
+// TemplateApplyResult reports the schedules created, replaced and skipped on
+// each station.
+type TemplateApplyResult struct {
+	TemplateID uint                    `json:"template_id"`
+	Mode       string                  `json:"mode"`
+	DryRun     bool                    `json:"dry_run"`
+	Stations   []TemplateStationResult `json:"stations"`
+}
+
+// TemplateConflictError is returned by ApplyTemplate in fail mode when a
+// template window overlaps existing schedules; nothing is applied.
+type TemplateConflictError struct {
+	Result *TemplateApplyResult
+}
+
+func (e *TemplateConflictError) Error() string {
+	var ids []string
+	for _, st := range e.Result.Stations {
+		if len(st.Conflicts) > 0 {
+			ids = append(ids, fmt.Sprint(st.StationID))
+		}
+	}
+	return "template overlaps existing schedules of stations " + strings.Join(ids, ", ")
+}
+
+func scheduleTemplateKey(id uint) string { return fmt.Sprintf("schedule_template:%d", id) }
+
+// slotSchedule returns the schedule a template window creates on a station.
+func slotSchedule(slot models.ScheduleTemplateSlot, stationID uint) *models.Schedule {
+	sc := &models.Schedule{StationID: stationID, StartHHMM: slot.StartHHMM, EndHHMM: slot.EndHHMM,
+		ExceptDates: append([]string(nil), slot.ExceptDates...)}
+	if slot.Recurrence != nil {
+		rec := *slot.Recurrence
+		rec.ByDay = append([]string(nil), rec.ByDay...)
+		sc.Recurrence = &rec
+	}
+	return sc
+}
+
+// validateTemplate checks the name and windows of a template. Its windows
+// must not overlap each other, so one template never conflicts with itself.
+func (s *ScheduleService) validateTemplate(t *models.ScheduleTemplate) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return fmt.Errorf("%w: template name is required", ErrInvalidSchedule)
+	}
+	if len(t.Slots) == 0 {
+		return fmt.Errorf("%w: template needs at least one window", ErrInvalidSchedule)
+	}
+	all, err := s.ListTemplates()
+	if err != nil {
+		return err
+	}
+	for _, other := range all {
+		if other.ID != t.ID && strings.EqualFold(other.Name, t.Name) {
+			return fmt.Errorf("%w: template %q already exists", ErrInvalidSchedule, other.Name)
+		}
+	}
+
+	today := civilDay(s.now().In(scheduleZone))
+	var placed []models.Schedule
+	for i, slot := range t.Slots {
+		sc := slotSchedule(slot, 0)
+		if _, err := windowSpans(sc.StartHHMM, sc.EndHHMM); err != nil {
+			return fmt.Errorf("window %d: %w", i+1, err)
+		}
+		r, err := compileRule(sc)
+		if err != nil {
+			return fmt.Errorf("window %d: %w", i+1, err)
+		}
+		if len(overlapping(sc, r, placed, today)) > 0 {
+			return fmt.Errorf("%w: window %d (%s-%s) overlaps another window of the template", ErrInvalidSchedule, i+1, slot.StartHHMM, slot.EndHHMM)
+		}
+		placed = append(placed, *sc)
+	}
+	return nil
+}
+
+// CreateTemplate saves a new schedule template.
+func (s *ScheduleService) CreateTemplate(t *models.ScheduleTemplate) error {
+	t.ID = 0
+	if err := s.validateTemplate(t); err != nil {
+		return err
+	}
+	id, err := s.db.NextID("schedule_template_counter")
+	if err != nil {
+		return fmt.Errorf("failed to generate ID: %w", err)
+	}
+	t.ID = id
+	t.CreatedAt = s.now().Unix()
+	t.UpdatedAt = t.CreatedAt
+	return s.db.PutJSON(scheduleTemplateKey(t.ID), t)
+}
+
+// GetTemplate retrieves a schedule template by ID.
+func (s *ScheduleService) GetTemplate(id uint) (*models.ScheduleTemplate, error) {
+	var t models.ScheduleTemplate
+	if err := s.db.GetJSON(scheduleTemplateKey(id), &t); err != nil {
+		return nil, errors.New("template not found")
+	}
+	return &t, nil
+}
+
+// UpdateTemplate replaces the name, description and windows of a template.
+// Schedules already created from it are not changed.
+func (s *ScheduleService) UpdateTemplate(t *models.ScheduleTemplate) error {
+	existing, err := s.GetTemplate(t.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateTemplate(t); err != nil {
+		return err
+	}
+	t.CreatedBy = existing.CreatedBy
+	t.CreatedAt = existing.CreatedAt
+	t.UpdatedAt = s.now().Unix()
+	return s.db.PutJSON(scheduleTemplateKey(t.ID), t)
+}
+
+// DeleteTemplate removes a template. Schedules created from it stay.
+func (s *ScheduleService) DeleteTemplate(id uint) error {
+	if _, err := s.GetTemplate(id); err != nil {
+		return err
+	}
+	return s.db.Delete(scheduleTemplateKey(id))
+}
+
+// ListTemplates returns all templates ordered by name.
+func (s *ScheduleService) ListTemplates() ([]models.ScheduleTemplate, error) {
+	out := []models.ScheduleTemplate{}
+	err := s.db.IteratePrefix("schedule_template:", func(_ string, val []byte) error {
+		var t models.ScheduleTemplate
+		if err := json.Unmarshal(val, &t); err != nil {
+			return nil // Skip invalid records
+		}
+		out = append(out, t)
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool { return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name) })
+	return out, err
+}
+
+// TemplateFromStation saves the windows and recurrence rules of a station's
+// schedules as a new template, for copying them to other stations.
+func (s *ScheduleService) TemplateFromStation(stationID uint, t *models.ScheduleTemplate) error {
+	list, err := s.ListByStation(stationID)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		return fmt.Errorf("%w: station %d has no schedules", ErrInvalidSchedule, stationID)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].StartHHMM < list[j].StartHHMM })
+	t.Slots = make([]models.ScheduleTemplateSlot, len(list))
+	for i, sc := range list {
+		t.Slots[i] = models.ScheduleTemplateSlot{StartHHMM: sc.StartHHMM, EndHHMM: sc.EndHHMM,
+			Recurrence: sc.Recurrence, ExceptDates: sc.ExceptDates}
+	}
+	return s.CreateTemplate(t)
+}
+
+// ApplyTemplate creates the windows of a template as schedules on each of
+// the stations, handling overlaps with existing schedules by mode (fail,
+// skip or replace). All stations are written in one batch; with dryRun
+// nothing is written.
+func (s *ScheduleService) ApplyTemplate(id uint, stationIDs []uint, mode string, dryRun bool) (*TemplateApplyResult, error) {
+	t, err := s.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	switch mode {
+	case TemplateConflictFail, TemplateConflictSkip, TemplateConflictReplace:
+	default:
+		return nil, fmt.Errorf("%w: mode must be fail, skip or replace", ErrInvalidSchedule)
+	}
+	if len(stationIDs) == 0 {
+		return nil, fmt.Errorf("%w: station_ids is required", ErrInvalidSchedule)
+	}
+
+	res := &TemplateApplyResult{TemplateID: t.ID, Mode: mode, DryRun: dryRun, Stations: []TemplateStationResult{}}
+	seen := make(map[uint]bool)
+	failed := false
+	for _, stID := range stationIDs {
+		if seen[stID] {
+			continue
+		}
+		seen[stID] = true
+		if ok, err := s.db.Exists(fmt.Sprintf("station:%d", stID)); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, fmt.Errorf("%w: station %d not found", ErrInvalidSchedule, stID)
+		}
+		current, err := s.ListByStation(stID)
+		if err != nil {
+			return nil, err
+		}
+
+		today := civilDay(s.now().In(s.zone(stID)))
+		st := TemplateStationResult{StationID: stID, Created: []models.Schedule{}}
+		inConflict := make(map[uint]bool)
+		for _, slot := range t.Slots {
+			sc := slotSchedule(slot, stID)
+			r, err := compileRule(sc)
+			if err != nil {
+				return nil, err
+			}
+			conflicts := overlapping(sc, r, append(current, st.Created...), today)
+			switch {
+			case len(conflicts) == 0:
+			case mode == TemplateConflictSkip:
+				st.Skipped = append(st.Skipped, slot)
+				continue
+			case mode == TemplateConflictReplace:
+				st.Replaced = append(st.Replaced, conflicts...)
+				kept := current[:0]
+				for _, c := range current {
+					if !containsSchedule(conflicts, c.ID) {
+						kept = append(kept, c)
+					}
+				}
+				current = kept
+			default:
+				for _, c := range conflicts {
+					if !inConflict[c.ID] {
+						inConflict[c.ID] = true
+						st.Conflicts = append(st.Conflicts, c)
+					}
+				}
+				failed = true
+				continue
+			}
+			st.Created = append(st.Created, *sc)
+		}
+		res.Stations = append(res.Stations, st)
+	}
+	if failed {
+		return nil, &TemplateConflictError{Result: res}
+	}
+	if dryRun {
+		return res, nil
+	}
+
+	puts := make(map[string]any)
+	var deletes []string
+	now := s.now().Unix()
+	for i := range res.Stations {
+		st := &res.Stations[i]
+		for _, sc := range st.Replaced {
+			deletes = append(deletes, fmt.Sprintf("schedule:%d:%d", sc.StationID, sc.ID))
+		}
+		for j := range st.Created {
+			sc := &st.Created[j]
+			sc.ID = s.nextID()
+			sc.CreatedAt, sc.UpdatedAt = now, now
+			puts[fmt.Sprintf("schedule:%d:%d", sc.StationID, sc.ID)] = sc
+		}
+	}
+	if err := s.db.WriteJSON(puts, deletes); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func containsSchedule(list []models.Schedule, id uint) bool {
+	for _, sc := range list {
+		if sc.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+const (
+	transitionStep    = 31 * 24 * 3600  // watches expanded per lookup
+	transitionHorizon = 366 * 24 * 3600 // no transition further out is reported
+)
+
+// Transition is the next time a station goes on or off schedule.
+type Transition struct {
+	At       int64            `json:"at"`
+	Status   string           `json:"status"` // ACTIVE or INACTIVE from At on
+	Watch    Watch            `json:"watch"`  // the watch that starts or ends at At
+	Schedule *models.Schedule `json:"schedule,omitempty"`
+}
+
+// NextTransition returns the first instant after t at which the station goes
+// on schedule (the start of the next watch) or off it (the end of the
+// current one), or nil when there is none within a year. Watches that touch
+// or overlap are one stretch on schedule, so back-to-back and overnight
+// windows do not count as a change.
+func (s *ScheduleService) NextTransition(stID uint, t time.Time) (*Transition, error) {
+	cur, horizon := t.Unix(), t.Unix()+transitionHorizon
+	on := false
+	var last Watch
+	for cur < horizon {
+		to := min(cur+transitionStep, horizon)
+		watches, err := s.Watches(stID, cur, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range watches {
+			switch {
+			case w.Start > cur && on:
+				return s.transition(cur, models.StationInactive, last), nil
+			case w.Start > cur:
+				return s.transition(w.Start, models.StationActive, w), nil
+			case w.End > cur:
+				on, cur, last = true, w.End, w
+			}
+		}
+		if on && cur < to {
+			return s.transition(cur, models.StationInactive, last), nil
+		}
+		cur = max(cur, to)
+	}
+	return nil, nil
+}
+
+// transition builds a Transition, with the schedule of the watch if it came
+// from one.
+func (s *ScheduleService) transition(at int64, status string, w Watch) *Transition {
+	tr := &Transition{At: at, Status: status, Watch: w}
+	if w.ScheduleID != 0 {
+		tr.Schedule, _ = s.GetByID(w.StationID, w.ScheduleID) // Extra watches have none
+	}
+	return tr
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// ErrInvalidSchedule is returned (wrapped) for malformed schedule windows.
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+const minutesPerDay = 24 * 60
+
+// ScheduleConflictError lists the existing schedules a window overlaps.
+type ScheduleConflictError struct {
+	Conflicts []models.Schedule
+}
+
+func (e *ScheduleConflictError) Error() string {
+	spans := make([]string, len(e.Conflicts))
+	for i, sc := range e.Conflicts {
+		spans[i] = fmt.Sprintf("%d (%s-%s)", sc.ID, sc.StartHHMM, sc.EndHHMM)
+	}
+	return "schedule overlaps " + strings.Join(spans, ", ")
+}
+
+// windowSpans returns a daily window as [start, end) minute ranges within one
+// day; an overnight window is split at midnight.
+func windowSpans(startHHMM, endHHMM string) ([][2]int, error) {
+	if len(startHHMM) != 4 || len(endHHMM) != 4 {
+		return nil, fmt.Errorf("%w: times must use HHMM format", ErrInvalidSchedule)
+	}
+	start, ok1 := hhmmMinutes(startHHMM)
+	end, ok2 := hhmmMinutes(endHHMM)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("%w: times must use HHMM format", ErrInvalidSchedule)
+	}
+	switch {
+	case start == end:
+		return nil, fmt.Errorf("%w: start and end must differ", ErrInvalidSchedule)
+	case start < end:
+		return [][2]int{{start, end}}, nil
+	case end == 0:
+		return [][2]int{{start, minutesPerDay}}, nil
+	}
+	return [][2]int{{start, minutesPerDay}, {0, end}}, nil
+}
+
+// validate checks the window and recurrence of a schedule and that it does
+// not run at the same time as another schedule of its station. Schedules on
+// different days (by weekday, date range or count) do not conflict. It then
+// checks the assigned personnel (see checkAssignments).
+func (s *ScheduleService) validate(sc *models.Schedule) error {
+	if _, err := windowSpans(sc.StartHHMM, sc.EndHHMM); err != nil {
+		return err
+	}
+	r, err := compileRule(sc)
+	if err != nil {
+		return err
+	}
+	list, err := s.ListByStation(sc.StationID)
+	if err != nil {
+		return err
+	}
+	today := civilDay(s.now().In(s.zone(sc.StationID)))
+	if conflicts := overlapping(sc, r, list, today); len(conflicts) > 0 {
+		return &ScheduleConflictError{Conflicts: conflicts}
+	}
+	return s.checkAssignments(sc)
+}
+
+// overlapping returns the schedules of list, other than sc itself, that run
+// at the same time as sc on some day from today on.
+func overlapping(sc *models.Schedule, r *rule, list []models.Schedule, today int) []models.Schedule {
+	var conflicts []models.Schedule
+	for _, other := range list {
+		if sc.ID != 0 && other.ID == sc.ID {
+			continue
+		}
+		if _, err := windowSpans(other.StartHHMM, other.EndHHMM); err != nil {
+			continue // Legacy malformed window; never active
+		}
+		ro, err := compileRule(&other)
+		if err != nil {
+			continue
+		}
+		if rulesOverlap(sc, &other, r, ro, today) {
+			conflicts = append(conflicts, other)
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// newTestDB opens an empty database that is removed after the test.
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := OpenDB(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestWindowSpans(t *testing.T) {
+	tests := []struct {
+		start, end string
+		want       [][2]int
+		wantErr    bool
+	}{
+		{"0800", "1600", [][2]int{{480, 960}}, false},
+		{"2200", "0200", [][2]int{{1320, 1440}, {0, 120}}, false},
+		{"2300", "0000", [][2]int{{1380, 1440}}, false},
+		{"0000", "0100", [][2]int{{0, 60}}, false},
+		{"0000", "0000", nil, true},
+		{"1200", "1200", nil, true},
+		{"2400", "0100", nil, true},
+		{"800", "1600", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := windowSpans(tt.start, tt.end)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidSchedule) {
+				t.Errorf("windowSpans(%s, %s) error = %v, want ErrInvalidSchedule", tt.start, tt.end, err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("windowSpans(%s, %s) = %v, %v; want %v", tt.start, tt.end, got, err, tt.want)
+		}
+	}
+}
+
+func TestValidateMidnight(t *testing.T) {
+	weekly := func(days ...string) *models.Recurrence {
+		return &models.Recurrence{Freq: "WEEKLY", ByDay: days}
+	}
+	tests := []struct {
+		name                   string
+		existStart, existEnd   string
+		existRec               *models.Recurrence
+		start, end             string
+		rec                    *models.Recurrence
+		wantConflict, wantFail bool
+	}{
+		{name: "overnight overlaps early morning", existStart: "2200", existEnd: "0200", start: "0100", end: "0300", wantConflict: true},
+		{name: "early morning overlaps overnight", existStart: "0100", existEnd: "0300", start: "2200", end: "0200", wantConflict: true},
+		{name: "touching at midnight", existStart: "2300", existEnd: "0000", start: "0000", end: "0100"},
+		{name: "touching at midnight reversed", existStart: "0000", existEnd: "0100", start: "2300", end: "0000"},
+		{name: "long overnight overlaps morning", existStart: "2200", existEnd: "0600", start: "0500", end: "0700", wantConflict: true},
+		{name: "back to back after overnight", existStart: "2200", existEnd: "0600", start: "0600", end: "0700"},
+		{name: "different weekdays", existStart: "0800", existEnd: "1600", existRec: weekly("MO"), start: "0800", end: "1600", rec: weekly("TU")},
+		{name: "same weekday", existStart: "0800", existEnd: "1600", existRec: weekly("MO", "WE"), start: "1200", end: "1800", rec: weekly("WE"), wantConflict: true},
+		{name: "overnight into the next weekday", existStart: "2200", existEnd: "0200", existRec: weekly("MO"), start: "0100", end: "0300", rec: weekly("TU"), wantConflict: true},
+		{name: "zero length midnight", existStart: "0800", existEnd: "1600", start: "0000", end: "0000", wantFail: true},
+		{name: "zero length noon", existStart: "0800", existEnd: "1600", start: "1200", end: "1200", wantFail: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewScheduleService(newTestDB(t), nil)
+			if err := svc.Create(&models.Schedule{StationID: 1, StartHHMM: tt.existStart, EndHHMM: tt.existEnd, Recurrence: tt.existRec}); err != nil {
+				t.Fatalf("Create existing: %v", err)
+			}
+
+			err := svc.validate(&models.Schedule{StationID: 1, StartHHMM: tt.start, EndHHMM: tt.end, Recurrence: tt.rec})
+			var conflict *ScheduleConflictError
+			switch {
+			case tt.wantFail:
+				if !errors.Is(err, ErrInvalidSchedule) {
+					t.Errorf("error = %v, want ErrInvalidSchedule", err)
+				}
+			case tt.wantConflict:
+				if !errors.As(err, &conflict) {
+					t.Errorf("error = %v, want ScheduleConflictError", err)
+				}
+			case err != nil:
+				t.Errorf("error = %v, want none", err)
+			}
+		})
+	}
+}
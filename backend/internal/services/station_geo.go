@@ -0,0 +1,209 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// maxCoverCells bounds the number of geohash prefixes scanned per query.
+const maxCoverCells = 32
+
+// halfCircumferenceKm is the largest possible great-circle distance.
+const halfCircumferenceKm = math.Pi * earthRadiusKm
+
+// StationDistance is a station together with its great-circle distance from
+// the query point.
+type StationDistance struct {
+	models.Station
+	DistanceKm float64 `json:"distance_km"`
+}
+
+func stationGeoKey(st *models.Station) string {
+	return fmt.Sprintf("station_geo:%s:%d", encodeGeohash(st.Latitude, st.Longitude, geohashIndexPrecision), st.ID)
+}
+
+// ensureGeoIndex (re)builds the "station_geo:{geohash}:{id}" index when it
+// has never been built, e.g. for stations created by older versions.
+func (s *StationService) ensureGeoIndex() {
+	if ok, _ := s.db.Exists("station_geo_version"); ok {
+		return
+	}
+	stations, err := s.List()
+	if err != nil {
+		log.Println("Station geo index rebuild failed:", err)
+		return
+	}
+	for _, st := range stations {
+		if err := s.db.PutJSON(stationGeoKey(st), st.ID); err != nil {
+			log.Println("Station geo index rebuild failed:", err)
+			return
+		}
+	}
+	s.db.PutJSON("station_geo_version", 1) // Ignore error
+}
+
+// reindexGeo moves the geo index entry of a station from its old to its new
+// position. old may be nil for new stations, st nil for deleted ones.
+func (s *StationService) reindexGeo(old, st *models.Station) error {
+	if old != nil && (st == nil || stationGeoKey(old) != stationGeoKey(st)) {
+		s.db.Delete(stationGeoKey(old)) // Ignore error
+	}
+	if st != nil {
+		return s.db.PutJSON(stationGeoKey(st), st.ID)
+	}
+	return nil
+}
+
+// withStatus fills the current status (schedule and health) and the next
+// time the station goes on or off schedule. While a status override is in
+// effect the schedule does not count until it expires: the next change is
+// the expiry, or the first schedule change after it when the station shows
+// the same status either way. An override without expiry has none.
+func (s *StationService) withStatus(st *models.Station) {
+	st.Status, _, _ = s.healthSvc.Status(st.ID)
+	st.NextChangeAt, st.NextStatus = nil, ""
+	from := s.now()
+	if ov := s.healthSvc.ActiveOverride(st.ID); ov != nil {
+		if ov.ExpiresAt == nil {
+			return
+		}
+		from = time.Unix(*ov.ExpiresAt, 0)
+		active, err := s.schedSvc.ActiveAt(st.ID, from)
+		if err != nil {
+			return
+		}
+		next := models.StationInactive
+		if active {
+			next = models.StationActive
+		}
+		if next != st.Status {
+			st.NextChangeAt, st.NextStatus = ov.ExpiresAt, next
+			return
+		}
+	}
+	if tr, err := s.schedSvc.NextTransition(st.ID, from); err == nil && tr != nil {
+		st.NextChangeAt, st.NextStatus = &tr.At, tr.Status
+	}
+}
+
+// stationsInBox returns the stations inside the box using the geo index. A
+// box with minLon > maxLon crosses the antimeridian.
+func (s *StationService) stationsInBox(minLat, minLon, maxLat, maxLon float64) ([]models.Station, error) {
+	seen := make(map[uint]bool)
+	var out []models.Station
+	for _, cell := range geohashCover(minLat, minLon, maxLat, maxLon, maxCoverCells) {
+		err := s.db.IteratePrefix("station_geo:"+cell, func(_ string, val []byte) error {
+			var id uint
+			if err := json.Unmarshal(val, &id); err != nil || seen[id] {
+				return nil // Skip invalid records
+			}
+			seen[id] = true
+			st, err := s.GetByID(id)
+			if err != nil {
+				return nil // Stale index entry
+			}
+			if inBox(st.Latitude, st.Longitude, minLat, minLon, maxLat, maxLon) {
+				out = append(out, *st)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// WithinBox returns the stations inside the lat/lon box, with their current
+// status. activeOnly drops stations that are not ACTIVE right now.
+func (s *StationService) WithinBox(minLat, minLon, maxLat, maxLon float64, activeOnly bool) ([]models.Station, error) {
+	if !validLatLon(minLat, minLon) || !validLatLon(maxLat, maxLon) || minLat > maxLat {
+		return nil, errors.New("invalid bounding box")
+	}
+	stations, err := s.stationsInBox(minLat, minLon, maxLat, maxLon)
+	if err != nil {
+		return nil, err
+	}
+	out := stations[:0]
+	for _, st := range stations {
+		s.withStatus(&st)
+		if !activeOnly || st.Status == models.StationActive {
+			out = append(out, st)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// WithinRadius returns the stations within radiusKm (great-circle distance)
+// of the point, nearest first.
+func (s *StationService) WithinRadius(lat, lon, radiusKm float64, activeOnly bool) ([]StationDistance, error) {
+	if !validLatLon(lat, lon) {
+		return nil, errors.New("invalid coordinates")
+	}
+	if radiusKm <= 0 {
+		return nil, errors.New("radius must be positive")
+	}
+	minLat, minLon, maxLat, maxLon := radiusBox(lat, lon, radiusKm)
+	stations, err := s.stationsInBox(minLat, minLon, maxLat, maxLon)
+	if err != nil {
+		return nil, err
+	}
+	out := []StationDistance{}
+	for _, st := range stations {
+		d := haversineKm(lat, lon, st.Latitude, st.Longitude)
+		if d > radiusKm {
+			continue
+		}
+		s.withStatus(&st)
+		if activeOnly && st.Status != models.StationActive {
+			continue
+		}
+		out = append(out, StationDistance{Station: st, DistanceKm: d})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].DistanceKm != out[j].DistanceKm {
+			return out[i].DistanceKm < out[j].DistanceKm
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// Nearest returns the k stations in scope (any station when scope is nil)
+// closest to the point, nearest first. The search radius grows until k
+// stations are found or the whole globe has been covered.
+func (s *StationService) Nearest(lat, lon float64, k int, activeOnly bool, scope map[uint]bool) ([]StationDistance, error) {
+	if k <= 0 {
+		return nil, errors.New("k must be positive")
+	}
+	for radius := 50.0; ; radius *= 4 {
+		radius = math.Min(radius, halfCircumferenceKm)
+		found, err := s.WithinRadius(lat, lon, radius, activeOnly)
+		if err != nil {
+			return nil, err
+		}
+		if scope != nil {
+			kept := found[:0]
+			for _, sd := range found {
+				if scope[sd.ID] {
+					kept = append(kept, sd)
+				}
+			}
+			found = kept
+		}
+		if len(found) >= k || radius >= halfCircumferenceKm {
+			if len(found) > k {
+				found = found[:k]
+			}
+			return found, nil
+		}
+	}
+}
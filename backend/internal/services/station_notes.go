@@ -0,0 +1,191 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// RecentNotesCount is how many notes come with a single station.
+const RecentNotesCount = 5
+
+func stationNoteKey(stationID, noteID uint) string {
+	return fmt.Sprintf("station_note:%d:%010d", stationID, noteID)
+}
+
+// ensureNoteLog moves the free-text notes of stations created by older
+// versions into the note log as pinned entries without an author.
+func (s *StationService) ensureNoteLog() {
+	if ok, _ := s.db.Exists("station_note_version"); ok {
+		return
+	}
+	stations, err := s.List()
+	if err != nil {
+		log.Println("Station note migration failed:", err)
+		return
+	}
+	for _, st := range stations {
+		if st.Note == "" {
+			continue
+		}
+		if notes, _ := s.notes(st.ID); len(notes) > 0 {
+			continue
+		}
+		if _, err := s.appendNote(&models.StationNote{StationID: st.ID, Text: st.Note, Pinned: true, CreatedAt: st.UpdatedAt}); err != nil {
+			log.Println("Station note migration failed:", err)
+			return
+		}
+	}
+	s.db.PutJSON("station_note_version", 1) // Ignore error
+}
+
+// notes returns all notes of a station, oldest first.
+func (s *StationService) notes(stationID uint) ([]models.StationNote, error) {
+	var out []models.StationNote
+	err := s.db.IteratePrefix(fmt.Sprintf("station_note:%d:", stationID), func(_ string, val []byte) error {
+		var n models.StationNote
+		if err := json.Unmarshal(val, &n); err != nil {
+			return nil // Skip invalid records
+		}
+		out = append(out, n)
+		return nil
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, err
+}
+
+func (s *StationService) appendNote(n *models.StationNote) (*models.StationNote, error) {
+	id, err := s.db.NextID("station_note_counter")
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ID: %w", err)
+	}
+	n.ID = id
+	if n.CreatedAt == 0 {
+		n.CreatedAt = s.now().Unix()
+	}
+	if err := s.db.PutJSON(stationNoteKey(n.StationID, n.ID), n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// syncNote sets Station.Note to the text of the latest pinned note.
+func (s *StationService) syncNote(stationID uint) error {
+	notes, err := s.notes(stationID)
+	if err != nil {
+		return err
+	}
+	latest := ""
+	for i := len(notes) - 1; i >= 0; i-- {
+		if notes[i].Pinned {
+			latest = notes[i].Text
+			break
+		}
+	}
+	key := fmt.Sprintf("station:%d", stationID)
+	var st models.Station
+	if err := s.db.GetJSON(key, &st); err != nil {
+		return errors.New("station not found")
+	}
+	if st.Note == latest {
+		return nil
+	}
+	st.Note = latest
+	st.UpdatedAt = s.now().Unix()
+	return s.db.PutJSON(key, &st)
+}
+
+// AddNote appends a note to the station's log.
+func (s *StationService) AddNote(stationID uint, author, text string, pinned bool) (*models.StationNote, error) {
+	if _, err := s.GetByID(stationID); err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, errors.New("note text is required")
+	}
+	n, err := s.appendNote(&models.StationNote{StationID: stationID, Author: author, Text: text, Pinned: pinned})
+	if err != nil {
+		return nil, err
+	}
+	if pinned {
+		if err := s.syncNote(stationID); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// SetNotePinned pins or unpins a note. The text of a note never changes.
+func (s *StationService) SetNotePinned(stationID, noteID uint, pinned bool) (*models.StationNote, error) {
+	key := stationNoteKey(stationID, noteID)
+	var n models.StationNote
+	if err := s.db.GetJSON(key, &n); err != nil {
+		return nil, errors.New("note not found")
+	}
+	n.Pinned = pinned
+	if err := s.db.PutJSON(key, &n); err != nil {
+		return nil, err
+	}
+	return &n, s.syncNote(stationID)
+}
+
+// UpdateNote is the compatibility write of Station.Note: a non-empty note is
+// appended as a pinned entry, an empty one unpins all notes.
+func (s *StationService) UpdateNote(id uint, author, note string) error {
+	if strings.TrimSpace(note) != "" {
+		_, err := s.AddNote(id, author, note, true)
+		return err
+	}
+	notes, err := s.notes(id)
+	if err != nil {
+		return err
+	}
+	for _, n := range notes {
+		if n.Pinned {
+			n.Pinned = false
+			if err := s.db.PutJSON(stationNoteKey(id, n.ID), &n); err != nil {
+				return err
+			}
+		}
+	}
+	return s.syncNote(id)
+}
+
+// RecentNotes returns the latest n notes of a station, newest first.
+func (s *StationService) RecentNotes(stationID uint, n int) ([]models.StationNote, error) {
+	notes, _, err := s.NoteHistory(stationID, 1, n)
+	return notes, err
+}
+
+// NoteHistory returns one page (from 1) of a station's notes, newest first,
+// with the total number of notes.
+func (s *StationService) NoteHistory(stationID uint, page, pageSize int) ([]models.StationNote, int, error) {
+	notes, err := s.notes(stationID)
+	if err != nil {
+		return nil, 0, err
+	}
+	out := []models.StationNote{}
+	start := (page - 1) * pageSize
+	for i := len(notes) - 1 - start; i >= 0 && len(out) < pageSize; i-- {
+		out = append(out, notes[i])
+	}
+	return out, len(notes), nil
+}
+
+// deleteNotes removes the note log of a station.
+func (s *StationService) deleteNotes(stationID uint) {
+	var keys []string
+	s.db.IteratePrefix(fmt.Sprintf("station_note:%d:", stationID), func(key string, _ []byte) error {
+		keys = append(keys, key)
+		return nil
+	})
+	for _, k := range keys {
+		s.db.Delete(k) // Ignore error
+	}
+}
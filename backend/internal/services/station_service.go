@@ -12,18 +12,22 @@ import (
 )
 
 type StationService struct {
-	db       *DB
-	schedSvc *ScheduleService
-	lastID   uint
+	db        *DB
+	schedSvc  *ScheduleService
+	healthSvc *HealthService
+	lastID    uint
+	now       func() time.Time
 }
 
-func NewStationService(db *DB, sched *ScheduleService) *StationService {
-	sv := &StationService{db: db, schedSvc: sched}
+func NewStationService(db *DB, sched *ScheduleService, health *HealthService) *StationService {
+	sv := &StationService{db: db, schedSvc: sched, healthSvc: health, now: sched.now}
 	lastID, err := sv.LastIDFromDB()
 	if err == nil {
 		sv.lastID = lastID
 	}
 	log.Println("Last station ID:", sv.lastID)
+	sv.ensureGeoIndex()
+	sv.ensureNoteLog()
 	return sv
 }
 
@@ -34,30 +38,24 @@ func (s *StationService) ListWithStatus() ([]models.Station, error) {
 		if err := json.Unmarshal(val, &st); err != nil {
 			return err
 		}
-		active, _ := s.schedSvc.IsStationActiveNow(st.ID)
-		if active {
-			st.Status = "ACTIVE"
-		} else {
-			st.Status = "INACTIVE"
-		}
+		s.withStatus(&st)
 		out = append(out, st)
 		return nil
 	})
 	return out, err
 }
 
-func (s *StationService) UpdateNote(id uint, note string) error {
-	key := fmt.Sprintf("station:%d", id)
-	var st models.Station
-	if err := s.db.GetJSON(key, &st); err != nil {
+func (s *StationService) Create(station *models.Station) error {
+	if err := validateRadar(station.Radar); err != nil {
+		return err
+	}
+	if _, err := loadZone(station.TimeZone); err != nil {
+		return err
+	}
+	if err := checkGroup(s.db, station.GroupID); err != nil {
 		return err
 	}
-	st.Note = note
-	st.UpdatedAt = time.Now().Unix()
-	return s.db.PutJSON(key, &st)
-}
 
-func (s *StationService) Create(station *models.Station) error {
 	// Generate a simple incremental ID if not provided
 	if station.ID == 0 {
 		station.ID = s.lastID + 1
@@ -75,12 +73,29 @@ func (s *StationService) Create(station *models.Station) error {
 		return errors.New("station with this ID already exists")
 	}
 
-	station.CreatedAt = time.Now().Unix()
+	station.CreatedAt = s.now().Unix()
 	station.UpdatedAt = station.CreatedAt
-	return s.db.PutJSON(key, station)
+	if err := s.db.PutJSON(key, station); err != nil {
+		return err
+	}
+	if station.Note != "" {
+		if _, err := s.appendNote(&models.StationNote{StationID: station.ID, Text: station.Note, Pinned: true}); err != nil {
+			return err
+		}
+	}
+	return s.reindexGeo(nil, station)
 }
 
 func (s *StationService) Update(station *models.Station) error {
+	if err := validateRadar(station.Radar); err != nil {
+		return err
+	}
+	if _, err := loadZone(station.TimeZone); err != nil {
+		return err
+	}
+	if err := checkGroup(s.db, station.GroupID); err != nil {
+		return err
+	}
 	key := fmt.Sprintf("station:%d", station.ID)
 	var existingStation models.Station
 	if err := s.db.GetJSON(key, &existingStation); err != nil {
@@ -88,8 +103,12 @@ func (s *StationService) Update(station *models.Station) error {
 	}
 
 	station.CreatedAt = existingStation.CreatedAt
-	station.UpdatedAt = time.Now().Unix()
-	return s.db.PutJSON(key, station)
+	station.Note = existingStation.Note // Derived from the note log
+	station.UpdatedAt = s.now().Unix()
+	if err := s.db.PutJSON(key, station); err != nil {
+		return err
+	}
+	return s.reindexGeo(&existingStation, station)
 }
 
 // UpdatePartial updates a station with partial data
@@ -100,6 +119,8 @@ func (s *StationService) UpdatePartial(id uint, updates map[string]interface{})
 		return nil, errors.New("station not found")
 	}
 
+	old := *station
+
 	// Apply updates
 	if name, ok := updates["name"].(string); ok && name != "" {
 		station.Name = name
@@ -116,14 +137,30 @@ func (s *StationService) UpdatePartial(id uint, updates map[string]interface{})
 	if distanceToCoast, ok := updates["distance_to_coast"].(float64); ok {
 		station.DistanceToCoast = distanceToCoast
 	}
-	if status, ok := updates["status"].(string); ok && status != "" {
-		station.Status = status
+	if radar, ok := updates["radar"].(*models.RadarParams); ok {
+		if err := validateRadar(radar); err != nil {
+			return nil, err
+		}
+		station.Radar = radar
+	}
+	if zone, ok := updates["time_zone"].(string); ok {
+		if _, err := loadZone(zone); err != nil {
+			return nil, err
+		}
+		station.TimeZone = zone
 	}
-	if note, ok := updates["note"].(string); ok {
-		station.Note = note
+	if groupID, ok := updates["group_id"].(*uint); ok {
+		// 0 removes the station from its group
+		if *groupID == 0 {
+			groupID = nil
+		}
+		if err := checkGroup(s.db, groupID); err != nil {
+			return nil, err
+		}
+		station.GroupID = groupID
 	}
 
-	station.UpdatedAt = time.Now().Unix()
+	station.UpdatedAt = s.now().Unix()
 
 	// Save updated station
 	key := fmt.Sprintf("station:%d", station.ID)
@@ -131,18 +168,43 @@ func (s *StationService) UpdatePartial(id uint, updates map[string]interface{})
 	if err != nil {
 		return nil, err
 	}
+	if err := s.reindexGeo(&old, station); err != nil {
+		return nil, err
+	}
 
 	return station, nil
 }
 
 func (s *StationService) Delete(id uint) error {
 	// Check if station exists
-	_, err := s.GetByID(id)
+	station, err := s.GetByID(id)
 	if err != nil {
 		return errors.New("station not found")
 	}
+	// Equipment and its maintenance log outlive the station; delete it first.
+	hasEquipment := false
+	err = s.db.IteratePrefix("equipment:", func(_ string, val []byte) error {
+		var ref struct {
+			StationID uint `json:"station_id"`
+		}
+		if err := json.Unmarshal(val, &ref); err == nil && ref.StationID == id {
+			hasEquipment = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if hasEquipment {
+		return errors.New("station has equipment")
+	}
 	key := fmt.Sprintf("station:%d", id)
-	return s.db.Delete(key)
+	if err := s.db.Delete(key); err != nil {
+		return err
+	}
+	s.healthSvc.DeleteByStation(id)
+	s.deleteNotes(id)
+	return s.reindexGeo(station, nil)
 }
 
 func (s *StationService) GetByID(id uint) (*models.Station, error) {
@@ -154,6 +216,16 @@ func (s *StationService) GetByID(id uint) (*models.Station, error) {
 	return &station, nil
 }
 
+// GetWithStatus retrieves a station with its current status.
+func (s *StationService) GetWithStatus(id uint) (*models.Station, error) {
+	station, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	s.withStatus(station)
+	return station, nil
+}
+
 // List retrieves all stations
 func (s *StationService) List() ([]*models.Station, error) {
 	var stations []*models.Station
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+func TestDeleteStationWithEquipment(t *testing.T) {
+	db := newTestDB(t)
+	sched := NewScheduleService(db, nil)
+	stations := NewStationService(db, sched, NewHealthService(db, sched, NewNotifier()))
+	equipment := NewEquipmentService(db)
+
+	for _, name := range []string{"S1", "S2"} {
+		if err := stations.Create(&models.Station{Name: name, Latitude: 10, Longitude: 107}); err != nil {
+			t.Fatalf("Create %s: %v", name, err)
+		}
+	}
+	eq := &models.Equipment{StationID: 1, Type: "RADAR", Model: "JRC JMA-5300"}
+	if err := equipment.Create(eq); err != nil {
+		t.Fatalf("Create equipment: %v", err)
+	}
+
+	if err := stations.Delete(1); err == nil || err.Error() != "station has equipment" {
+		t.Errorf("Delete station with equipment: error = %v, want station has equipment", err)
+	}
+	if _, err := stations.GetByID(1); err != nil {
+		t.Errorf("station 1 gone after refused delete: %v", err)
+	}
+	if err := stations.Delete(2); err != nil {
+		t.Errorf("Delete station without equipment: %v", err)
+	}
+
+	if err := equipment.Delete(eq.ID); err != nil {
+		t.Fatalf("Delete equipment: %v", err)
+	}
+	if err := stations.Delete(1); err != nil {
+		t.Errorf("Delete station after its equipment: %v", err)
+	}
+}
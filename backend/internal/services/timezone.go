@@ -0,0 +1,49 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// ErrInvalidTimeZone is returned (wrapped) for unknown station time zones.
+var ErrInvalidTimeZone = errors.New("invalid time zone")
+
+// scheduleZone is the time zone of stations that do not set one.
+var scheduleZone = time.FixedZone("UTC+7", 7*3600)
+
+var zoneCache sync.Map // IANA name → *time.Location
+
+// loadZone returns the location of an IANA time zone name such as
+// "Asia/Ho_Chi_Minh"; an empty name is the default UTC+7.
+func loadZone(name string) (*time.Location, error) {
+	if name == "" {
+		return scheduleZone, nil
+	}
+	if loc, ok := zoneCache.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, fmt.Errorf("%w: unknown zone %q, use an IANA name like Asia/Ho_Chi_Minh", ErrInvalidTimeZone, name)
+	}
+	zoneCache.Store(name, loc)
+	return loc, nil
+}
+
+// stationZone returns the time zone the schedules of a station are read in.
+// Unknown stations and zones fall back to UTC+7.
+func stationZone(db *DB, stationID uint) *time.Location {
+	var st models.Station
+	if err := db.GetJSON(fmt.Sprintf("station:%d", stationID), &st); err != nil {
+		return scheduleZone
+	}
+	loc, err := loadZone(st.TimeZone)
+	if err != nil {
+		return scheduleZone
+	}
+	return loc
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // zones on hosts without a zoneinfo database
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+func mustZone(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%s): %v", name, err)
+	}
+	return loc
+}
+
+func TestActiveAtNearMidnight(t *testing.T) {
+	hcm := mustZone(t, "Asia/Ho_Chi_Minh")
+	ny := mustZone(t, "America/New_York") // DST: 2026-03-08 02:00 → 03:00, 2026-11-01 02:00 → 01:00
+
+	tests := []struct {
+		name       string
+		zone       string
+		start, end string
+		at         time.Time
+		want       bool
+	}{
+		{"overnight 23:59", "Asia/Ho_Chi_Minh", "2200", "0200", time.Date(2026, 10, 18, 23, 59, 0, 0, hcm), true},
+		{"overnight 00:00", "Asia/Ho_Chi_Minh", "2200", "0200", time.Date(2026, 10, 19, 0, 0, 0, 0, hcm), true},
+		{"overnight end", "Asia/Ho_Chi_Minh", "2200", "0200", time.Date(2026, 10, 19, 2, 0, 0, 0, hcm), false},
+		{"before overnight start", "Asia/Ho_Chi_Minh", "2200", "0200", time.Date(2026, 10, 18, 21, 59, 59, 0, hcm), false},
+		{"ends at midnight 23:59", "Asia/Ho_Chi_Minh", "2300", "0000", time.Date(2026, 10, 18, 23, 59, 0, 0, hcm), true},
+		{"ends at midnight 00:00", "Asia/Ho_Chi_Minh", "2300", "0000", time.Date(2026, 10, 19, 0, 0, 0, 0, hcm), false},
+		{"default zone is UTC+7", "", "2200", "0200", time.Date(2026, 10, 18, 16, 59, 0, 0, time.UTC), true},
+		{"spring forward 23:59", "America/New_York", "2200", "0200", time.Date(2026, 3, 7, 23, 59, 0, 0, ny), true},
+		{"spring forward 00:00", "America/New_York", "2200", "0200", time.Date(2026, 3, 8, 0, 0, 0, 0, ny), true},
+		{"spring forward 01:59", "America/New_York", "2200", "0200", time.Date(2026, 3, 8, 1, 59, 0, 0, ny), true},
+		{"spring forward 03:00", "America/New_York", "2200", "0200", time.Date(2026, 3, 8, 3, 0, 0, 0, ny), false},
+		{"fall back 23:59", "America/New_York", "2200", "0200", time.Date(2026, 10, 31, 23, 59, 0, 0, ny), true},
+		{"fall back 00:00", "America/New_York", "2200", "0200", time.Date(2026, 11, 1, 0, 0, 0, 0, ny), true},
+		{"fall back second 01:30", "America/New_York", "2200", "0200", time.Date(2026, 11, 1, 6, 30, 0, 0, time.UTC), true},
+		{"fall back 02:00", "America/New_York", "2200", "0200", time.Date(2026, 11, 1, 2, 0, 0, 0, ny), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			if err := db.PutJSON("station:1", models.Station{ID: 1, Name: "S1", TimeZone: tt.zone}); err != nil {
+				t.Fatal(err)
+			}
+			svc := NewScheduleService(db, func() time.Time { return tt.at })
+			if err := svc.Create(&models.Schedule{StationID: 1, StartHHMM: tt.start, EndHHMM: tt.end}); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			// The instant in another zone must not matter.
+			if got, err := svc.ActiveAt(1, tt.at.UTC()); err != nil || got != tt.want {
+				t.Errorf("ActiveAt(%v) = %v, %v; want %v", tt.at, got, err, tt.want)
+			}
+			if got, err := svc.IsStationActiveNow(1); err != nil || got != tt.want {
+				t.Errorf("IsStationActiveNow at %v = %v, %v; want %v", tt.at, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestServicesShareClock(t *testing.T) {
+	at := time.Date(2026, 10, 18, 23, 59, 0, 0, time.UTC)
+	db := newTestDB(t)
+	sched := NewScheduleService(db, func() time.Time { return at })
+	health := NewHealthService(db, sched, NewNotifier())
+	station := NewStationService(db, sched, health)
+
+	for name, now := range map[string]func() time.Time{"health": health.now, "station": station.now} {
+		if got := now(); !got.Equal(at) {
+			t.Errorf("%s clock = %v, want %v", name, got, at)
+		}
+	}
+}
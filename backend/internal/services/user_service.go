@@ -36,6 +36,9 @@ func (s *UserService) NextID() int {
 
 // Create inserts a new user if username not exists.
 func (s *UserService) Create(u *models.User) error {
+	if err := checkGroup(s.db, u.GroupID); err != nil {
+		return err
+	}
 	key := "user:" + u.Username
 	exists, err := s.db.Exists(key)
 	if err != nil {
@@ -220,6 +223,16 @@ func (s *UserService) UpdateUser(id int, updates map[string]interface{}) (*model
 	if stationID, ok := updates["station_id"].(*uint); ok {
 		user.StationID = stationID
 	}
+	if groupID, ok := updates["group_id"].(*uint); ok {
+		// 0 removes the user from their group
+		if *groupID == 0 {
+			groupID = nil
+		}
+		if err := checkGroup(s.db, groupID); err != nil {
+			return nil, err
+		}
+		user.GroupID = groupID
+	}
 
 	user.UpdatedAt = time.Now().Unix()
 
@@ -307,6 +320,16 @@ func (s *UserService) UpdateByUsername(username string, updates map[string]inter
 	if stationID, ok := updates["station_id"].(*uint); ok {
 		user.StationID = stationID
 	}
+	if groupID, ok := updates["group_id"].(*uint); ok {
+		// 0 removes the user from their group
+		if *groupID == 0 {
+			groupID = nil
+		}
+		if err := checkGroup(s.db, groupID); err != nil {
+			return nil, err
+		}
+		user.GroupID = groupID
+	}
 
 	user.UpdatedAt = time.Now().Unix()
 
@@ -335,6 +358,8 @@ func (s *UserService) DeleteByUsername(username string) error {
 		return err
 	}
 
+	s.revokeCalendarToken(username)
+
 	// Delete by both username and ID keys
 	userKey := "user:" + username
 	userIDKey := "user_id:" + strconv.Itoa(user.ID)
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// vesselColumn describes one vessel field in import/export files.
+type vesselColumn struct {
+	name string
+	get  func(v *models.Vessel) string
+	set  func(v *models.Vessel, s string) error // nil for read-only columns
+}
+
+func floatColumn(name string, field func(v *models.Vessel) *float64) vesselColumn {
+	return vesselColumn{
+		name: name,
+		get: func(v *models.Vessel) string {
+			if f := *field(v); f != 0 {
+				return strconv.FormatFloat(f, 'f', -1, 64)
+			}
+			return ""
+		},
+		set: func(v *models.Vessel, s string) error {
+			f, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", "."), 64)
+			if err != nil {
+				return fmt.Errorf("%s: not a number", name)
+			}
+			*field(v) = f
+			return nil
+		},
+	}
+}
+
+func stringColumn(name string, field func(v *models.Vessel) *string) vesselColumn {
+	return vesselColumn{
+		name: name,
+		get:  func(v *models.Vessel) string { return *field(v) },
+		set: func(v *models.Vessel, s string) error {
+			*field(v) = s
+			return nil
+		},
+	}
+}
+
+// vesselColumns is the column order of exports; names match the JSON tags
+// of models.Vessel.
+var vesselColumns = []vesselColumn{
+	{name: "id", get: func(v *models.Vessel) string { return strconv.FormatUint(uint64(v.ID), 10) }},
+	stringColumn("name", func(v *models.Vessel) *string { return &v.Name }),
+	stringColumn("mmsi", func(v *models.Vessel) *string { return &v.MMSI }),
+	{name: "mmsi_type", get: func(v *models.Vessel) string { return v.MMSIType }},
+	{name: "flag_code", get: func(v *models.Vessel) string { return v.FlagCode }},
+	{name: "flag_country", get: func(v *models.Vessel) string { return v.FlagCountry }},
+	stringColumn("imo_number", func(v *models.Vessel) *string { return &v.IMO }),
+	stringColumn("call_sign", func(v *models.Vessel) *string { return &v.CallSign }),
+	stringColumn("kind", func(v *models.Vessel) *string { return &v.Kind }),
+	stringColumn("class", func(v *models.Vessel) *string { return &v.Class }),
+	floatColumn("length", func(v *models.Vessel) *float64 { return &v.Length }),
+	floatColumn("beam", func(v *models.Vessel) *float64 { return &v.Beam }),
+	floatColumn("draught", func(v *models.Vessel) *float64 { return &v.Draught }),
+	floatColumn("gross_tonnage", func(v *models.Vessel) *float64 { return &v.GrossTonnage }),
+	floatColumn("max_speed_knots", func(v *models.Vessel) *float64 { return &v.MaxSpeedKnots }),
+	stringColumn("size", func(v *models.Vessel) *string { return &v.Size }),
+	stringColumn("weight", func(v *models.Vessel) *string { return &v.Weight }),
+	stringColumn("max_speed", func(v *models.Vessel) *string { return &v.MaxSpeed }),
+	stringColumn("specs", func(v *models.Vessel) *string { return &v.Specs }),
+	stringColumn("description", func(v *models.Vessel) *string { return &v.Description }),
+}
+
+func findVesselColumn(name string) (vesselColumn, bool) {
+	for _, col := range vesselColumns {
+		if col.name == name {
+			return col, true
+		}
+	}
+	return vesselColumn{}, false
+}
+
+// ImportRowResult reports what happened (or would happen, in a dry run) to
+// one input row. Rows are numbered from 1, not counting the CSV header.
+type ImportRowResult struct {
+	Row      int    `json:"row"`
+	MMSI     string `json:"mmsi,omitempty"`
+	Action   string `json:"action"` // "create", "update" or "error"
+	VesselID uint   `json:"vessel_id,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ImportReport summarises a bulk import.
+type ImportReport struct {
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Failed  int               `json:"failed"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+// ParseVesselRows reads CSV (with a header line) or a JSON array of objects
+// into rows keyed by vessel field name. mapping renames source columns to
+// vessel fields (e.g. {"Ship Name": "name"}); columns that are neither mapped
+// nor named like a vessel field are rejected so typos surface early.
+func ParseVesselRows(r io.Reader, format string, mapping map[string]string) ([]map[string]string, error) {
+	for src, dst := range mapping {
+		col, ok := findVesselColumn(dst)
+		if !ok || col.set == nil {
+			return nil, fmt.Errorf("mapping %q: unknown or read-only field %q", src, dst)
+		}
+	}
+	field := func(src string) (string, error) {
+		src = strings.TrimSpace(src)
+		if dst, ok := mapping[src]; ok {
+			return dst, nil
+		}
+		name := strings.ToLower(src)
+		if _, ok := findVesselColumn(name); ok {
+			return name, nil
+		}
+		return "", fmt.Errorf("unknown column %q", src)
+	}
+
+	var rows []map[string]string
+	switch format {
+	case "csv":
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		records, err := cr.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if len(records) == 0 {
+			return nil, errors.New("CSV file is empty")
+		}
+		header := make([]string, len(records[0]))
+		for i, h := range records[0] {
+			if header[i], err = field(strings.TrimPrefix(h, "\ufeff")); err != nil {
+				return nil, err
+			}
+		}
+		for _, rec := range records[1:] {
+			row := make(map[string]string, len(header))
+			for i, val := range rec {
+				if i < len(header) {
+					row[header[i]] = strings.TrimSpace(val)
+				}
+			}
+			rows = append(rows, row)
+		}
+	case "json":
+		dec := json.NewDecoder(r)
+		dec.UseNumber()
+		var items []map[string]any
+		if err := dec.Decode(&items); err != nil {
+			return nil, fmt.Errorf("invalid JSON: expected an array of objects: %w", err)
+		}
+		for _, item := range items {
+			row := make(map[string]string, len(item))
+			for k, v := range item {
+				name, err := field(k)
+				if err != nil {
+					return nil, err
+				}
+				if v != nil {
+					row[name] = strings.TrimSpace(fmt.Sprint(v))
+				}
+			}
+			rows = append(rows, row)
+		}
+	default:
+		return nil, errors.New("format must be csv or json")
+	}
+	return rows, nil
+}
+
+// Import upserts vessels by MMSI: rows whose MMSI is already registered
+// update that vessel (only the non-empty cells are applied), the others
+// create a new vessel. With dryRun nothing is written but every row is still
+// fully validated. Rows fail independently of each other.
+func (s *VesselService) Import(rows []map[string]string, dryRun bool) ImportReport {
+	report := ImportReport{DryRun: dryRun, Total: len(rows), Rows: make([]ImportRowResult, 0, len(rows))}
+	seen := make(map[string]int)
+
+	for i, row := range rows {
+		res := ImportRowResult{Row: i + 1, MMSI: strings.TrimSpace(row["mmsi"])}
+		fail := func(err error) {
+			res.Action, res.Error = "error", strings.TrimPrefix(err.Error(), ErrInvalidVessel.Error()+": ")
+			report.Failed++
+			report.Rows = append(report.Rows, res)
+		}
+
+		if res.MMSI == "" {
+			fail(errors.New("mmsi is required"))
+			continue
+		}
+		if first, dup := seen[res.MMSI]; dup {
+			fail(fmt.Errorf("duplicate MMSI in file (row %d)", first))
+			continue
+		}
+		seen[res.MMSI] = res.Row
+
+		vessel := &models.Vessel{}
+		existing, err := s.GetByMMSI(res.MMSI)
+		if err == nil {
+			vessel = existing
+			res.Action, res.VesselID = "update", existing.ID
+		} else if err.Error() == "vessel not found" {
+			res.Action = "create"
+		} else {
+			fail(err)
+			continue
+		}
+
+		var setErr error
+		for _, col := range vesselColumns {
+			val, ok := row[col.name]
+			if !ok || val == "" || col.set == nil {
+				continue
+			}
+			if setErr = col.set(vessel, val); setErr != nil {
+				break
+			}
+		}
+		if setErr != nil {
+			fail(setErr)
+			continue
+		}
+
+		if dryRun {
+			check := *vessel
+			parseLegacyDimensions(&check)
+			if err := validateVessel(&check); err != nil {
+				fail(err)
+				continue
+			}
+		} else if res.Action == "update" {
+			if err := s.Update(vessel); err != nil {
+				fail(err)
+				continue
+			}
+		} else {
+			if err := s.Create(vessel); err != nil {
+				fail(err)
+				continue
+			}
+			res.VesselID = vessel.ID
+		}
+
+		if res.Action == "update" {
+			report.Updated++
+		} else {
+			report.Created++
+		}
+		report.Rows = append(report.Rows, res)
+	}
+	return report
+}
+
+// ExportVessels writes vessels as CSV (with a header line) or as a JSON array.
+func ExportVessels(w io.Writer, vessels []*models.Vessel, format string) error {
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		header := make([]string, len(vesselColumns))
+		for i, col := range vesselColumns {
+			header[i] = col.name
+		}
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+		for _, v := range vessels {
+			rec := make([]string, len(vesselColumns))
+			for i, col := range vesselColumns {
+				rec[i] = col.get(v)
+			}
+			if err := cw.Write(rec); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case "json":
+		if vessels == nil {
+			vessels = []*models.Vessel{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(vessels)
+	default:
+		return errors.New("format must be csv or json")
+	}
+}
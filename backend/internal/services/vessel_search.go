@@ -0,0 +1,346 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+	"golang.org/x/text/unicode/norm"
+)
+
+// searchIndexVersion is bumped whenever the tokenizer or key layout changes;
+// the index is rebuilt on startup when the stored version differs.
+const searchIndexVersion = 1
+
+// Searchable vessel fields and their weight in the ranking.
+var searchFields = []struct {
+	name   string
+	weight float64
+	get    func(v *models.Vessel) string
+}{
+	{"name", 3, func(v *models.Vessel) string { return v.Name }},
+	{"mmsi", 3, func(v *models.Vessel) string { return v.MMSI }},
+	{"call_sign", 3, func(v *models.Vessel) string { return v.CallSign }},
+	{"description", 1, func(v *models.Vessel) string { return v.Description }},
+}
+
+// VesselMatch is one ranked search result.
+type VesselMatch struct {
+	Vessel        *models.Vessel `json:"vessel"`
+	Score         float64        `json:"score"`
+	MatchedFields []string       `json:"matched_fields"`
+}
+
+// foldText lowercases s and strips diacritics, so "Đại Dương" and
+// "dai duong" compare equal.
+func foldText(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		switch r {
+		case 'đ', 'Đ':
+			r = 'd'
+		case 'ø', 'Ø':
+			r = 'o'
+		case 'ł', 'Ł':
+			r = 'l'
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// tokenize folds s and splits it into distinct letter/digit runs.
+func tokenize(s string) []string {
+	words := strings.FieldsFunc(foldText(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := make(map[string]bool, len(words))
+	out := words[:0]
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// vesselTokens maps every token of the searchable fields of v to the fields
+// it occurs in.
+func vesselTokens(v *models.Vessel) map[string][]string {
+	tokens := make(map[string][]string)
+	for _, f := range searchFields {
+		for _, t := range tokenize(f.get(v)) {
+			tokens[t] = append(tokens[t], f.name)
+		}
+	}
+	return tokens
+}
+
+// indexVessel writes the search index entries of v. Entries are keyed by
+// token and vessel ID ("vessel_token:{token}:{id}"), so vessels sharing a
+// name do not overwrite each other.
+func (s *VesselService) indexVessel(v *models.Vessel) error {
+	for token, fields := range vesselTokens(v) {
+		if err := s.db.PutJSON(fmt.Sprintf("vessel_token:%s:%d", token, v.ID), fields); err != nil {
+			return fmt.Errorf("failed to update search index: %w", err)
+		}
+	}
+	return nil
+}
+
+// unindexVessel removes the search index entries of v.
+func (s *VesselService) unindexVessel(v *models.Vessel) {
+	for token := range vesselTokens(v) {
+		s.db.Delete(fmt.Sprintf("vessel_token:%s:%d", token, v.ID)) // Ignore error
+	}
+}
+
+// RebuildSearchIndex drops the search index, including the legacy
+// "vessel_name:" keys, and indexes every vessel again.
+func (s *VesselService) RebuildSearchIndex() error {
+	var stale []string
+	for _, prefix := range []string{"vessel_name:", "vessel_token:"} {
+		if err := s.db.IteratePrefix(prefix, func(key string, _ []byte) error {
+			stale = append(stale, key)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	for _, k := range stale {
+		s.db.Delete(k) // Ignore error
+	}
+
+	vessels, err := s.List()
+	if err != nil {
+		return err
+	}
+	for _, v := range vessels {
+		if err := s.indexVessel(v); err != nil {
+			return err
+		}
+	}
+	return s.db.PutJSON("vessel_search_version", searchIndexVersion)
+}
+
+// ensureSearchIndex rebuilds the search index when it was built by an older
+// version of the service (or not at all).
+func (s *VesselService) ensureSearchIndex() {
+	var version int
+	if err := s.db.GetJSON("vessel_search_version", &version); err == nil && version == searchIndexVersion {
+		return
+	}
+	if err := s.RebuildSearchIndex(); err != nil {
+		log.Println("Vessel search index rebuild failed:", err)
+		return
+	}
+	log.Println("Vessel search index rebuilt")
+}
+
+// maxEdits is the edit distance tolerated for a query term of n runes.
+func maxEdits(n int) int {
+	switch {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// termScore rates how well the query term q matches the indexed token t,
+// from 1 (identical) down to 0 (no match). Numeric terms such as MMSIs only
+// match exactly or by prefix; an MMSI one digit off is a different vessel.
+func termScore(q, t string) float64 {
+	switch {
+	case q == t:
+		return 1
+	case strings.HasPrefix(t, q):
+		return 0.8
+	case len(q) >= 3 && strings.Contains(t, q):
+		return 0.5
+	}
+	if _, err := strconv.ParseUint(q, 10, 64); err == nil {
+		return 0
+	}
+
+	qr, tr := []rune(q), []rune(t)
+	limit := maxEdits(len(qr))
+	if limit == 0 {
+		return 0
+	}
+	if d := editDistance(qr, tr, limit); d <= limit {
+		return 0.6 / float64(d)
+	}
+	// A misspelt prefix: "entrep" for "enterprise".
+	if len(tr) > len(qr) {
+		if d := editDistance(qr, tr[:len(qr)], limit); d <= limit {
+			return 0.4 / float64(d)
+		}
+	}
+	return 0
+}
+
+// editDistance returns the optimal string alignment distance between a and
+// b (Levenshtein plus adjacent transpositions), or limit+1 as soon as it is
+// known to exceed limit.
+func editDistance(a, b []rune, limit int) int {
+	if d := len(a) - len(b); d > limit || -d > limit {
+		return limit + 1
+	}
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+			rowMin = min(rowMin, cur[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// Search returns the vessels matching every term of query, best match first.
+// Terms are folded like the index, so accents and case are ignored, and may
+// match a field token exactly, by prefix, as a substring or within a small
+// edit distance. fields restricts the search to some of "name", "mmsi",
+// "call_sign" and "description" (all of them when empty). limit <= 0 means
+// no limit.
+func (s *VesselService) Search(query string, fields []string, limit int) ([]VesselMatch, error) {
+	terms := tokenize(query)
+	if len(terms) == 0 {
+		return []VesselMatch{}, nil
+	}
+	weights := make(map[string]float64)
+	for _, f := range searchFields {
+		if len(fields) == 0 || slices.Contains(fields, f.name) {
+			weights[f.name] = f.weight
+		}
+	}
+
+	type hit struct {
+		best   []float64 // best weighted score per query term
+		fields map[string]bool
+	}
+	hits := make(map[uint]*hit)
+
+	lastToken := ""
+	scores := make([]float64, len(terms))
+	err := s.db.IteratePrefix("vessel_token:", func(key string, val []byte) error {
+		rest := strings.TrimPrefix(key, "vessel_token:")
+		sep := strings.LastIndexByte(rest, ':')
+		if sep < 0 {
+			return nil
+		}
+		token := rest[:sep]
+		id, err := strconv.ParseUint(rest[sep+1:], 10, 64)
+		if err != nil {
+			return nil // Skip invalid records
+		}
+		// Keys are sorted by token, so each token is scored only once.
+		if token != lastToken {
+			lastToken = token
+			for i, q := range terms {
+				scores[i] = termScore(q, token)
+			}
+		}
+
+		var tokenFields []string
+		if err := json.Unmarshal(val, &tokenFields); err != nil {
+			return nil // Skip invalid records
+		}
+		for i, sc := range scores {
+			if sc == 0 {
+				continue
+			}
+			for _, f := range tokenFields {
+				w, ok := weights[f]
+				if !ok {
+					continue
+				}
+				h := hits[uint(id)]
+				if h == nil {
+					h = &hit{best: make([]float64, len(terms)), fields: make(map[string]bool)}
+					hits[uint(id)] = h
+				}
+				h.fields[f] = true
+				h.best[i] = max(h.best[i], sc*w)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	folded := strings.Join(terms, " ")
+	matches := []VesselMatch{}
+	for id, h := range hits {
+		total := 0.0
+		complete := true
+		for _, b := range h.best {
+			if b == 0 {
+				complete = false
+				break
+			}
+			total += b
+		}
+		if !complete {
+			continue
+		}
+		vessel, err := s.GetByID(id)
+		if err != nil {
+			continue // Stale index entry
+		}
+		if strings.Join(tokenize(vessel.Name), " ") == folded {
+			total++ // Whole-name match ranks first
+		}
+		m := VesselMatch{Vessel: vessel, Score: total}
+		for f := range h.fields {
+			m.MatchedFields = append(m.MatchedFields, f)
+		}
+		sort.Strings(m.MatchedFields)
+		matches = append(matches, m)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		if a, b := foldText(matches[i].Vessel.Name), foldText(matches[j].Vessel.Name); a != b {
+			return a < b
+		}
+		return matches[i].Vessel.ID < matches[j].Vessel.ID
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
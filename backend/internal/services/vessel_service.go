@@ -3,6 +3,8 @@ package services
 import (
 	"errors"
 	"fmt"
+	"log"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,16 +16,16 @@ type VesselService struct {
 }
 
 func NewVesselService(db *DB) *VesselService {
-	return &VesselService{db: db}
+	sv := &VesselService{db: db}
+	sv.ensureLegacyFields()
+	sv.ensureSearchIndex()
+	return sv
 }
 
 func (s *VesselService) Create(vessel *models.Vessel) error {
-	// Validate required fields
-	if vessel.Name == "" {
-		return errors.New("vessel name is required")
-	}
-	if vessel.MMSI == "" {
-		return errors.New("vessel MMSI is required")
+	parseLegacyDimensions(vessel)
+	if err := validateVessel(vessel); err != nil {
+		return err
 	}
 
 	// Check if MMSI already exists
@@ -55,13 +57,8 @@ func (s *VesselService) Create(vessel *models.Vessel) error {
 		return fmt.Errorf("failed to create MMSI index: %w", err)
 	}
 
-	// Create name index (lowercase for case-insensitive search)
-	nameKey := fmt.Sprintf("vessel_name:%s", strings.ToLower(vessel.Name))
-	if err := s.db.PutJSON(nameKey, vessel.ID); err != nil {
-		return fmt.Errorf("failed to create name index: %w", err)
-	}
-
-	return nil
+	// Create search index
+	return s.indexVessel(vessel)
 }
 
 func (s *VesselService) GetByID(id uint) (*models.Vessel, error) {
@@ -92,33 +89,18 @@ func (s *VesselService) GetByMMSI(mmsi string) (*models.Vessel, error) {
 	return s.GetByID(vesselID)
 }
 
+// SearchByName returns the vessels whose name matches name, ignoring case
+// and diacritics and tolerating small typos, best match first.
 func (s *VesselService) SearchByName(name string) ([]*models.Vessel, error) {
-	var vessels []*models.Vessel
-	searchName := strings.ToLower(name)
-
-	err := s.db.IteratePrefix("vessel_name:", func(key string, val []byte) error {
-		// Extract the name from the key
-		keyName := strings.TrimPrefix(key, "vessel_name:")
-
-		// Check if the name contains the search term
-		if strings.Contains(keyName, searchName) {
-			var vesselID uint
-			if err := s.db.GetJSON(key, &vesselID); err != nil {
-				return nil // Skip invalid records
-			}
-
-			vessel, err := s.GetByID(vesselID)
-			if err != nil {
-				return nil // Skip if vessel not found
-			}
-
-			vessels = append(vessels, vessel)
-		}
-
-		return nil
-	})
-
-	return vessels, err
+	matches, err := s.Search(name, []string{"name"}, 0)
+	if err != nil {
+		return nil, err
+	}
+	vessels := make([]*models.Vessel, len(matches))
+	for i, m := range matches {
+		vessels[i] = m.Vessel
+	}
+	return vessels, nil
 }
 
 func (s *VesselService) List() ([]*models.Vessel, error) {
@@ -137,12 +119,9 @@ func (s *VesselService) List() ([]*models.Vessel, error) {
 }
 
 func (s *VesselService) Update(vessel *models.Vessel) error {
-	// Validate required fields
-	if vessel.Name == "" {
-		return errors.New("vessel name is required")
-	}
-	if vessel.MMSI == "" {
-		return errors.New("vessel MMSI is required")
+	parseLegacyDimensions(vessel)
+	if err := validateVessel(vessel); err != nil {
+		return err
 	}
 
 	// Get existing vessel
@@ -170,17 +149,10 @@ func (s *VesselService) Update(vessel *models.Vessel) error {
 		}
 	}
 
-	// Update name index if name changed
-	if !strings.EqualFold(existing.Name, vessel.Name) {
-		// Remove old name index
-		oldNameKey := fmt.Sprintf("vessel_name:%s", strings.ToLower(existing.Name))
-		s.db.Delete(oldNameKey) // Ignore error
-
-		// Create new name index
-		newNameKey := fmt.Sprintf("vessel_name:%s", strings.ToLower(vessel.Name))
-		if err := s.db.PutJSON(newNameKey, vessel.ID); err != nil {
-			return fmt.Errorf("failed to create new name index: %w", err)
-		}
+	// Re-index searchable fields
+	s.unindexVessel(existing)
+	if err := s.indexVessel(vessel); err != nil {
+		return err
 	}
 
 	vessel.UpdatedAt = time.Now().Unix()
@@ -206,13 +178,135 @@ func (s *VesselService) Delete(id uint) error {
 	mmsiKey := fmt.Sprintf("vessel_mmsi:%s", vessel.MMSI)
 	s.db.Delete(mmsiKey) // Ignore error
 
-	// Clean up name index
-	nameKey := fmt.Sprintf("vessel_name:%s", strings.ToLower(vessel.Name))
-	s.db.Delete(nameKey) // Ignore error
+	// Clean up search index
+	s.unindexVessel(vessel)
 
 	return nil
 }
 
+// legacyFieldsVersion is bumped whenever MigrateLegacyFields learns to fill
+// more fields, so that it runs once more over the stored vessels.
+const legacyFieldsVersion = 1
+
+// ensureLegacyFields runs MigrateLegacyFields once per legacyFieldsVersion.
+func (s *VesselService) ensureLegacyFields() {
+	var version int
+	if err := s.db.GetJSON("vessel_legacy_version", &version); err == nil && version == legacyFieldsVersion {
+		return
+	}
+	migrated, err := s.MigrateLegacyFields()
+	if err != nil {
+		log.Println("Vessel migration failed:", err)
+		return
+	}
+	if migrated > 0 {
+		log.Println("Migrated vessels:", migrated)
+	}
+	s.db.PutJSON("vessel_legacy_version", legacyFieldsVersion) // Ignore error
+}
+
+// MigrateLegacyFields parses the free-text Size and MaxSpeed of stored
+// vessels into the typed numeric fields and derives the MMSI type and flag
+// state where possible. It returns the number of vessels updated and is safe
+// to run repeatedly.
+func (s *VesselService) MigrateLegacyFields() (int, error) {
+	vessels, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for _, v := range vessels {
+		changed := parseLegacyDimensions(v)
+		if v.MMSIType == "" {
+			// Records with a malformed MMSI are left as they are.
+			changed = applyMMSIInfo(v) == nil || changed
+		}
+		if !changed {
+			continue
+		}
+		if err := s.db.PutJSON(fmt.Sprintf("vessel:%d", v.ID), v); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	return migrated, nil
+}
+
+// VesselFilter holds optional numeric range and flag/MMSI type filters and
+// the sort order for vessel listings. Nil bounds are ignored; vessels whose
+// value is unknown (0) are excluded as soon as a bound on that field is set.
+type VesselFilter struct {
+	MinLength, MaxLength             *float64
+	MinBeam, MaxBeam                 *float64
+	MinDraught, MaxDraught           *float64
+	MinGrossTonnage, MaxGrossTonnage *float64
+	MinSpeed, MaxSpeed               *float64
+
+	FlagCountry string // ISO code or country name, case-insensitive
+	MMSIType    string
+
+	SortBy string // "length", "beam", "draught", "gross_tonnage", "max_speed_knots", "name"
+	Desc   bool
+}
+
+// vesselSortFields maps sortable field names to their accessor.
+var vesselSortFields = map[string]func(*models.Vessel) float64{
+	"length":          func(v *models.Vessel) float64 { return v.Length },
+	"beam":            func(v *models.Vessel) float64 { return v.Beam },
+	"draught":         func(v *models.Vessel) float64 { return v.Draught },
+	"gross_tonnage":   func(v *models.Vessel) float64 { return v.GrossTonnage },
+	"max_speed_knots": func(v *models.Vessel) float64 { return v.MaxSpeedKnots },
+}
+
+// ValidSortField reports whether name can be used as VesselFilter.SortBy.
+func ValidSortField(name string) bool {
+	_, ok := vesselSortFields[name]
+	return ok || name == "name"
+}
+
+// Apply returns the vessels matching the filter, sorted as requested.
+func (f VesselFilter) Apply(vessels []*models.Vessel) []*models.Vessel {
+	inRange := func(v float64, min, max *float64) bool {
+		if min == nil && max == nil {
+			return true
+		}
+		if v == 0 {
+			return false
+		}
+		return (min == nil || v >= *min) && (max == nil || v <= *max)
+	}
+
+	out := make([]*models.Vessel, 0, len(vessels))
+	for _, v := range vessels {
+		if inRange(v.Length, f.MinLength, f.MaxLength) &&
+			inRange(v.Beam, f.MinBeam, f.MaxBeam) &&
+			inRange(v.Draught, f.MinDraught, f.MaxDraught) &&
+			inRange(v.GrossTonnage, f.MinGrossTonnage, f.MaxGrossTonnage) &&
+			inRange(v.MaxSpeedKnots, f.MinSpeed, f.MaxSpeed) &&
+			(f.FlagCountry == "" || strings.EqualFold(v.FlagCode, f.FlagCountry) || strings.EqualFold(v.FlagCountry, f.FlagCountry)) &&
+			(f.MMSIType == "" || strings.EqualFold(v.MMSIType, f.MMSIType)) {
+			out = append(out, v)
+		}
+	}
+
+	if f.SortBy == "name" {
+		sort.SliceStable(out, func(i, j int) bool {
+			if f.Desc {
+				return strings.ToLower(out[i].Name) > strings.ToLower(out[j].Name)
+			}
+			return strings.ToLower(out[i].Name) < strings.ToLower(out[j].Name)
+		})
+	} else if get, ok := vesselSortFields[f.SortBy]; ok {
+		sort.SliceStable(out, func(i, j int) bool {
+			if f.Desc {
+				return get(out[i]) > get(out[j])
+			}
+			return get(out[i]) < get(out[j])
+		})
+	}
+	return out
+}
+
 func (s *VesselService) ExistsByMMSI(mmsi string) (bool, error) {
 	mmsiKey := fmt.Sprintf("vessel_mmsi:%s", mmsi)
 	return s.db.Exists(mmsiKey)
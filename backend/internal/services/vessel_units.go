@@ -0,0 +1,185 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// ErrInvalidVessel is wrapped by every vessel validation error so handlers
+// can map them to 400 responses.
+var ErrInvalidVessel = errors.New("invalid vessel")
+
+// Plausible upper bounds for the typed vessel fields. Zero always means
+// "unknown" and is accepted.
+const (
+	maxLengthM     = 500
+	maxBeamM       = 80
+	maxDraughtM    = 30
+	maxGrossTonnes = 600000
+	maxSpeedKnots  = 100
+)
+
+var (
+	numberRe   = regexp.MustCompile(`[0-9]+(?:[.,][0-9]+)?`)
+	thousandRe = regexp.MustCompile(`([0-9]),([0-9]{3})([^0-9]|$)`)
+	callSignRe = regexp.MustCompile(`^[A-Z0-9]{3,7}$`)
+)
+
+func invalidVessel(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidVessel, fmt.Sprintf(format, args...))
+}
+
+// validateVessel checks required fields, the MMSI, numeric ranges, IMO number
+// and call sign, normalising IMO/call sign and deriving the MMSI type and flag
+// in place.
+func validateVessel(v *models.Vessel) error {
+	if v.Name == "" {
+		return invalidVessel("vessel name is required")
+	}
+	v.MMSI = strings.TrimSpace(v.MMSI)
+	if v.MMSI == "" {
+		return invalidVessel("vessel MMSI is required")
+	}
+	if err := applyMMSIInfo(v); err != nil {
+		return err
+	}
+
+	ranges := []struct {
+		name  string
+		value float64
+		max   float64
+		unit  string
+	}{
+		{"length", v.Length, maxLengthM, "m"},
+		{"beam", v.Beam, maxBeamM, "m"},
+		{"draught", v.Draught, maxDraughtM, "m"},
+		{"gross_tonnage", v.GrossTonnage, maxGrossTonnes, "GT"},
+		{"max_speed_knots", v.MaxSpeedKnots, maxSpeedKnots, "kn"},
+	}
+	for _, r := range ranges {
+		if r.value < 0 || r.value > r.max {
+			return invalidVessel("%s must be between 0 and %g %s", r.name, r.max, r.unit)
+		}
+	}
+	if v.Beam > 0 && v.Length > 0 && v.Beam > v.Length {
+		return invalidVessel("beam cannot exceed length")
+	}
+
+	if v.IMO != "" {
+		imo, err := normalizeIMO(v.IMO)
+		if err != nil {
+			return err
+		}
+		v.IMO = imo
+	}
+	if v.CallSign != "" {
+		cs := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(v.CallSign), " ", ""))
+		if !callSignRe.MatchString(cs) {
+			return invalidVessel("call sign must be 3-7 letters or digits")
+		}
+		v.CallSign = cs
+	}
+	return nil
+}
+
+// normalizeIMO accepts "9074729" or "IMO 9074729" and verifies the check
+// digit: the sum of the first six digits weighted 7..2 must end with the
+// seventh digit.
+func normalizeIMO(s string) (string, error) {
+	s = strings.TrimSpace(strings.ToUpper(s))
+	s = strings.TrimSpace(strings.TrimPrefix(s, "IMO"))
+	if len(s) != 7 {
+		return "", invalidVessel("IMO number must have 7 digits")
+	}
+	sum := 0
+	for i, r := range s {
+		if r < '0' || r > '9' {
+			return "", invalidVessel("IMO number must have 7 digits")
+		}
+		if i < 6 {
+			sum += int(r-'0') * (7 - i)
+		}
+	}
+	if sum%10 != int(s[6]-'0') {
+		return "", invalidVessel("IMO number check digit mismatch")
+	}
+	return s, nil
+}
+
+// parseLegacyDimensions fills Beam/Draught and MaxSpeedKnots from the
+// free-text Size ("width x height x depth"; the height has no typed field)
+// and MaxSpeed fields when the typed field is still unknown. Values that
+// cannot be parsed, or that would fail validation, are left alone. The legacy
+// Weight is a displacement or deadweight in tons, not a gross tonnage (a
+// volume), so it is not carried over. It reports whether anything changed.
+func parseLegacyDimensions(v *models.Vessel) bool {
+	changed := false
+
+	if v.Size != "" && v.Beam == 0 && v.Draught == 0 {
+		parts := strings.FieldsFunc(strings.ToLower(v.Size), func(r rune) bool {
+			return r == 'x' || r == '×' || r == '*'
+		})
+		dims := make([]float64, 0, 3)
+		for _, p := range parts {
+			n, ok := parseMeasure(p, map[string]float64{"ft": 0.3048, "'": 0.3048})
+			if !ok {
+				dims = nil
+				break
+			}
+			dims = append(dims, n)
+		}
+		if len(dims) >= 1 && dims[0] <= maxBeamM && (v.Length == 0 || dims[0] <= v.Length) {
+			v.Beam = dims[0]
+			changed = true
+		}
+		if len(dims) >= 3 && dims[2] <= maxDraughtM {
+			v.Draught = dims[2]
+			changed = true
+		}
+	}
+
+	if v.MaxSpeed != "" && v.MaxSpeedKnots == 0 {
+		if n, ok := parseMeasure(v.MaxSpeed, map[string]float64{"km/h": 1 / kmPerNM, "kph": 1 / kmPerNM}); ok && n <= maxSpeedKnots {
+			v.MaxSpeedKnots = n
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// parseMeasure extracts the first number in s and applies the conversion
+// factor of the first matching unit suffix, if any.
+func parseMeasure(s string, units map[string]float64) (float64, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	compact := strings.ReplaceAll(s, " ", "")
+	// Drop thousands separators ("100,000 tons"); a lone comma followed by
+	// fewer or more than three digits is kept as a decimal separator.
+	for {
+		next := thousandRe.ReplaceAllString(compact, "$1$2$3")
+		if next == compact {
+			break
+		}
+		compact = next
+	}
+	m := numberRe.FindString(compact)
+	if m == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(strings.ReplaceAll(m, ",", "."), 64)
+	if err != nil {
+		return 0, false
+	}
+	for unit, factor := range units {
+		if strings.Contains(s, unit) {
+			n *= factor
+			break
+		}
+	}
+	return n, true
+}
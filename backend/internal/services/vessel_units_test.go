@@ -0,0 +1,73 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+func TestParseLegacyDimensions(t *testing.T) {
+	tests := []struct {
+		name                         string
+		in                           models.Vessel
+		length, beam, draught, speed float64
+		changed                      bool
+	}{
+		{"width x height x depth", models.Vessel{Size: "12m x 8m x 4m"}, 0, 12, 4, 0, true},
+		{"unicode times", models.Vessel{Size: "12 × 8 × 4"}, 0, 12, 4, 0, true},
+		{"feet", models.Vessel{Size: "40ft x 20ft x 10ft"}, 0, 40 * 0.3048, 10 * 0.3048, 0, true},
+		{"width only", models.Vessel{Size: "15m"}, 0, 15, 0, 0, true},
+		{"width and height", models.Vessel{Size: "15 x 9"}, 0, 15, 0, 0, true},
+		{"typed length kept", models.Vessel{Size: "12 x 8 x 4", Length: 60}, 60, 12, 4, 0, true},
+		{"width over typed length", models.Vessel{Size: "30 x 8 x 4", Length: 20}, 20, 0, 4, 0, true},
+		{"width out of range", models.Vessel{Size: "342m x 78m x 76m"}, 0, 0, 0, 0, false},
+		{"typed beam wins", models.Vessel{Size: "12 x 8 x 4", Beam: 10}, 0, 10, 0, 0, false},
+		{"unparsable", models.Vessel{Size: "large"}, 0, 0, 0, 0, false},
+		{"weight not converted", models.Vessel{Weight: "5000 tons"}, 0, 0, 0, 0, false},
+		{"knots", models.Vessel{MaxSpeed: "30+ knots"}, 0, 0, 0, 30, true},
+		{"km/h", models.Vessel{MaxSpeed: "37.04 km/h"}, 0, 0, 0, 20, true},
+	}
+	for _, tt := range tests {
+		v := tt.in
+		changed := parseLegacyDimensions(&v)
+		if changed != tt.changed || v.Length != tt.length || !near(v.Beam, tt.beam) || !near(v.Draught, tt.draught) || !near(v.MaxSpeedKnots, tt.speed) {
+			t.Errorf("%s: changed %v, length %v, beam %v, draught %v, speed %v; want %v, %v, %v, %v, %v",
+				tt.name, changed, v.Length, v.Beam, v.Draught, v.MaxSpeedKnots, tt.changed, tt.length, tt.beam, tt.draught, tt.speed)
+		}
+		if v.GrossTonnage != 0 {
+			t.Errorf("%s: gross tonnage = %v, want 0", tt.name, v.GrossTonnage)
+		}
+	}
+}
+
+func near(a, b float64) bool { return a-b < 1e-9 && b-a < 1e-9 }
+
+func TestMigratedVesselStaysValid(t *testing.T) {
+	db := newTestDB(t)
+	legacy := []models.Vessel{
+		{ID: 1, Name: "Wide", MMSI: "574123456", Size: "30m x 12m x 5m", Length: 20},
+		{ID: 2, Name: "Plain", MMSI: "574123457", Size: "12m x 8m x 4m", Weight: "900 tons", MaxSpeed: "18 knots"},
+	}
+	for _, v := range legacy {
+		if err := db.PutJSON(fmt.Sprintf("vessel:%d", v.ID), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	svc := NewVesselService(db)
+	for _, want := range legacy {
+		v, err := svc.GetByID(want.ID)
+		if err != nil {
+			t.Fatalf("GetByID(%d): %v", want.ID, err)
+		}
+		// An unrelated edit must not trip over the migrated dimensions.
+		v.Description = "edited"
+		if err := svc.Update(v); err != nil {
+			t.Errorf("%s: Update after migration: %v", want.Name, err)
+		}
+	}
+	if v, _ := svc.GetByID(2); v.Beam != 12 || v.Draught != 4 || v.MaxSpeedKnots != 18 || v.GrossTonnage != 0 {
+		t.Errorf("Plain migrated to beam %v, draught %v, speed %v, GT %v", v.Beam, v.Draught, v.MaxSpeedKnots, v.GrossTonnage)
+	}
+}
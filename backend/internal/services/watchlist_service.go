@@ -0,0 +1,310 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lehaisonagentai2/radar-hub-manager/backend/internal/models"
+)
+
+// alertCooldown is the minimum time between two automatic (AIS/radar) alerts
+// for the same watchlist entry, so a vessel reporting every few seconds does
+// not flood HQ. Manual sightings always raise an alert.
+const alertCooldown = 30 * 60
+
+// EventWatchlistAlert is the notifier event type for new watchlist alerts.
+const EventWatchlistAlert = "watchlist_alert"
+
+// Sighting is an observation of a vessel, identified by registry ID, MMSI or
+// both.
+type Sighting struct {
+	VesselID   uint
+	MMSI       string
+	Source     string // models.SightingAIS / SightingRadar / SightingManual
+	Latitude   float64
+	Longitude  float64
+	Note       string
+	ReportedBy int
+	Time       int64
+}
+
+// WatchlistService stores watchlists at "watchlist:{id}", their entries at
+// "watchlist_entry:{watchlistID}:{entryID}" and the alert history of each
+// entry at "watchlist_alert:{entryID}:{alertID}".
+type WatchlistService struct {
+	db        *DB
+	vesselSvc *VesselService
+	notifier  *Notifier
+}
+
+// NewWatchlistService creates the service and subscribes it to position
+// reports so AIS and radar sightings are checked automatically.
+func NewWatchlistService(db *DB, vesselSvc *VesselService, positionSvc *PositionService, notifier *Notifier) *WatchlistService {
+	sv := &WatchlistService{db: db, vesselSvc: vesselSvc, notifier: notifier}
+	positionSvc.OnReport(func(pos models.VesselPosition) {
+		source := strings.ToUpper(pos.Source)
+		if source == "" {
+			source = models.SightingAIS
+		}
+		_, err := sv.CheckSighting(Sighting{
+			VesselID:  pos.VesselID,
+			Source:    source,
+			Latitude:  pos.Latitude,
+			Longitude: pos.Longitude,
+			Time:      pos.Timestamp,
+		})
+		if err != nil {
+			log.Println("Watchlist check failed:", err)
+		}
+	})
+	return sv
+}
+
+// Create stores a new watchlist.
+func (s *WatchlistService) Create(w *models.Watchlist) error {
+	if strings.TrimSpace(w.Name) == "" {
+		return errors.New("watchlist name is required")
+	}
+	id, err := s.db.NextID("watchlist_counter")
+	if err != nil {
+		return fmt.Errorf("failed to generate ID: %w", err)
+	}
+	w.ID = id
+	w.CreatedAt = time.Now().Unix()
+	w.UpdatedAt = w.CreatedAt
+	return s.db.PutJSON(fmt.Sprintf("watchlist:%d", w.ID), w)
+}
+
+// GetByID retrieves a watchlist.
+func (s *WatchlistService) GetByID(id uint) (*models.Watchlist, error) {
+	var w models.Watchlist
+	if err := s.db.GetJSON(fmt.Sprintf("watchlist:%d", id), &w); err != nil {
+		return nil, errors.New("watchlist not found")
+	}
+	return &w, nil
+}
+
+// List returns all watchlists.
+func (s *WatchlistService) List() ([]models.Watchlist, error) {
+	var out []models.Watchlist
+	err := s.db.IteratePrefix("watchlist:", func(_ string, val []byte) error {
+		var w models.Watchlist
+		if err := json.Unmarshal(val, &w); err != nil {
+			return nil // Skip invalid records
+		}
+		out = append(out, w)
+		return nil
+	})
+	return out, err
+}
+
+// Update changes the name and/or description of a watchlist.
+func (s *WatchlistService) Update(id uint, name, description *string) (*models.Watchlist, error) {
+	w, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if name != nil && strings.TrimSpace(*name) != "" {
+		w.Name = *name
+	}
+	if description != nil {
+		w.Description = *description
+	}
+	w.UpdatedAt = time.Now().Unix()
+	return w, s.db.PutJSON(fmt.Sprintf("watchlist:%d", w.ID), w)
+}
+
+// Delete removes a watchlist together with its entries and their alerts.
+func (s *WatchlistService) Delete(id uint) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	entries, err := s.ListEntries(id)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := s.RemoveEntry(id, e.ID); err != nil {
+			return err
+		}
+	}
+	return s.db.Delete(fmt.Sprintf("watchlist:%d", id))
+}
+
+// AddEntry adds a vessel to a watchlist. Either MMSI or VesselID must be set;
+// the other is filled from the vessel registry when possible.
+func (s *WatchlistService) AddEntry(e *models.WatchlistEntry) error {
+	if _, err := s.GetByID(e.WatchlistID); err != nil {
+		return err
+	}
+	if strings.TrimSpace(e.Reason) == "" {
+		return errors.New("reason is required")
+	}
+	e.MMSI = strings.TrimSpace(e.MMSI)
+	switch {
+	case e.VesselID != 0:
+		v, err := s.vesselSvc.GetByID(e.VesselID)
+		if err != nil {
+			return err
+		}
+		if e.MMSI == "" {
+			e.MMSI = v.MMSI
+		}
+	case e.MMSI != "":
+		if _, err := ClassifyMMSI(e.MMSI); err != nil {
+			return err
+		}
+		if v, err := s.vesselSvc.GetByMMSI(e.MMSI); err == nil {
+			e.VesselID = v.ID
+		}
+	default:
+		return errors.New("mmsi or vessel_id is required")
+	}
+	if e.ExpiresAt != nil && *e.ExpiresAt <= time.Now().Unix() {
+		return errors.New("expires_at must be in the future")
+	}
+
+	id, err := s.db.NextID("watchlist_entry_counter")
+	if err != nil {
+		return fmt.Errorf("failed to generate ID: %w", err)
+	}
+	e.ID = id
+	e.CreatedAt = time.Now().Unix()
+	return s.putEntry(e)
+}
+
+func (s *WatchlistService) putEntry(e *models.WatchlistEntry) error {
+	return s.db.PutJSON(fmt.Sprintf("watchlist_entry:%d:%d", e.WatchlistID, e.ID), e)
+}
+
+// GetEntry retrieves an entry of a watchlist.
+func (s *WatchlistService) GetEntry(watchlistID, entryID uint) (*models.WatchlistEntry, error) {
+	var e models.WatchlistEntry
+	if err := s.db.GetJSON(fmt.Sprintf("watchlist_entry:%d:%d", watchlistID, entryID), &e); err != nil {
+		return nil, errors.New("entry not found")
+	}
+	return &e, nil
+}
+
+// ListEntries returns the entries of one watchlist, including expired ones.
+func (s *WatchlistService) ListEntries(watchlistID uint) ([]models.WatchlistEntry, error) {
+	return s.listEntries(fmt.Sprintf("watchlist_entry:%d:", watchlistID))
+}
+
+func (s *WatchlistService) listEntries(prefix string) ([]models.WatchlistEntry, error) {
+	var out []models.WatchlistEntry
+	err := s.db.IteratePrefix(prefix, func(_ string, val []byte) error {
+		var e models.WatchlistEntry
+		if err := json.Unmarshal(val, &e); err != nil {
+			return nil // Skip invalid records
+		}
+		out = append(out, e)
+		return nil
+	})
+	return out, err
+}
+
+// RemoveEntry deletes an entry and its alert history.
+func (s *WatchlistService) RemoveEntry(watchlistID, entryID uint) error {
+	if _, err := s.GetEntry(watchlistID, entryID); err != nil {
+		return err
+	}
+	var keys []string
+	if err := s.db.IteratePrefix(fmt.Sprintf("watchlist_alert:%d:", entryID), func(key string, _ []byte) error {
+		keys = append(keys, key)
+		return nil
+	}); err != nil {
+		return err
+	}
+	for _, k := range keys {
+		s.db.Delete(k) // Ignore error
+	}
+	return s.db.Delete(fmt.Sprintf("watchlist_entry:%d:%d", watchlistID, entryID))
+}
+
+// ListAlerts returns the alert history of an entry, oldest first.
+func (s *WatchlistService) ListAlerts(entryID uint) ([]models.WatchlistAlert, error) {
+	var out []models.WatchlistAlert
+	err := s.db.IteratePrefix(fmt.Sprintf("watchlist_alert:%d:", entryID), func(_ string, val []byte) error {
+		var a models.WatchlistAlert
+		if err := json.Unmarshal(val, &a); err != nil {
+			return nil // Skip invalid records
+		}
+		out = append(out, a)
+		return nil
+	})
+	return out, err
+}
+
+// CheckSighting matches a sighting against all active watchlist entries,
+// records an alert for each match and pushes it to HQ over the notifier.
+func (s *WatchlistService) CheckSighting(sg Sighting) ([]models.WatchlistAlert, error) {
+	sg.MMSI = strings.TrimSpace(sg.MMSI)
+	if sg.VesselID != 0 && sg.MMSI == "" {
+		if v, err := s.vesselSvc.GetByID(sg.VesselID); err == nil {
+			sg.MMSI = v.MMSI
+		}
+	}
+	if sg.VesselID == 0 && sg.MMSI != "" {
+		if v, err := s.vesselSvc.GetByMMSI(sg.MMSI); err == nil {
+			sg.VesselID = v.ID
+		}
+	}
+	if sg.VesselID == 0 && sg.MMSI == "" {
+		return nil, errors.New("mmsi or vessel_id is required")
+	}
+	now := time.Now().Unix()
+	if sg.Time == 0 {
+		sg.Time = now
+	}
+
+	entries, err := s.listEntries("watchlist_entry:")
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []models.WatchlistAlert
+	for _, e := range entries {
+		matches := (e.VesselID != 0 && e.VesselID == sg.VesselID) || (e.MMSI != "" && e.MMSI == sg.MMSI)
+		if !matches || (e.ExpiresAt != nil && *e.ExpiresAt <= now) {
+			continue
+		}
+		if sg.Source != models.SightingManual && e.LastAlertAt != nil && now-*e.LastAlertAt < alertCooldown {
+			continue
+		}
+
+		id, err := s.db.NextID("watchlist_alert_counter")
+		if err != nil {
+			return alerts, fmt.Errorf("failed to generate ID: %w", err)
+		}
+		alert := models.WatchlistAlert{
+			ID:          id,
+			WatchlistID: e.WatchlistID,
+			EntryID:     e.ID,
+			VesselID:    sg.VesselID,
+			MMSI:        sg.MMSI,
+			Source:      sg.Source,
+			Latitude:    sg.Latitude,
+			Longitude:   sg.Longitude,
+			Note:        sg.Note,
+			ReportedBy:  sg.ReportedBy,
+			SightedAt:   sg.Time,
+			CreatedAt:   now,
+		}
+		if err := s.db.PutJSON(fmt.Sprintf("watchlist_alert:%d:%010d", e.ID, alert.ID), &alert); err != nil {
+			return alerts, err
+		}
+		e.LastAlertAt = &now
+		if err := s.putEntry(&e); err != nil {
+			return alerts, err
+		}
+
+		s.notifier.Publish(EventWatchlistAlert, alert)
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
@@ -35,7 +35,7 @@ CREATE_VESSEL1_RESPONSE=$(curl -s -X POST "$BASE_URL/vessels" \
   -H "Content-Type: application/json" \
   -d '{
     "name": "USS Enterprise",
-    "mmsi": "123456789",
+    "mmsi": "338123456",
     "kind": "TC",
     "size": "342m x 78m x 76m",
     "weight": "100000 tons",
@@ -117,7 +117,7 @@ fi
 
 # Test 8: Get vessel by MMSI
 echo -e "\n8. Testing get vessel by MMSI..."
-GET_BY_MMSI_RESPONSE=$(curl -s -X GET "$BASE_URL/vessels/mmsi/123456789" \
+GET_BY_MMSI_RESPONSE=$(curl -s -X GET "$BASE_URL/vessels/mmsi/338123456" \
   -H "Authorization: Bearer $TOKEN")
 
 echo "Get vessel by MMSI response: $GET_BY_MMSI_RESPONSE"
@@ -144,7 +144,7 @@ DUPLICATE_MMSI_RESPONSE=$(curl -s -X POST "$BASE_URL/vessels" \
   -H "Content-Type: application/json" \
   -d '{
     "name": "Duplicate MMSI Test",
-    "mmsi": "123456789",
+    "mmsi": "338123456",
     "kind": "TC"
   }')
 